@@ -2,9 +2,13 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -12,11 +16,21 @@ import (
 	"github.com/Abraxas-365/ams/manifest"
 	"github.com/Abraxas-365/ams/orchestator"
 	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/cachex"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/limitx"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx"
 	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx/memoryinfra"
 	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx/memorysrv"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/routerx"
+	aianthropic "github.com/Abraxas-365/ams/pkg/ai/providers/anthropic"
+	aigemini "github.com/Abraxas-365/ams/pkg/ai/providers/gemini"
 	aiopenai "github.com/Abraxas-365/ams/pkg/ai/providers/openai"
 	"github.com/Abraxas-365/ams/pkg/config"
 	"github.com/Abraxas-365/ams/pkg/errx"
+	"github.com/Abraxas-365/ams/pkg/fsx"
+	"github.com/Abraxas-365/ams/pkg/fsx/fsxlocal"
+	"github.com/Abraxas-365/ams/pkg/iam/auth"
+	"github.com/Abraxas-365/ams/pkg/kernel"
 	"github.com/Abraxas-365/ams/pkg/logx"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -26,6 +40,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	"github.com/openai/openai-go/v3/option"
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
@@ -47,7 +63,7 @@ func main() {
 	db, err := initDatabase()
 	if err != nil {
 		logx.Warnf("⚠️ Database not available: %v", err)
-		logx.Info("ℹ️ Running without session persistence (buffer memory only)")
+		logx.Info("ℹ️ Running without Postgres session persistence")
 		db = nil
 	} else {
 		logx.Info("✅ Database connected successfully")
@@ -61,6 +77,15 @@ func main() {
 	openaiProvider := aiopenai.NewOpenAIProvider(apiKey)
 	llmClient := llm.NewClient(openaiProvider)
 
+	// Rate limits coordinate concurrent calls (shared across replicas with redis)
+	rateLimitStore := buildRateLimitStore(cfg.Redis)
+	if limits := os.Getenv("LLM_RATE_LIMITS"); limits != "" {
+		// Limited inside Adapt so requests without a model are limited as the default model
+		limited := newRateLimiter(rateLimitStore, "default", parseRateLimits(limits)).Wrap(openaiProvider)
+		llmClient = llm.NewClient(llm.Adapt(limited, llm.AllCapabilities(), llm.WithModel(aiopenai.DefaultModel)))
+		logx.Infof("✅ LLM rate limits enabled (%s)", limits)
+	}
+
 	// --- C. Manifest Registry ---
 	manifestReg := manifest.NewRegistry()
 	manifestPath := os.Getenv("MANIFEST_PATH")
@@ -73,26 +98,55 @@ func main() {
 	}
 	logx.Infof("✅ Manifest loaded from %s (Routes: %d)", manifestPath, len(manifestReg.ListRoutes()))
 
-	// --- D. Session Service (if DB available) ---
-	var sessionService *memorysrv.SessionService
+	// Named LLM providers referenced by routes (`llm.provider`)
+	llmProviders := buildLLMProviders(manifestReg.GetManifest().LLMProviders, rateLimitStore)
+	var llmRouter *routerx.Router
+	if chain := os.Getenv("LLM_FAILOVER"); chain != "" {
+		llmClient, llmRouter = buildFailoverClient(chain, *llmClient, llmProviders, manifestReg.GetManifest().LLMProviders)
+	}
+
+	// --- D. Session Service ---
+	sessionService := memorysrv.NewSessionService(buildSessionRepository(cfg.Redis, db))
+
+	var toolCallService *memorysrv.ToolCallService
 	if db != nil {
-		sessionRepo := memoryinfra.NewPostgresSessionRepository(db)
-		sessionService = memorysrv.NewSessionService(sessionRepo)
-		logx.Info("✅ Session service initialized (database-backed memory)")
-	} else {
-		logx.Info("ℹ️ Session service disabled (using buffer memory only)")
+		toolCallService = memorysrv.NewToolCallService(
+			memoryinfra.NewPostgresToolCallRepository(db),
+			memorysrv.WithRetention(loadToolCallRetention()),
+		)
+		go toolCallService.StartRetention(context.Background())
+		logx.Info("✅ Tool call audit trail enabled")
+	}
+
+	// --- E. File System (optional, for multipart uploads in tools/providers) ---
+	var fileSystem fsx.FileReader
+	if filesPath := os.Getenv("FILES_BASE_PATH"); filesPath != "" {
+		localFS, err := fsxlocal.NewLocalFileSystem(filesPath)
+		if err != nil {
+			logx.Fatalf("❌ Failed to initialize file system: %v", err)
+		}
+		fileSystem = localFS
+		logx.Infof("✅ File system initialized at %s", filesPath)
 	}
 
-	// --- E. Context & Orchestrator ---
-	providerLoader := appcontext.NewProviderLoader()
+	// --- F. Context & Orchestrator ---
+	providerLoaderOpts := make([]appcontext.ProviderLoaderOption, 0)
+	if fileSystem != nil {
+		providerLoaderOpts = append(providerLoaderOpts, appcontext.WithFileSystem(fileSystem))
+	}
+	providerLoader := appcontext.NewProviderLoader(providerLoaderOpts...)
 	contextBuilder := appcontext.NewBuilder(providerLoader)
 
 	orchConfig := orchestator.Config{
 		LLMClient:      *llmClient,
+		LLMProviders:   llmProviders,
+		ResponseCache:  buildResponseCache(cfg.Redis),
 		ContextBuilder: contextBuilder,
 		ManifestReg:    manifestReg,
-		MemoryFactory:  orchestator.NewBufferMemoryFactory(),
+		MemoryFactory:  buildMemoryFactory(llmClient),
 		SessionService: sessionService,
+		ToolCallSrv:    toolCallService,
+		FileSystem:     fileSystem,
 	}
 
 	orch := orchestator.NewOrchestrator(orchConfig)
@@ -110,7 +164,7 @@ func main() {
 	setupMiddleware(app, cfg)
 
 	// 6. Routes
-	registerRoutes(app, orch)
+	registerRoutes(app, orch, llmRouter)
 
 	// 7. Start Server
 	startServer(app, cfg)
@@ -190,17 +244,383 @@ func initDatabase() (*sqlx.DB, error) {
 	return db, nil
 }
 
+// buildSessionRepository selects where chat sessions are stored
+// SESSION_STORE is postgres (default with a database), redis, sqlite
+// (SQLITE_PATH, default ams.db) or memory (default without a database).
+// Redis sessions expire after SESSION_TTL (default 24h); with
+// SESSION_WRITE_BEHIND=true and a database they are flushed to Postgres when
+// deleted or idle for SESSION_IDLE_TIMEOUT (default 30m), and loaded back
+// from Postgres when the conversation continues
+func buildSessionRepository(redisCfg config.RedisConfig, db *sqlx.DB) memoryx.SessionRepository {
+	switch store := os.Getenv("SESSION_STORE"); store {
+	case "redis":
+		client := redis.NewClient(&redis.Options{
+			Addr:     redisCfg.Address(),
+			Password: redisCfg.Password,
+			DB:       redisCfg.DB,
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		if err := client.Ping(ctx).Err(); err != nil {
+			logx.Warnf("⚠️ Redis not available for sessions, falling back to postgres: %v", err)
+			break
+		}
+
+		opts := []memoryinfra.RedisSessionOption{memoryinfra.WithSessionTTL(envDuration("SESSION_TTL", 24*time.Hour))}
+		writeBehind := os.Getenv("SESSION_WRITE_BEHIND") == "true" && db != nil
+		if writeBehind {
+			opts = append(opts, memoryinfra.WithWriteBehind(memoryinfra.NewPostgresSessionRepository(db)))
+		}
+		repo := memoryinfra.NewRedisSessionRepository(client, opts...)
+
+		if writeBehind {
+			idle := envDuration("SESSION_IDLE_TIMEOUT", 30*time.Minute)
+			go repo.StartWriteBehind(context.Background(), time.Minute, idle)
+			logx.Infof("✅ Session service initialized (redis, write-behind to postgres after %s idle)", idle)
+		} else {
+			logx.Info("✅ Session service initialized (redis-backed memory)")
+		}
+		return repo
+	case "sqlite":
+		path := os.Getenv("SQLITE_PATH")
+		if path == "" {
+			path = "ams.db"
+		}
+		sqliteDB, err := memoryinfra.OpenSQLite(path)
+		if err != nil {
+			logx.Warnf("⚠️ SQLite not available for sessions: %v", err)
+			break
+		}
+		logx.Infof("✅ Session service initialized (sqlite at %s)", path)
+		return memoryinfra.NewSQLiteSessionRepository(sqliteDB)
+	case "memory":
+		logx.Info("✅ Session service initialized (in-memory, lost on restart)")
+		return memoryinfra.NewInMemorySessionRepository()
+	case "", "postgres":
+	default:
+		logx.Warnf("⚠️ Unknown SESSION_STORE %q, using postgres", store)
+	}
+
+	if db == nil {
+		logx.Warn("⚠️ No database for sessions, keeping them in memory (lost on restart); set SESSION_STORE=sqlite to persist locally")
+		return memoryinfra.NewInMemorySessionRepository()
+	}
+	logx.Info("✅ Session service initialized (database-backed memory)")
+	return memoryinfra.NewPostgresSessionRepository(db)
+}
+
+// envDuration reads a Go duration from the environment
+func envDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		logx.Warnf("⚠️ Invalid %s %q, using %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
+
+// loadToolCallRetention reads the tool call retention policy from the environment
+// TOOL_CALL_RETENTION accepts a Go duration (e.g. "720h"); empty keeps records forever
+func loadToolCallRetention() memorysrv.RetentionPolicy {
+	policy := memorysrv.RetentionPolicy{Interval: time.Hour}
+
+	if value := os.Getenv("TOOL_CALL_RETENTION"); value != "" {
+		maxAge, err := time.ParseDuration(value)
+		if err != nil {
+			logx.Warnf("⚠️ Invalid TOOL_CALL_RETENTION %q: %v", value, err)
+			return policy
+		}
+		policy.MaxAge = maxAge
+	}
+
+	return policy
+}
+
+// buildMemoryFactory selects the conversation memory strategy
+// MEMORY_STRATEGY is buffer (default), window or summary. Window memory keeps
+// the last MEMORY_WINDOW_TOKENS tokens (default 8000); summary memory is tuned
+// with MEMORY_SUMMARY_THRESHOLD and MEMORY_SUMMARY_RECENT_TOKENS (tokens) and
+// MEMORY_SUMMARY_MODEL (a cheaper model for the summaries)
+func buildMemoryFactory(client llm.LLM) orchestator.MemoryFactory {
+	switch strategy := os.Getenv("MEMORY_STRATEGY"); strategy {
+	case "window":
+		maxTokens := 8000
+		if value := os.Getenv("MEMORY_WINDOW_TOKENS"); value != "" {
+			if tokens, err := strconv.Atoi(value); err == nil && tokens > 0 {
+				maxTokens = tokens
+			} else {
+				logx.Warnf("⚠️ Invalid MEMORY_WINDOW_TOKENS %q, using default", value)
+			}
+		}
+		logx.Infof("✅ Token window memory enabled (%d tokens)", maxTokens)
+		return orchestator.NewTokenWindowMemoryFactory(maxTokens)
+	case "summary":
+		var opts []memoryx.SummaryMemoryOption
+		if value := os.Getenv("MEMORY_SUMMARY_THRESHOLD"); value != "" {
+			if tokens, err := strconv.Atoi(value); err == nil {
+				opts = append(opts, memoryx.WithSummaryThreshold(tokens))
+			} else {
+				logx.Warnf("⚠️ Invalid MEMORY_SUMMARY_THRESHOLD %q, using default", value)
+			}
+		}
+		if value := os.Getenv("MEMORY_SUMMARY_RECENT_TOKENS"); value != "" {
+			if tokens, err := strconv.Atoi(value); err == nil {
+				opts = append(opts, memoryx.WithRecentTokens(tokens))
+			} else {
+				logx.Warnf("⚠️ Invalid MEMORY_SUMMARY_RECENT_TOKENS %q, using default", value)
+			}
+		}
+		if model := os.Getenv("MEMORY_SUMMARY_MODEL"); model != "" {
+			opts = append(opts, memoryx.WithSummaryOptions(llm.WithModel(model)))
+		}
+		logx.Info("✅ Summary memory enabled")
+		return orchestator.NewSummaryMemoryFactory(client, opts...)
+	case "", "buffer":
+	default:
+		logx.Warnf("⚠️ Unknown MEMORY_STRATEGY %q, using buffer", strategy)
+	}
+	return orchestator.NewBufferMemoryFactory()
+}
+
+// buildLLMProviders creates a client for each named provider in the manifest
+// Keys are read from each provider's api_key_env; clients are adapted to the
+// declared capabilities so limited backends degrade instead of failing, and
+// rate limited when the provider declares rate_limits
+func buildLLMProviders(providers []manifest.LLMProvider, rateLimitStore limitx.Store) map[string]llm.Client {
+	clients := make(map[string]llm.Client, len(providers))
+
+	for _, provider := range providers {
+		apiKey := ""
+		if provider.APIKeyEnv != "" {
+			apiKey = os.Getenv(provider.APIKeyEnv)
+			if apiKey == "" {
+				logx.Warnf("⚠️ %s not set for LLM provider %s", provider.APIKeyEnv, provider.Name)
+			}
+		}
+
+		var backend llm.LLM
+		switch provider.Type {
+		case manifest.LLMProviderOpenAI, manifest.LLMProviderOpenAICompatible:
+			opts := make([]option.RequestOption, 0, 1)
+			if provider.BaseURL != "" {
+				opts = append(opts, option.WithBaseURL(provider.BaseURL))
+			}
+			// Self-hosted servers often need no key; never fall back to OPENAI_API_KEY for them
+			if apiKey == "" && provider.Type == manifest.LLMProviderOpenAICompatible {
+				apiKey = "unused"
+			}
+			backend = aiopenai.NewOpenAIProvider(apiKey, opts...)
+		case manifest.LLMProviderAnthropic:
+			opts := make([]aianthropic.Option, 0, 1)
+			if provider.BaseURL != "" {
+				opts = append(opts, aianthropic.WithBaseURL(provider.BaseURL))
+			}
+			backend = aianthropic.NewAnthropicProvider(apiKey, opts...)
+		case manifest.LLMProviderGemini:
+			opts := make([]aigemini.Option, 0, 1)
+			if provider.BaseURL != "" {
+				opts = append(opts, aigemini.WithBaseURL(provider.BaseURL))
+			}
+			backend = aigemini.NewGeminiProvider(apiKey, opts...)
+		default:
+			logx.Warnf("⚠️ Skipping LLM provider %s with unsupported type %q", provider.Name, provider.Type)
+			continue
+		}
+
+		// Limited inside Adapt so the provider's default model is resolved
+		if len(provider.RateLimits) > 0 {
+			backend = newRateLimiter(rateLimitStore, provider.Name, provider.RateLimits).Wrap(backend)
+		}
+
+		defaults := make([]llm.Option, 0, 1)
+		if provider.DefaultModel != "" {
+			defaults = append(defaults, llm.WithModel(provider.DefaultModel))
+		}
+		clients[provider.Name] = *llm.NewClient(llm.Adapt(backend, provider.Capabilities.ToLLM(), defaults...))
+
+		logx.WithFields(logx.Fields{
+			"provider": provider.Name,
+			"type":     provider.Type,
+			"base_url": provider.BaseURL,
+		}).Info("✅ LLM provider configured")
+	}
+
+	return clients
+}
+
+// buildFailoverClient wraps the default client in a failover router
+// LLM_FAILOVER lists backends in order, e.g. "default,claude:claude-sonnet-4,local",
+// where "default" is the OpenAI client and other names come from llm_providers.
+// Model names are provider specific, so a backend uses the model after the colon,
+// or its provider's default_model; only "default" receives the route's model
+func buildFailoverClient(chain string, defaultClient llm.Client, providers map[string]llm.Client, definitions []manifest.LLMProvider) (*llm.Client, *routerx.Router) {
+	defaultModels := make(map[string]string, len(definitions))
+	for _, definition := range definitions {
+		defaultModels[definition.Name] = definition.DefaultModel
+	}
+
+	backends := make([]routerx.Backend, 0)
+	for _, entry := range strings.Split(chain, ",") {
+		name, model, _ := strings.Cut(strings.TrimSpace(entry), ":")
+		client, ok := providers[name]
+		if name == "default" {
+			client, ok = defaultClient, true
+		}
+		if !ok {
+			logx.Warnf("⚠️ Unknown LLM_FAILOVER backend %q, skipping", name)
+			continue
+		}
+
+		if model == "" && name != "default" {
+			model = defaultModels[name]
+			if model == "" {
+				logx.Warnf("⚠️ LLM_FAILOVER backend %q has no model; routes' models will be sent to it", name)
+			}
+		}
+		backends = append(backends, routerx.Backend{Name: name, LLM: &client, Model: model})
+	}
+
+	if len(backends) == 0 {
+		return &defaultClient, nil
+	}
+
+	logx.Infof("✅ LLM failover enabled (%s)", chain)
+	router := routerx.New(backends)
+	return llm.NewClient(router), router
+}
+
+// buildResponseCache creates the LLM response cache used by routes with `cache.enabled`
+// LLM_CACHE selects the store: memory (default), redis, or off
+func buildResponseCache(redisCfg config.RedisConfig) *cachex.Cache {
+	switch store := os.Getenv("LLM_CACHE"); store {
+	case "off":
+		logx.Info("ℹ️ LLM response cache disabled")
+		return nil
+	case "redis":
+		client := redis.NewClient(&redis.Options{
+			Addr:     redisCfg.Address(),
+			Password: redisCfg.Password,
+			DB:       redisCfg.DB,
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		if err := client.Ping(ctx).Err(); err != nil {
+			logx.Warnf("⚠️ Redis not available for LLM cache, using memory: %v", err)
+			break
+		}
+		logx.Infof("✅ LLM response cache enabled (redis at %s)", redisCfg.Address())
+		return cachex.New(cachex.NewRedisStore(client))
+	case "", "memory":
+	default:
+		logx.Warnf("⚠️ Unknown LLM_CACHE %q, using memory", store)
+	}
+
+	maxEntries := 10000
+	if value := os.Getenv("LLM_CACHE_MAX_ENTRIES"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			maxEntries = parsed
+		}
+	}
+	logx.Info("✅ LLM response cache enabled (memory)")
+	return cachex.New(cachex.NewMemoryStore(maxEntries))
+}
+
+// buildRateLimitStore creates the store for LLM rate limit buckets
+// LLM_RATE_LIMIT_STORE selects memory (default, per replica) or redis (shared)
+func buildRateLimitStore(redisCfg config.RedisConfig) limitx.Store {
+	switch store := os.Getenv("LLM_RATE_LIMIT_STORE"); store {
+	case "redis":
+		client := redis.NewClient(&redis.Options{
+			Addr:     redisCfg.Address(),
+			Password: redisCfg.Password,
+			DB:       redisCfg.DB,
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		if err := client.Ping(ctx).Err(); err != nil {
+			logx.Warnf("⚠️ Redis not available for LLM rate limits, using memory: %v", err)
+			break
+		}
+		return limitx.NewRedisStore(client)
+	case "", "memory":
+	default:
+		logx.Warnf("⚠️ Unknown LLM_RATE_LIMIT_STORE %q, using memory", store)
+	}
+	return limitx.NewMemoryStore()
+}
+
+// newRateLimiter creates a limiter for one provider; the "*" entry applies to unlisted models
+// LLM_RATE_LIMIT_MAX_WAIT bounds how long calls queue (default 30s)
+func newRateLimiter(store limitx.Store, namespace string, limits map[string]manifest.RateLimit) *limitx.Limiter {
+	opts := []limitx.Option{limitx.WithNamespace(namespace)}
+	for model, limit := range limits {
+		converted := limitx.Limit{RequestsPerMinute: limit.RPM, TokensPerMinute: limit.TPM}
+		if model == "*" {
+			opts = append(opts, limitx.WithDefaultLimit(converted))
+		} else {
+			opts = append(opts, limitx.WithLimit(model, converted))
+		}
+	}
+
+	if value := os.Getenv("LLM_RATE_LIMIT_MAX_WAIT"); value != "" {
+		if maxWait, err := time.ParseDuration(value); err == nil {
+			opts = append(opts, limitx.WithMaxWait(maxWait))
+		} else {
+			logx.Warnf("⚠️ Invalid LLM_RATE_LIMIT_MAX_WAIT %q, using default", value)
+		}
+	}
+	return limitx.New(store, opts...)
+}
+
+// parseRateLimits reads LLM_RATE_LIMITS, e.g. "gpt-4o=500:30000,*=100:10000" (model=rpm:tpm)
+func parseRateLimits(value string) map[string]manifest.RateLimit {
+	limits := make(map[string]manifest.RateLimit)
+	for _, entry := range strings.Split(value, ",") {
+		model, budget, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		rpm, tpm, _ := strings.Cut(budget, ":")
+		requests, rpmErr := strconv.Atoi(strings.TrimSpace(rpm))
+		tokens, tpmErr := strconv.Atoi(strings.TrimSpace(tpm))
+		if !ok || model == "" || rpmErr != nil || tpmErr != nil {
+			logx.Warnf("⚠️ Invalid LLM_RATE_LIMITS entry %q, expected model=rpm:tpm", entry)
+			continue
+		}
+		limits[model] = manifest.RateLimit{RPM: requests, TPM: tokens}
+	}
+	return limits
+}
+
 // ============================================================================
 // Helper Functions
 // ============================================================================
 
+// writeSSE writes a single server-sent event with a JSON-encoded payload
+func writeSSE(w *bufio.Writer, event string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// auditReadScope lets non-admin callers query every user's tool calls
+const auditReadScope = "audit:read"
+
 // generateAnonymousID creates a unique identifier for anonymous users
 func generateAnonymousID() string {
 	return fmt.Sprintf("anon_%s", uuid.New().String())
 }
 
-// setupAnonymousUser sets up user context for anonymous/unauthenticated requests
-func setupAnonymousUser(req *orchestator.ChatRequest) {
+// setupAnonymousUser sets up user context for the request
+// Authenticated callers get the identity and scopes from their verified token;
+// anonymous callers can't claim a tenant or scopes
+func setupAnonymousUser(c *fiber.Ctx, req *orchestator.ChatRequest) {
 	req.BearerToken = ""
 
 	// Initialize Frontend context if nil
@@ -208,6 +628,19 @@ func setupAnonymousUser(req *orchestator.ChatRequest) {
 		req.Frontend = &appcontext.FrontendContext{}
 	}
 
+	if authContext, ok := auth.GetAuthContext(c); ok {
+		req.User = verifiedUser(authContext, req.User)
+		if req.Frontend.AnonymousID == "" {
+			req.Frontend.AnonymousID = req.User.ID
+		}
+		return
+	}
+
+	if req.User != nil {
+		req.User.TenantID = ""
+		req.User.Permissions = nil
+	}
+
 	// Get or generate anonymous ID from frontend context
 	anonymousID := req.Frontend.AnonymousID
 	if anonymousID == "" {
@@ -226,11 +659,76 @@ func setupAnonymousUser(req *orchestator.ChatRequest) {
 	}
 }
 
+// verifiedUser builds the request user from the authentication context
+// Only metadata is kept from the client-supplied user
+func verifiedUser(authContext *kernel.AuthContext, clientUser *appcontext.User) *appcontext.User {
+	user := &appcontext.User{
+		TenantID:    authContext.TenantID.String(),
+		Email:       authContext.Email,
+		Name:        authContext.Name,
+		Permissions: authContext.Scopes,
+	}
+	if authContext.UserID != nil {
+		user.ID = authContext.UserID.String()
+	}
+	if clientUser != nil {
+		user.Metadata = clientUser.Metadata
+	}
+	return user
+}
+
+// verifiedScopes returns the scopes of the verified token, nil for anonymous callers
+func verifiedScopes(c *fiber.Ctx) []string {
+	if authContext, ok := auth.GetAuthContext(c); ok {
+		return authContext.Scopes
+	}
+	return nil
+}
+
+// callerID identifies the caller: the verified user, or the anonymous ID sent
+// as a query param or X-Anonymous-ID header
+func callerID(c *fiber.Ctx) string {
+	if authContext, ok := auth.GetAuthContext(c); ok && authContext.UserID != nil {
+		return authContext.UserID.String()
+	}
+	if anonymousID := c.Query("anonymous_id"); anonymousID != "" {
+		return anonymousID
+	}
+	return c.Get("X-Anonymous-ID")
+}
+
+// optionalAuth verifies the bearer token when one is sent and stores the
+// authentication context; requests without a token continue as anonymous
+func optionalAuth(tokens auth.TokenService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token, found := strings.CutPrefix(c.Get("Authorization"), "Bearer ")
+		if !found || token == "" {
+			return c.Next()
+		}
+
+		claims, err := tokens.ValidateAccessToken(token)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		c.Locals("auth", &kernel.AuthContext{
+			UserID:   &claims.UserID,
+			TenantID: claims.TenantID,
+			Email:    claims.Email,
+			Name:     claims.Name,
+			Scopes:   claims.Scopes,
+		})
+		return c.Next()
+	}
+}
+
 // ============================================================================
 // Routes
 // ============================================================================
 
-func registerRoutes(app *fiber.App, orch *orchestator.Orchestrator) {
+func registerRoutes(app *fiber.App, orch *orchestator.Orchestrator, llmRouter *routerx.Router) {
 	// Health check
 	app.Get("/health", func(c *fiber.Ctx) error {
 		health := fiber.Map{
@@ -246,6 +744,15 @@ func registerRoutes(app *fiber.App, orch *orchestator.Orchestrator) {
 		return c.JSON(health)
 	})
 
+	// Metrics (run counts, response cache hit/miss counters, failover backend health)
+	app.Get("/metrics", func(c *fiber.Ctx) error {
+		stats := orch.Stats()
+		if llmRouter != nil {
+			stats["llm_backends"] = llmRouter.Health()
+		}
+		return c.JSON(stats)
+	})
+
 	// List available routes
 	app.Get("/routes", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
@@ -268,9 +775,9 @@ func registerRoutes(app *fiber.App, orch *orchestator.Orchestrator) {
 		}
 
 		// Setup anonymous user with unique ID
-		setupAnonymousUser(&req)
+		setupAnonymousUser(c, &req)
 
-		response, err := orch.HandleChat(c.Context(), req)
+		response, err := orch.HandleChat(c.Context(), req, verifiedScopes(c))
 		if err != nil {
 			return err
 		}
@@ -293,8 +800,9 @@ func registerRoutes(app *fiber.App, orch *orchestator.Orchestrator) {
 		}
 
 		// Setup anonymous user with unique ID
-		setupAnonymousUser(&req)
+		setupAnonymousUser(c, &req)
 		anonymousID := req.Frontend.AnonymousID
+		scopes := verifiedScopes(c)
 
 		c.Set("Content-Type", "text/event-stream")
 		c.Set("Cache-Control", "no-cache")
@@ -303,28 +811,80 @@ func registerRoutes(app *fiber.App, orch *orchestator.Orchestrator) {
 
 		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
 			// Send anonymous_id as first event
-			fmt.Fprintf(w, "event: init\ndata: {\"anonymous_id\":\"%s\"}\n\n", anonymousID)
-			_ = w.Flush()
-
-			_ = orch.HandleChatStream(c.Context(), req, func(chunk orchestator.StreamChunk) {
-				if chunk.Error != "" {
-					fmt.Fprintf(w, "event: error\ndata: {\"error\":\"%s\"}\n\n", chunk.Error)
-				} else if chunk.Done {
-					if chunk.SessionID != "" {
-						fmt.Fprintf(w, "event: done\ndata: {\"session_id\":\"%s\",\"anonymous_id\":\"%s\"}\n\n",
-							chunk.SessionID, anonymousID)
-					} else {
-						fmt.Fprintf(w, "event: done\ndata: {\"anonymous_id\":\"%s\"}\n\n", anonymousID)
+			_ = writeSSE(w, "init", fiber.Map{"anonymous_id": anonymousID})
+
+			// The request context is released once the handler returns, so the run
+			// gets its own context and is cancelled when the client disconnects
+			var runID string
+			disconnected := false
+
+			_ = orch.HandleChatStream(context.Background(), req, scopes, func(chunk orchestator.StreamChunk) {
+				if disconnected {
+					return
+				}
+
+				var payload any
+				switch chunk.Type {
+				case orchestator.StreamEventRunStarted:
+					runID = chunk.RunID
+					payload = fiber.Map{
+						"run_id":     chunk.RunID,
+						"session_id": chunk.SessionID,
+					}
+				case orchestator.StreamEventError:
+					payload = fiber.Map{"error": chunk.Error}
+				case orchestator.StreamEventDone:
+					payload = fiber.Map{
+						"session_id":   chunk.SessionID,
+						"run_id":       chunk.RunID,
+						"anonymous_id": anonymousID,
+						"metadata":     chunk.Metadata,
+						"data":         chunk.Data,
+					}
+				case orchestator.StreamEventUsage:
+					payload = chunk.Usage
+				case orchestator.StreamEventTrace:
+					payload = chunk.Trace
+				case orchestator.StreamEventToolCallStarted, orchestator.StreamEventToolCallFinished:
+					payload = chunk.ToolCall
+				default:
+					payload = fiber.Map{"content": chunk.Content}
+				}
+				if err := writeSSE(w, string(chunk.Type), payload); err != nil {
+					disconnected = true
+					logx.WithFields(logx.Fields{
+						"run_id": runID,
+					}).WithError(err).Info("Client disconnected, cancelling run")
+					if runID != "" {
+						_ = orch.CancelRun(runID, req.User.ID)
 					}
-				} else {
-					fmt.Fprintf(w, "event: message\ndata: %s\n\n", chunk.Content)
 				}
-				_ = w.Flush()
 			})
 		})
 		return nil
 	})
 
+	// 3. Cancel an in-flight chat run
+	// Only the caller that started the run can cancel it
+	app.Post("/api/v1/runs/:id/cancel", func(c *fiber.Ctx) error {
+		userID := callerID(c)
+		if userID == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "anonymous_id is required (query param or X-Anonymous-ID header)",
+			})
+		}
+
+		runID := c.Params("id")
+		if err := orch.CancelRun(runID, userID); err != nil {
+			return err
+		}
+
+		return c.JSON(fiber.Map{
+			"run_id":    runID,
+			"cancelled": true,
+		})
+	})
+
 	// ========================================================================
 	// Session Management Endpoints
 	// ========================================================================
@@ -438,7 +998,7 @@ func registerRoutes(app *fiber.App, orch *orchestator.Orchestrator) {
 	sessionAPI.Get("/:session_id/messages", func(c *fiber.Ctx) error {
 		sessionID := c.Params("session_id")
 
-		sessionWithMessages, err := orch.GetSessionWithMessages(c.Context(), sessionID)
+		sessionWithMessages, err := orch.GetSessionWithMessages(c.Context(), sessionID, verifiedScopes(c))
 		if err != nil {
 			logx.WithError(err).Error("Failed to get session with messages")
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -449,6 +1009,23 @@ func registerRoutes(app *fiber.App, orch *orchestator.Orchestrator) {
 		return c.JSON(sessionWithMessages)
 	})
 
+	// Get the execution trace recorded for a message (debug requests only)
+	sessionAPI.Get("/:session_id/messages/:message_id/trace", func(c *fiber.Ctx) error {
+		messageID, err := strconv.ParseInt(c.Params("message_id"), 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "message_id must be an integer",
+			})
+		}
+
+		trace, err := orch.GetMessageTrace(c.Context(), c.Params("session_id"), messageID, verifiedScopes(c))
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(trace)
+	})
+
 	// Delete session
 	sessionAPI.Delete("/:session_id", func(c *fiber.Ctx) error {
 		sessionID := c.Params("session_id")
@@ -463,6 +1040,69 @@ func registerRoutes(app *fiber.App, orch *orchestator.Orchestrator) {
 		return c.SendStatus(fiber.StatusNoContent)
 	})
 
+	// ========================================================================
+	// Tool Call Audit Endpoints
+	// ========================================================================
+
+	// Query tool call audit trail
+	// Admins and auditors can query any user; everyone else only sees their own calls
+	app.Get("/api/v1/tool-calls", func(c *fiber.Ctx) error {
+		authContext, ok := auth.GetAuthContext(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Authentication required",
+			})
+		}
+
+		filter := memoryx.ToolCallFilter{
+			SessionID: memoryx.SessionID(c.Query("session_id")),
+			UserID:    c.Query("user_id"),
+			ToolName:  c.Query("tool"),
+			Limit:     c.QueryInt("limit", 50),
+			Offset:    c.QueryInt("offset", 0),
+		}
+		if !authContext.IsAdmin() && !authContext.HasScope(auditReadScope) {
+			filter.UserID = ""
+			if authContext.UserID != nil {
+				filter.UserID = authContext.UserID.String()
+			}
+			if filter.UserID == "" {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"error": "Insufficient permissions",
+				})
+			}
+		}
+
+		for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+			value := c.Query(param)
+			if value == "" {
+				continue
+			}
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": fmt.Sprintf("%s must be an RFC3339 timestamp", param),
+				})
+			}
+			*target = &parsed
+		}
+
+		records, err := orch.ListToolCalls(c.Context(), filter)
+		if err != nil {
+			logx.WithError(err).Error("Failed to list tool calls")
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to list tool calls",
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"tool_calls": records,
+			"count":      len(records),
+			"limit":      filter.Limit,
+			"offset":     filter.Offset,
+		})
+	})
+
 	// Utility: Generate new anonymous ID
 	app.Get("/api/v1/anonymous-id", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
@@ -516,6 +1156,14 @@ func setupMiddleware(app *fiber.App, cfg *config.Config) {
 		TimeFormat: "15:04:05",
 		TimeZone:   "Local",
 	}))
+
+	// Bearer tokens are verified server-side so user identity and scopes
+	// can't be claimed in the request body
+	if cfg.Auth.JWT.SecretKey != "" {
+		app.Use(optionalAuth(auth.NewJWTServiceFromConfig(&cfg.Auth.JWT)))
+	} else {
+		logx.Warn("⚠️ JWT_SECRET_KEY not set. All chat requests are anonymous.")
+	}
 }
 
 func globalErrorHandler(cfg *config.Config) fiber.ErrorHandler {
@@ -0,0 +1,622 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	appcontext "github.com/Abraxas-365/ams/context"
+	"github.com/Abraxas-365/ams/manifest"
+	"github.com/Abraxas-365/ams/orchestator"
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/cachex"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/llmtest"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx/memoryinfra"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx/memorysrv"
+	"github.com/Abraxas-365/ams/pkg/config"
+	"github.com/Abraxas-365/ams/pkg/fsx/fsxlocal"
+	"github.com/Abraxas-365/ams/pkg/iam/auth"
+	"github.com/Abraxas-365/ams/pkg/kernel"
+	"github.com/gofiber/fiber/v2"
+)
+
+const testManifest = `
+version: "1"
+routes:
+  - name: home
+    pattern: /
+`
+
+// testServer is the API wired to a fake LLM, in-memory sessions and a JWT secret
+type testServer struct {
+	app    *fiber.App
+	fake   *llmtest.Fake
+	tokens *auth.JWTService
+}
+
+func newTestServer(t *testing.T, manifestYAML string, configure ...func(*orchestator.Config)) *testServer {
+	t.Helper()
+
+	manifestReg := manifest.NewRegistry()
+	if err := manifestReg.LoadFromYAML([]byte(manifestYAML)); err != nil {
+		t.Fatalf("LoadFromYAML: %v", err)
+	}
+
+	fake := llmtest.New()
+	orchConfig := orchestator.Config{
+		LLMClient:      fake.Client(),
+		ContextBuilder: appcontext.NewBuilder(appcontext.NewProviderLoader()),
+		ManifestReg:    manifestReg,
+		MemoryFactory:  orchestator.NewBufferMemoryFactory(),
+		SessionService: memorysrv.NewSessionService(memoryinfra.NewInMemorySessionRepository()),
+	}
+	for _, fn := range configure {
+		fn(&orchConfig)
+	}
+	orch := orchestator.NewOrchestrator(orchConfig)
+
+	tokens := auth.NewJWTServiceFromConfig(&config.JWTConfig{
+		SecretKey:      "test-secret",
+		AccessTokenTTL: time.Hour,
+	})
+	app := fiber.New(fiber.Config{ErrorHandler: globalErrorHandler(nil)})
+	app.Use(optionalAuth(tokens))
+	registerRoutes(app, orch, nil)
+
+	return &testServer{app: app, fake: fake, tokens: tokens}
+}
+
+// token signs an access token for user-1 with the given scopes
+func (s *testServer) token(t *testing.T, scopes ...string) string {
+	t.Helper()
+
+	token, err := s.tokens.GenerateAccessToken(kernel.NewUserID("user-1"), kernel.NewTenantID("tenant-1"),
+		map[string]any{"scopes": scopes})
+	if err != nil {
+		t.Fatalf("GenerateAccessToken: %v", err)
+	}
+	return token
+}
+
+// do sends the request with an optional bearer token and decodes the JSON body
+func (s *testServer) do(t *testing.T, req *http.Request, token string) (int, map[string]any) {
+	t.Helper()
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := s.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	var decoded map[string]any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("decode %s: %v", body, err)
+	}
+	return resp.StatusCode, decoded
+}
+
+func jsonRequest(method, target, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestChatDebugRequiresVerifiedScope(t *testing.T) {
+	tests := []struct {
+		name       string
+		request    func() *http.Request
+		scopes     []string
+		anonymous  bool
+		wantStatus int
+	}{
+		{
+			name: "anonymous json body",
+			request: func() *http.Request {
+				return jsonRequest(http.MethodPost, "/api/v1/chat",
+					`{"message":"hi","route":{"path":"/"},"debug":true,"scopes":["*"],"user":{"permissions":["*"]}}`)
+			},
+			anonymous:  true,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "anonymous form body",
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/api/v1/chat",
+					strings.NewReader("message=hi&route.path=/&debug=true&scopes=*&Scopes=*&user.permissions=*"))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				return req
+			},
+			anonymous:  true,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "authenticated without the debug scope",
+			request: func() *http.Request {
+				return jsonRequest(http.MethodPost, "/api/v1/chat", `{"message":"hi","route":{"path":"/"},"debug":true}`)
+			},
+			scopes:     []string{"orders:read"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "authenticated with the debug scope",
+			request: func() *http.Request {
+				return jsonRequest(http.MethodPost, "/api/v1/chat", `{"message":"hi","route":{"path":"/"},"debug":true}`)
+			},
+			scopes:     []string{orchestator.DefaultDebugScope},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "wildcard scope",
+			request: func() *http.Request {
+				return jsonRequest(http.MethodPost, "/api/v1/chat", `{"message":"hi","route":{"path":"/"},"debug":true}`)
+			},
+			scopes:     []string{"*"},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, testManifest)
+			server.fake.On().Reply("hello")
+
+			var token string
+			if !tt.anonymous {
+				token = server.token(t, tt.scopes...)
+			}
+			status, body := server.do(t, tt.request(), token)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+			}
+			if status != http.StatusOK {
+				if body["code"] != "ORCHESTRATOR_DEBUG_NOT_ALLOWED" {
+					t.Errorf("error = %v, want ORCHESTRATOR_DEBUG_NOT_ALLOWED", body)
+				}
+				if server.fake.CallCount() != 0 {
+					t.Errorf("model called %d times for a rejected debug request", server.fake.CallCount())
+				}
+				return
+			}
+			response, _ := body["response"].(map[string]any)
+			if response["trace"] == nil {
+				t.Errorf("response = %v, want a trace", response)
+			}
+		})
+	}
+}
+
+// toolManifest routes "/" to a login tool posting to url
+func toolManifest(url string) string {
+	return `
+version: "1"
+routes:
+  - name: home
+    pattern: /
+    tools:
+      - name: login
+        description: Sign in
+        type: http
+        config:
+          method: POST
+          url: ` + url + `
+        parameters:
+          - name: username
+            type: string
+            required: true
+            source: agent
+          - name: password
+            type: string
+            required: true
+            source: agent
+`
+}
+
+func TestSessionMessagesHideTracesWithoutDebugScope(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, toolManifest(backend.URL))
+	server.fake.On(llmtest.AfterToolResult("login")).Reply("signed in")
+	server.fake.On().ReplyToolCall("login", map[string]any{"username": "bob", "password": "hunter2"})
+
+	debugToken := server.token(t, orchestator.DefaultDebugScope)
+	status, body := server.do(t, jsonRequest(http.MethodPost, "/api/v1/chat",
+		`{"message":"sign me in","route":{"path":"/"},"debug":true}`), debugToken)
+	if status != http.StatusOK {
+		t.Fatalf("chat status = %d (%v)", status, body)
+	}
+	sessionID, _ := body["session_id"].(string)
+
+	tests := []struct {
+		name      string
+		token     string
+		wantTrace bool
+	}{
+		{"anonymous", "", false},
+		{"authenticated without the debug scope", server.token(t, "orders:read"), false},
+		{"debug scope", debugToken, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/sessions/"+sessionID+"/messages", nil)
+			status, body := server.do(t, req, tt.token)
+			if status != http.StatusOK {
+				t.Fatalf("status = %d (%v)", status, body)
+			}
+
+			var traces []string
+			for _, message := range body["messages"].([]any) {
+				metadata, _ := message.(map[string]any)["metadata"].(string)
+				if strings.Contains(metadata, `"trace"`) {
+					traces = append(traces, metadata)
+				}
+			}
+			if hasTrace := len(traces) > 0; hasTrace != tt.wantTrace {
+				t.Fatalf("trace in messages = %v, want %v", hasTrace, tt.wantTrace)
+			}
+			for _, trace := range traces {
+				if strings.Contains(trace, "hunter2") || !strings.Contains(trace, "[REDACTED]") {
+					t.Errorf("trace arguments not redacted: %s", trace)
+				}
+			}
+		})
+	}
+}
+
+func TestChatPathAttachmentsStayInCallersFolder(t *testing.T) {
+	root := t.TempDir()
+	for _, user := range []string{"user-1", "user-2"} {
+		dir := filepath.Join(root, "uploads", user)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes of "+user), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fileSystem, err := fsxlocal.NewLocalFileSystem(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		attachment string
+		scopes     []string
+		anonymous  bool
+		wantStatus int
+		wantCode   string
+		wantData   string // File content sent to the model
+	}{
+		{"anonymous inline data", `{"type":"file","data":"aGk=","filename":"hi.txt"}`, nil, true, http.StatusOK, "", "hi"},
+		{"anonymous path", `{"type":"file","path":"uploads/user-1/notes.txt"}`, nil, true, http.StatusForbidden, "ORCHESTRATOR_ATTACHMENT_NOT_ALLOWED", ""},
+		{"authenticated without the files scope", `{"type":"file","path":"uploads/user-1/notes.txt"}`, []string{"orders:read"}, false, http.StatusForbidden, "ORCHESTRATOR_ATTACHMENT_NOT_ALLOWED", ""},
+		{"own folder", `{"type":"file","path":"uploads/user-1/notes.txt"}`, []string{orchestator.DefaultAttachmentScope}, false, http.StatusOK, "", "notes of user-1"},
+		{"other user's folder", `{"type":"file","path":"uploads/user-2/notes.txt"}`, []string{orchestator.DefaultAttachmentScope}, false, http.StatusBadRequest, "ORCHESTRATOR_INVALID_ATTACHMENT", ""},
+		{"escape with dot dot", `{"type":"file","path":"uploads/user-1/../user-2/notes.txt"}`, []string{"*"}, false, http.StatusBadRequest, "ORCHESTRATOR_INVALID_ATTACHMENT", ""},
+		{"outside the uploads folder", `{"type":"file","path":"user-1/notes.txt"}`, []string{"*"}, false, http.StatusBadRequest, "ORCHESTRATOR_INVALID_ATTACHMENT", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, testManifest, func(c *orchestator.Config) {
+				c.FileSystem = fileSystem
+			})
+			server.fake.On().Reply("read it")
+
+			var token string
+			if !tt.anonymous {
+				token = server.token(t, tt.scopes...)
+			}
+			status, body := server.do(t, jsonRequest(http.MethodPost, "/api/v1/chat",
+				`{"message":"summarize","route":{"path":"/"},"attachments":[`+tt.attachment+`]}`), token)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+			}
+			if tt.wantCode != "" {
+				if body["code"] != tt.wantCode {
+					t.Errorf("code = %v, want %s", body["code"], tt.wantCode)
+				}
+				if server.fake.CallCount() != 0 {
+					t.Errorf("model called for a rejected attachment")
+				}
+				return
+			}
+
+			request, _ := server.fake.LastRequest()
+			parts := request.LastMessage().Parts
+			if len(parts) != 1 {
+				t.Fatalf("parts = %+v, want the attachment", parts)
+			}
+			if data, _ := base64.StdEncoding.DecodeString(parts[0].Data); string(data) != tt.wantData {
+				t.Errorf("attachment data = %q, want %q", data, tt.wantData)
+			}
+		})
+	}
+}
+
+func TestChatOmitsToolsForProvidersWithoutFunctionCalling(t *testing.T) {
+	tests := []struct {
+		name  string
+		tools string
+		want  string
+	}{
+		{"provider with tools", "true", "offered"},
+		{"provider without tools", "false", "plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifestYAML := `
+llm_providers:
+  - name: local
+    type: openai_compatible
+    base_url: http://localhost:11434/v1
+    capabilities:
+      tools: ` + tt.tools + `
+` + strings.Replace(toolManifest("http://localhost/login"), "    tools:", "    llm:\n      provider: local\n    tools:", 1)
+
+			var local *llmtest.Fake
+			server := newTestServer(t, manifestYAML, func(c *orchestator.Config) {
+				local = llmtest.New()
+				c.LLMProviders = map[string]llm.Client{"local": local.Client()}
+			})
+			local.On(llmtest.HasTool("login")).Reply("offered")
+			local.On(llmtest.SystemContains("AVAILABLE TOOLS")).Reply("listed")
+			local.On().Reply("plain")
+
+			status, body := server.do(t, jsonRequest(http.MethodPost, "/api/v1/chat",
+				`{"message":"hi","route":{"path":"/"}}`), "")
+			if status != http.StatusOK {
+				t.Fatalf("status = %d (%v)", status, body)
+			}
+			response, _ := body["response"].(map[string]any)
+			if response["response"] != tt.want {
+				t.Errorf("reply = %v, want %q", response["response"], tt.want)
+			}
+		})
+	}
+}
+
+func TestChatCachedRouteAnswersRepeatsFromCache(t *testing.T) {
+	cache := cachex.New(cachex.NewMemoryStore(0))
+	server := newTestServer(t, `
+version: "1"
+routes:
+  - name: faq
+    pattern: /
+    cache:
+      enabled: true
+`, func(c *orchestator.Config) {
+		c.ResponseCache = cache
+	})
+	server.fake.On().Reply("open 9 to 5")
+
+	token := server.token(t)
+	for range 2 {
+		status, body := server.do(t, jsonRequest(http.MethodPost, "/api/v1/chat",
+			`{"message":"opening hours?","route":{"path":"/"}}`), token)
+		if status != http.StatusOK {
+			t.Fatalf("status = %d (%v)", status, body)
+		}
+		response, _ := body["response"].(map[string]any)
+		if response["response"] != "open 9 to 5" {
+			t.Fatalf("reply = %v", response["response"])
+		}
+	}
+
+	if server.fake.CallCount() != 1 {
+		t.Errorf("model calls = %d, want 1", server.fake.CallCount())
+	}
+	if stats := cache.Stats(); stats.Hits != 1 {
+		t.Errorf("cache stats = %+v, want 1 hit", stats)
+	}
+}
+
+// scopedToolsManifest routes "/" to a public tool and one requiring orders:read
+const scopedToolsManifest = `
+version: "1"
+routes:
+  - name: orders
+    pattern: /
+    tools:
+      - name: faq
+        description: Answer common questions
+        type: http
+        config:
+          method: GET
+          url: http://localhost/faq
+      - name: lookup_order
+        description: Look up an order
+        type: http
+        required_scopes: [orders:read]
+        config:
+          method: GET
+          url: http://localhost/orders
+`
+
+func TestChatOffersToolsByVerifiedScopes(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		scopes    []string
+		anonymous bool
+		wantOrder bool
+	}{
+		{"anonymous", `{"message":"hi","route":{"path":"/"}}`, nil, true, false},
+		{"anonymous claiming scopes", `{"message":"hi","route":{"path":"/"},"scopes":["*"],"user":{"permissions":["orders:read"]}}`, nil, true, false},
+		{"authenticated without the scope", `{"message":"hi","route":{"path":"/"}}`, []string{"profile:read"}, false, false},
+		{"authenticated with the scope", `{"message":"hi","route":{"path":"/"}}`, []string{"orders:read"}, false, true},
+		{"prefix wildcard", `{"message":"hi","route":{"path":"/"}}`, []string{"orders:*"}, false, true},
+		{"wildcard", `{"message":"hi","route":{"path":"/"}}`, []string{"*"}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, scopedToolsManifest)
+			server.fake.On().Reply("hello")
+
+			var token string
+			if !tt.anonymous {
+				token = server.token(t, tt.scopes...)
+			}
+			status, body := server.do(t, jsonRequest(http.MethodPost, "/api/v1/chat", tt.body), token)
+			if status != http.StatusOK {
+				t.Fatalf("status = %d (%v)", status, body)
+			}
+
+			request, _ := server.fake.LastRequest()
+			if !llmtest.HasTool("faq")(request) {
+				t.Errorf("public tool not offered")
+			}
+			if got := llmtest.HasTool("lookup_order")(request); got != tt.wantOrder {
+				t.Errorf("lookup_order offered = %v, want %v", got, tt.wantOrder)
+			}
+			if got := strings.Contains(request.Messages[0].Content, "lookup_order"); got != tt.wantOrder {
+				t.Errorf("lookup_order listed in the context = %v, want %v", got, tt.wantOrder)
+			}
+		})
+	}
+}
+
+// toolCallRepository keeps recorded tool calls and the last list filter
+type toolCallRepository struct {
+	mu      sync.Mutex
+	records []*memoryx.ToolCallRecord
+	filter  memoryx.ToolCallFilter
+}
+
+func (r *toolCallRepository) RecordToolCall(ctx context.Context, record *memoryx.ToolCallRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, record)
+	return nil
+}
+
+func (r *toolCallRepository) ListToolCalls(ctx context.Context, filter memoryx.ToolCallFilter) ([]*memoryx.ToolCallRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.filter = filter
+	return r.records, nil
+}
+
+func (r *toolCallRepository) DeleteToolCallsBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestChatDryRunSimulatesToolCalls(t *testing.T) {
+	var hits atomic.Int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer backend.Close()
+
+	liveManifest := strings.Replace(toolManifest(backend.URL), "        parameters:",
+		"        mock_response: {\"ok\": \"simulated\"}\n        parameters:", 1)
+	routeDryRunManifest := strings.Replace(liveManifest, "    tools:", "    safety:\n      dry_run: true\n    tools:", 1)
+
+	tests := []struct {
+		name      string
+		manifest  string
+		dryRun    string
+		scopes    []string
+		anonymous bool
+		wantLive  bool // The backend is called and the call audited
+	}{
+		{"anonymous live call", liveManifest, "false", nil, true, true},
+		{"anonymous request dry run", liveManifest, "true", nil, true, false},
+		{"authenticated request dry run", liveManifest, "true", []string{"orders:read"}, false, false},
+		{"wildcard request dry run", liveManifest, "true", []string{"*"}, false, false},
+		{"route dry run can't be disabled", routeDryRunManifest, "false", []string{"*"}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits.Store(0)
+			audit := &toolCallRepository{}
+			server := newTestServer(t, tt.manifest, func(c *orchestator.Config) {
+				c.ToolCallSrv = memorysrv.NewToolCallService(audit)
+			})
+			server.fake.On(llmtest.AfterToolResult("login")).Reply("signed in")
+			server.fake.On().ReplyToolCall("login", map[string]any{"username": "bob", "password": "hunter2"})
+
+			var token string
+			if !tt.anonymous {
+				token = server.token(t, tt.scopes...)
+			}
+			status, body := server.do(t, jsonRequest(http.MethodPost, "/api/v1/chat",
+				`{"message":"sign me in","route":{"path":"/"},"dry_run":`+tt.dryRun+`}`), token)
+			if status != http.StatusOK {
+				t.Fatalf("status = %d (%v)", status, body)
+			}
+
+			wantHits, wantRecords := 0, 0
+			if tt.wantLive {
+				wantHits, wantRecords = 1, 1
+			}
+			if got := int(hits.Load()); got != wantHits {
+				t.Errorf("backend hits = %d, want %d", got, wantHits)
+			}
+			if len(audit.records) != wantRecords {
+				t.Errorf("audit records = %d, want %d", len(audit.records), wantRecords)
+			}
+
+			request, _ := server.fake.LastRequest()
+			result := request.LastMessage().Content
+			if simulated := strings.Contains(result, "simulated"); simulated == tt.wantLive {
+				t.Errorf("tool result = %s, want simulated = %v", result, !tt.wantLive)
+			}
+		})
+	}
+}
+
+func TestToolCallAuditIsScopedToTheCaller(t *testing.T) {
+	tests := []struct {
+		name       string
+		scopes     []string
+		anonymous  bool
+		wantStatus int
+		wantUserID string
+	}{
+		{"anonymous", nil, true, http.StatusUnauthorized, ""},
+		{"authenticated", []string{"orders:read"}, false, http.StatusOK, "user-1"},
+		{"audit scope", []string{"audit:read"}, false, http.StatusOK, "user-2"},
+		{"admin", []string{"admin:*"}, false, http.StatusOK, "user-2"},
+		{"wildcard", []string{"*"}, false, http.StatusOK, "user-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit := &toolCallRepository{}
+			server := newTestServer(t, testManifest, func(c *orchestator.Config) {
+				c.ToolCallSrv = memorysrv.NewToolCallService(audit)
+			})
+
+			var token string
+			if !tt.anonymous {
+				token = server.token(t, tt.scopes...)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/tool-calls?user_id=user-2", nil)
+			status, body := server.do(t, req, token)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+			}
+			if audit.filter.UserID != tt.wantUserID {
+				t.Errorf("filter user = %q, want %q", audit.filter.UserID, tt.wantUserID)
+			}
+		})
+	}
+}
@@ -55,7 +55,7 @@ func (b *Builder) Build(
 		Frontend:       frontendContext,
 		Backend:        make(map[string]any),
 		Instructions:   routeMatch.Route.AgentInstructions,
-		AvailableTools: extractToolNames(routeMatch.Route.Tools),
+		AvailableTools: extractToolNames(routeMatch.Route.AllowedTools(user.GrantedScopes())),
 	}
 
 	logx.WithFields(logx.Fields{
@@ -77,7 +77,7 @@ func (b *Builder) Build(
 	logx.WithField("param_count", len(params)).Debug("Provider parameters built")
 
 	// Execute all providers in parallel
-	backendData, err := b.executeProviders(ctx, providerClient, routeMatch.Route.Context.Providers, params)
+	backendData, err := b.executeProviders(ctx, providerClient, routeMatch.Route.Context.Providers, params, user.GrantedScopes())
 	if err != nil {
 		// Log error but don't fail completely - partial context is OK
 		logx.WithError(err).Warn("Some providers failed, continuing with partial context")
@@ -141,6 +141,7 @@ func (b *Builder) executeProviders(
 	providerClient *ProviderClient,
 	providerConfigs []manifest.Provider,
 	baseParams map[string]any,
+	grantedScopes []string,
 ) (map[string]any, error) {
 	logx.WithField("provider_count", len(providerConfigs)).Info("Executing providers in parallel")
 
@@ -164,6 +165,16 @@ func (b *Builder) executeProviders(
 			continue
 		}
 
+		// Skip if the user lacks the required scopes
+		if !config.IsAllowed(grantedScopes) {
+			logx.WithFields(logx.Fields{
+				"provider":        config.Name,
+				"required_scopes": config.RequiredScopes,
+			}).Debug("Provider skipped due to missing scopes")
+			skippedCount++
+			continue
+		}
+
 		wg.Add(1)
 		go func(cfg manifest.Provider) {
 			defer wg.Done()
@@ -325,7 +336,7 @@ func (b *Builder) BuildMinimal(
 		Frontend:       nil,
 		Backend:        make(map[string]any),
 		Instructions:   routeMatch.Route.AgentInstructions,
-		AvailableTools: extractToolNames(routeMatch.Route.Tools),
+		AvailableTools: extractToolNames(routeMatch.Route.AllowedTools(user.GrantedScopes())),
 	}
 
 	logx.WithField("route_name", routeMatch.Route.Name).Debug("Minimal context built successfully")
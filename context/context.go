@@ -30,6 +30,7 @@ type RouteInfo struct {
 // User represents the current user
 type User struct {
 	ID          string         `json:"id"`
+	TenantID    string         `json:"tenant_id,omitempty"`
 	Email       string         `json:"email,omitempty"`
 	Name        string         `json:"name,omitempty"`
 	Permissions []string       `json:"permissions,omitempty"`
@@ -186,6 +187,15 @@ func (u *User) HasPermission(permission string) bool {
 	return slices.Contains(u.Permissions, permission)
 }
 
+// GrantedScopes returns the scopes used to gate tools and providers
+// Permissions must come from a verified token, never from the request body
+func (u *User) GrantedScopes() []string {
+	if u == nil {
+		return nil
+	}
+	return u.Permissions
+}
+
 // GetAnonymousID safely gets the anonymous ID from frontend context
 func (fc *FrontendContext) GetAnonymousID() string {
 	if fc == nil {
@@ -1,9 +1,7 @@
 package context
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,7 +9,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Abraxas-365/ams/pkg/fsx"
+	"github.com/Abraxas-365/ams/pkg/httpx"
 	"github.com/Abraxas-365/ams/pkg/logx"
+	"github.com/Abraxas-365/ams/pkg/shapex"
 )
 
 // HTTPProvider makes HTTP requests to fetch context
@@ -28,6 +29,12 @@ type HTTPConfig struct {
 	Headers map[string]string // HTTP headers (supports templating)
 	Body    interface{}       // Request body (for POST/PUT/PATCH)
 	Timeout time.Duration     // Request timeout
+	Shaping shapex.Options    // Response path, template and truncation
+
+	Query      map[string]string // Query parameters (supports templating, properly encoded)
+	BodyType   httpx.BodyType    // json (default), form, multipart, text, xml
+	Files      []httpx.File      // Multipart file parts
+	FileSystem fsx.FileReader    // Source for multipart files
 }
 
 // NewHTTPProvider creates a new HTTP context provider
@@ -76,56 +83,36 @@ func (p *HTTPProvider) GetContext(ctx context.Context, params map[string]interfa
 		"url":      url,
 	}).Debug("URL resolved")
 
-	// 2. Prepare request body if needed
-	var bodyReader io.Reader
-	if p.config.Body != nil {
-		bodyJSON, err := json.Marshal(p.config.Body)
-		if err != nil {
-			logx.WithFields(logx.Fields{
-				"provider": p.name,
-			}).WithError(err).Error("Failed to marshal request body")
-			return nil, NewProviderFailedError(p.name, fmt.Errorf("error marshaling body: %w", err))
-		}
-
-		// Also resolve templates in body
-		bodyStr := p.resolveTemplate(string(bodyJSON), params)
-		bodyReader = bytes.NewReader([]byte(bodyStr))
-		logx.WithFields(logx.Fields{
-			"provider":    p.name,
-			"body_length": len(bodyStr),
-		}).Debug("Request body prepared")
+	// 2. Build request (query encoding, body by type, headers with templates)
+	spec := httpx.Spec{
+		Method:   p.config.Method,
+		URL:      url,
+		Headers:  p.config.Headers,
+		Query:    p.config.Query,
+		BodyType: p.config.BodyType,
+		Body:     p.config.Body,
+		Files:    p.config.Files,
+	}
+	resolve := func(template string) string {
+		return p.resolveTemplate(template, params)
 	}
 
-	// 3. Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, p.config.Method, url, bodyReader)
+	req, err := httpx.NewRequest(ctx, spec, resolve, p.config.FileSystem)
 	if err != nil {
 		logx.WithFields(logx.Fields{
-			"provider": p.name,
-			"url":      url,
+			"provider":  p.name,
+			"url":       url,
+			"body_type": p.config.BodyType,
 		}).WithError(err).Error("Failed to create HTTP request")
 		return nil, NewProviderFailedError(p.name, fmt.Errorf("error creating request: %w", err))
 	}
 
-	// 4. Add headers (with template resolution)
-	headerCount := 0
-	for key, value := range p.config.Headers {
-		resolvedValue := p.resolveTemplate(value, params)
-		req.Header.Set(key, resolvedValue)
-		headerCount++
-	}
-
-	// Set Content-Type for requests with body
-	if p.config.Body != nil && req.Header.Get("Content-Type") == "" {
-		req.Header.Set("Content-Type", "application/json")
-		headerCount++
-	}
-
-	if headerCount > 0 {
-		logx.WithFields(logx.Fields{
-			"provider":     p.name,
-			"header_count": headerCount,
-		}).Debug("Request headers set")
-	}
+	logx.WithFields(logx.Fields{
+		"provider":     p.name,
+		"body_type":    p.config.BodyType,
+		"query_count":  len(p.config.Query),
+		"header_count": len(req.Header),
+	}).Debug("Request prepared")
 
 	// 5. Execute request
 	logx.WithFields(logx.Fields{
@@ -190,12 +177,33 @@ func (p *HTTPProvider) GetContext(ctx context.Context, params map[string]interfa
 		"body_length": len(body),
 	}).Debug("Response body read")
 
-	// 8. Try to parse as JSON
-	var result interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		// If not JSON, return as string
-		logx.WithField("provider", p.name).Debug("Response is not JSON, returning as string")
-		return string(body), nil
+	// 8. Parse JSON, XML or CSV into structured data
+	result, err := httpx.Decode(body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		logx.WithFields(logx.Fields{
+			"provider": p.name,
+		}).WithError(err).Error("Failed to decode response")
+		return nil, NewProviderFailedError(p.name, err)
+	}
+
+	if text, ok := result.(string); ok {
+		// Unstructured response, return as string (only truncation applies)
+		logx.WithField("provider", p.name).Debug("Response is not structured, returning as string")
+		if p.config.Shaping.MaxBytes > 0 {
+			return shapex.Truncate(text, p.config.Shaping.MaxBytes), nil
+		}
+		return text, nil
+	}
+
+	// 9. Shape the response (path selection, template, truncation)
+	if !p.config.Shaping.IsZero() {
+		result, err = shapex.Apply(result, p.config.Shaping)
+		if err != nil {
+			logx.WithFields(logx.Fields{
+				"provider": p.name,
+			}).WithError(err).Error("Failed to shape response")
+			return nil, NewProviderFailedError(p.name, err)
+		}
 	}
 
 	logx.WithFields(logx.Fields{
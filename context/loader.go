@@ -6,15 +6,31 @@ import (
 	"time"
 
 	"github.com/Abraxas-365/ams/manifest"
+	"github.com/Abraxas-365/ams/pkg/fsx"
 )
 
 // ProviderLoader loads context providers from manifest configuration
 type ProviderLoader struct {
+	fs fsx.FileReader
+}
+
+// ProviderLoaderOption configures the provider loader
+type ProviderLoaderOption func(*ProviderLoader)
+
+// WithFileSystem sets the file source for multipart provider requests
+func WithFileSystem(fs fsx.FileReader) ProviderLoaderOption {
+	return func(l *ProviderLoader) {
+		l.fs = fs
+	}
 }
 
 // NewProviderLoader creates a new provider loader
-func NewProviderLoader() *ProviderLoader {
-	return &ProviderLoader{}
+func NewProviderLoader(opts ...ProviderLoaderOption) *ProviderLoader {
+	loader := &ProviderLoader{}
+	for _, opt := range opts {
+		opt(loader)
+	}
+	return loader
 }
 
 // LoadFromRouteConfig creates a ProviderClient from route configuration
@@ -76,12 +92,18 @@ func (l *ProviderLoader) createHTTPProvider(config manifest.Provider) (Provider,
 	}
 
 	// Create HTTP config
+	spec := config.RequestSpec(method, config.URL, config.Headers, config.Body)
 	httpConfig := HTTPConfig{
-		URL:     config.URL,
-		Method:  method,
-		Headers: config.Headers,
-		Body:    config.Body,
-		Timeout: timeout,
+		URL:        config.URL,
+		Method:     method,
+		Headers:    config.Headers,
+		Body:       config.Body,
+		Timeout:    timeout,
+		Shaping:    config.ShapeOptions(),
+		Query:      spec.Query,
+		BodyType:   spec.BodyType,
+		Files:      spec.Files,
+		FileSystem: l.fs,
 	}
 
 	return NewHTTPProvider(config.Name, httpConfig), nil
@@ -102,6 +124,9 @@ func (l *ProviderLoader) ValidateProviderConfig(config manifest.Provider) error
 		if config.URL == "" {
 			return NewInvalidProviderConfigError(config.Name, "URL is required for HTTP provider")
 		}
+		if err := config.RequestEncoding.Validate(); err != nil {
+			return NewInvalidProviderConfigError(config.Name, err.Error())
+		}
 	default:
 		return NewUnsupportedProviderTypeError(config.Type)
 	}
@@ -0,0 +1,43 @@
+// manifest/cache.go
+package manifest
+
+import (
+	"fmt"
+	"time"
+)
+
+// CacheSettings opts a route into exact-match LLM response caching
+// Only useful for FAQ-style routes where identical questions meet identical context
+type CacheSettings struct {
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+	TTL     string `json:"ttl,omitempty" yaml:"ttl,omitempty"` // Go duration, e.g. "1h" (default 1h)
+}
+
+// IsEnabled reports whether caching is on; nil-safe
+func (c *CacheSettings) IsEnabled() bool {
+	return c != nil && c.Enabled
+}
+
+// TTLDuration returns the parsed TTL, or 0 for the cache default
+func (c *CacheSettings) TTLDuration() time.Duration {
+	if c == nil || c.TTL == "" {
+		return 0
+	}
+	ttl, _ := time.ParseDuration(c.TTL)
+	return ttl
+}
+
+// Validate checks the TTL
+func (c *CacheSettings) Validate() error {
+	if c == nil || c.TTL == "" {
+		return nil
+	}
+	ttl, err := time.ParseDuration(c.TTL)
+	if err != nil {
+		return fmt.Errorf("invalid cache ttl %q: %w", c.TTL, err)
+	}
+	if ttl <= 0 {
+		return fmt.Errorf("cache ttl must be positive, got %s", c.TTL)
+	}
+	return nil
+}
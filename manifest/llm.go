@@ -0,0 +1,152 @@
+// manifest/llm.go
+package manifest
+
+import (
+	"fmt"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+)
+
+// LLMSettings configures the model and agent loop for a route
+// Set at manifest level as the default; route values override field by field.
+// Pointer fields distinguish "not set" from a zero value
+type LLMSettings struct {
+	Provider            string   `json:"provider,omitempty" yaml:"provider,omitempty"` // Name from llm_providers (default client when empty)
+	Model               string   `json:"model,omitempty" yaml:"model,omitempty"`
+	Temperature         *float32 `json:"temperature,omitempty" yaml:"temperature,omitempty"`
+	TopP                *float32 `json:"top_p,omitempty" yaml:"top_p,omitempty"`
+	MaxCompletionTokens int      `json:"max_completion_tokens,omitempty" yaml:"max_completion_tokens,omitempty"`
+	ReasoningEffort     string   `json:"reasoning_effort,omitempty" yaml:"reasoning_effort,omitempty"` // minimal, low, medium, high
+	Seed                *int64   `json:"seed,omitempty" yaml:"seed,omitempty"`
+	ToolChoice          string   `json:"tool_choice,omitempty" yaml:"tool_choice,omitempty"` // auto, none, required (first turn only)
+
+	// Agent loop limits
+	MaxAutoIterations  int `json:"max_auto_iterations,omitempty" yaml:"max_auto_iterations,omitempty"`
+	MaxTotalIterations int `json:"max_total_iterations,omitempty" yaml:"max_total_iterations,omitempty"`
+}
+
+var (
+	validReasoningEfforts = map[string]bool{"minimal": true, "low": true, "medium": true, "high": true}
+	validToolChoices      = map[string]bool{"auto": true, "none": true, "required": true}
+)
+
+// Merge returns the settings with override applied on top
+// Either side may be nil
+func (s *LLMSettings) Merge(override *LLMSettings) *LLMSettings {
+	if s == nil && override == nil {
+		return nil
+	}
+
+	merged := LLMSettings{}
+	if s != nil {
+		merged = *s
+	}
+	if override == nil {
+		return &merged
+	}
+
+	if override.Provider != "" {
+		merged.Provider = override.Provider
+	}
+	if override.Model != "" {
+		merged.Model = override.Model
+	}
+	if override.Temperature != nil {
+		merged.Temperature = override.Temperature
+	}
+	if override.TopP != nil {
+		merged.TopP = override.TopP
+	}
+	if override.MaxCompletionTokens > 0 {
+		merged.MaxCompletionTokens = override.MaxCompletionTokens
+	}
+	if override.ReasoningEffort != "" {
+		merged.ReasoningEffort = override.ReasoningEffort
+	}
+	if override.Seed != nil {
+		merged.Seed = override.Seed
+	}
+	if override.ToolChoice != "" {
+		merged.ToolChoice = override.ToolChoice
+	}
+	if override.MaxAutoIterations > 0 {
+		merged.MaxAutoIterations = override.MaxAutoIterations
+	}
+	if override.MaxTotalIterations > 0 {
+		merged.MaxTotalIterations = override.MaxTotalIterations
+	}
+
+	return &merged
+}
+
+// Options converts the model settings to LLM options
+func (s *LLMSettings) Options() []llm.Option {
+	if s == nil {
+		return nil
+	}
+
+	options := make([]llm.Option, 0)
+	if s.Model != "" {
+		options = append(options, llm.WithModel(s.Model))
+	}
+	if s.Temperature != nil {
+		options = append(options, llm.WithTemperature(*s.Temperature))
+	}
+	if s.TopP != nil {
+		options = append(options, llm.WithTopP(*s.TopP))
+	}
+	if s.MaxCompletionTokens > 0 {
+		options = append(options, llm.WithMaxCompletionTokens(s.MaxCompletionTokens))
+	}
+	if s.ReasoningEffort != "" {
+		options = append(options, llm.WithReasoningEffort(s.ReasoningEffort))
+	}
+	if s.Seed != nil {
+		options = append(options, llm.WithSeed(*s.Seed))
+	}
+	if s.ToolChoice != "" {
+		options = append(options, llm.WithToolChoice(s.ToolChoice))
+	}
+	return options
+}
+
+// Validate checks ranges and enums
+// Models are checked by the manifest, since they depend on the selected provider
+func (s *LLMSettings) Validate() error {
+	if s == nil {
+		return nil
+	}
+	if s.Temperature != nil && (*s.Temperature < 0 || *s.Temperature > 2) {
+		return fmt.Errorf("temperature must be between 0 and 2, got %v", *s.Temperature)
+	}
+	if s.TopP != nil && (*s.TopP < 0 || *s.TopP > 1) {
+		return fmt.Errorf("top_p must be between 0 and 1, got %v", *s.TopP)
+	}
+	if s.MaxCompletionTokens < 0 {
+		return fmt.Errorf("max_completion_tokens must be positive, got %d", s.MaxCompletionTokens)
+	}
+	if s.ReasoningEffort != "" && !validReasoningEfforts[s.ReasoningEffort] {
+		return fmt.Errorf("invalid reasoning_effort %q (expected minimal, low, medium or high)", s.ReasoningEffort)
+	}
+	if s.ToolChoice != "" && !validToolChoices[s.ToolChoice] {
+		return fmt.Errorf("invalid tool_choice %q (expected auto, none or required)", s.ToolChoice)
+	}
+	if s.MaxAutoIterations < 0 || s.MaxTotalIterations < 0 {
+		return fmt.Errorf("iteration limits must be positive")
+	}
+	if s.MaxAutoIterations > 0 && s.MaxTotalIterations > 0 && s.MaxAutoIterations > s.MaxTotalIterations {
+		return fmt.Errorf("max_auto_iterations (%d) cannot exceed max_total_iterations (%d)",
+			s.MaxAutoIterations, s.MaxTotalIterations)
+	}
+
+	return nil
+}
+
+// validateKnownModel checks a model against the catalog
+// Skipped when no provider package has registered models
+func validateKnownModel(model string) error {
+	if len(llm.KnownModels()) > 0 && !llm.IsKnownModel(model) {
+		return fmt.Errorf("unknown model %q (known: %v)", model, llm.KnownModels())
+	}
+	return nil
+}
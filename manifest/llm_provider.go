@@ -0,0 +1,162 @@
+// manifest/llm_provider.go
+package manifest
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+)
+
+// LLM provider types
+const (
+	LLMProviderOpenAI           = "openai"
+	LLMProviderOpenAICompatible = "openai_compatible" // vLLM, Ollama, LM Studio...
+	LLMProviderAnthropic        = "anthropic"
+	LLMProviderGemini           = "gemini"
+)
+
+var validLLMProviderTypes = map[string]bool{
+	LLMProviderOpenAI:           true,
+	LLMProviderOpenAICompatible: true,
+	LLMProviderAnthropic:        true,
+	LLMProviderGemini:           true,
+}
+
+// LLMProvider is a named model backend that routes select with `llm.provider`
+// API keys are never stored in the manifest; APIKeyEnv names the variable to read
+type LLMProvider struct {
+	Name         string               `json:"name" yaml:"name"`
+	Type         string               `json:"type" yaml:"type"`
+	BaseURL      string               `json:"base_url,omitempty" yaml:"base_url,omitempty"`
+	APIKeyEnv    string               `json:"api_key_env,omitempty" yaml:"api_key_env,omitempty"`
+	DefaultModel string               `json:"default_model,omitempty" yaml:"default_model,omitempty"`
+	Models       []string             `json:"models,omitempty" yaml:"models,omitempty"` // Allowed models (optional)
+	Capabilities ProviderCapabilities `json:"capabilities,omitempty" yaml:"capabilities,omitempty"`
+	RateLimits   map[string]RateLimit `json:"rate_limits,omitempty" yaml:"rate_limits,omitempty"` // Per model; "*" applies to the others
+}
+
+// RateLimit is a model's per-minute budget; zero means unlimited
+type RateLimit struct {
+	RPM int `json:"rpm,omitempty" yaml:"rpm,omitempty"` // Requests per minute
+	TPM int `json:"tpm,omitempty" yaml:"tpm,omitempty"` // Tokens per minute
+}
+
+// ProviderCapabilities declares what the backend supports (all default to true)
+// Unsupported features degrade instead of failing: tools are not offered,
+// JSON schemas become prompt instructions, and streams are emulated
+type ProviderCapabilities struct {
+	Tools      *bool `json:"tools,omitempty" yaml:"tools,omitempty"`
+	JSONSchema *bool `json:"json_schema,omitempty" yaml:"json_schema,omitempty"`
+	Streaming  *bool `json:"streaming,omitempty" yaml:"streaming,omitempty"`
+}
+
+// ToLLM resolves unset flags to true
+func (c ProviderCapabilities) ToLLM() llm.Capabilities {
+	enabled := func(flag *bool) bool { return flag == nil || *flag }
+	return llm.Capabilities{
+		Tools:      enabled(c.Tools),
+		JSONSchema: enabled(c.JSONSchema),
+		Streaming:  enabled(c.Streaming),
+	}
+}
+
+// Validate checks the provider definition
+func (p *LLMProvider) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("llm provider name is required")
+	}
+	if !validLLMProviderTypes[p.Type] {
+		return fmt.Errorf("llm provider %s: invalid type %q (expected openai, openai_compatible, anthropic or gemini)", p.Name, p.Type)
+	}
+	if p.Type == LLMProviderOpenAICompatible && p.BaseURL == "" {
+		return fmt.Errorf("llm provider %s: base_url is required for openai_compatible", p.Name)
+	}
+	if p.BaseURL != "" {
+		if u, err := url.Parse(p.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("llm provider %s: invalid base_url %q", p.Name, p.BaseURL)
+		}
+	}
+	if p.DefaultModel != "" {
+		if err := p.validateModel(p.DefaultModel); err != nil {
+			return fmt.Errorf("llm provider %s: default_model: %w", p.Name, err)
+		}
+	}
+	for model, limit := range p.RateLimits {
+		if limit.RPM < 0 || limit.TPM < 0 {
+			return fmt.Errorf("llm provider %s: rate limit for %s must not be negative", p.Name, model)
+		}
+	}
+	return nil
+}
+
+// validateModel checks a model against the provider's allow list, or the
+// model catalog for first-party APIs; self-hosted servers accept any name
+func (p *LLMProvider) validateModel(model string) error {
+	if len(p.Models) > 0 {
+		if !slices.Contains(p.Models, model) {
+			return fmt.Errorf("model %q is not offered by provider %s (models: %v)", model, p.Name, p.Models)
+		}
+		return nil
+	}
+	if p.Type == LLMProviderOpenAICompatible {
+		return nil
+	}
+	return validateKnownModel(model)
+}
+
+// GetLLMProvider returns the named provider, or nil
+func (m *Manifest) GetLLMProvider(name string) *LLMProvider {
+	for i := range m.LLMProviders {
+		if m.LLMProviders[i].Name == name {
+			return &m.LLMProviders[i]
+		}
+	}
+	return nil
+}
+
+// validateLLMProviders checks provider definitions and duplicate names
+func validateLLMProviders(providers []LLMProvider) []error {
+	errors := make([]error, 0)
+	names := make(map[string]bool)
+	for i := range providers {
+		provider := &providers[i]
+		if err := provider.Validate(); err != nil {
+			errors = append(errors, NewValidationError(err.Error()))
+			continue
+		}
+		if names[provider.Name] {
+			errors = append(errors, NewValidationError(fmt.Sprintf("duplicate llm provider name %q", provider.Name)))
+		}
+		names[provider.Name] = true
+	}
+	return errors
+}
+
+// validateLLM checks effective LLM settings, resolving the model against
+// the selected provider when one is set
+func (m *Manifest) validateLLM(settings *LLMSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	if settings == nil {
+		return nil
+	}
+
+	if settings.Provider == "" {
+		if settings.Model != "" {
+			return validateKnownModel(settings.Model)
+		}
+		return nil
+	}
+
+	provider := m.GetLLMProvider(settings.Provider)
+	if provider == nil {
+		return fmt.Errorf("unknown llm provider %q", settings.Provider)
+	}
+	if settings.Model != "" {
+		return provider.validateModel(settings.Model)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package manifest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/errx"
+)
+
+func TestValidateManifestRejectsUnknownModels(t *testing.T) {
+	llm.RegisterModels("known-model")
+
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name: "known route model",
+			yaml: `
+version: "1"
+routes:
+  - name: home
+    pattern: /
+    llm:
+      model: known-model
+`,
+		},
+		{
+			name: "unknown route model",
+			yaml: `
+version: "1"
+routes:
+  - name: home
+    pattern: /
+    llm:
+      model: made-up-model
+`,
+			wantErr: "made-up-model",
+		},
+		{
+			name: "unknown default model",
+			yaml: `
+version: "1"
+llm:
+  model: made-up-model
+routes:
+  - name: home
+    pattern: /
+`,
+			wantErr: "made-up-model",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewRegistry().LoadFromYAML([]byte(tt.yaml))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var validationErr *errx.Error
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("error = %v, want a validation error", err)
+			}
+			details := fmt.Sprint(validationErr.Details["errors"])
+			if !strings.Contains(details, tt.wantErr) {
+				t.Fatalf("validation errors = %s, want one mentioning %q", details, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLLMSettingsLeaveTemperatureUnset(t *testing.T) {
+	options := llm.DefaultOptions()
+	for _, option := range (&LLMSettings{Model: "known-model"}).Options() {
+		option(options)
+	}
+	if options.TemperatureSet {
+		t.Errorf("temperature set to %v without a setting", options.Temperature)
+	}
+}
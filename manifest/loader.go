@@ -3,10 +3,12 @@ package manifest
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/Abraxas-365/ams/pkg/shapex"
 	"gopkg.in/yaml.v3"
 )
 
@@ -30,7 +32,7 @@ func (r *Registry) LoadFromFile(filepath string) error {
 	}
 
 	format := DetectFormat(filepath, data)
-	return r.LoadFromBytes(data, format)
+	return r.loadFromBytes(data, format, manifestDir(filepath))
 }
 
 // LoadFromYAML loads manifest from YAML data
@@ -44,12 +46,22 @@ func (r *Registry) LoadFromJSON(data []byte) error {
 }
 
 // LoadFromBytes loads manifest from bytes with specified format
+// Relative schema files are resolved against the working directory
 func (r *Registry) LoadFromBytes(data []byte, format Format) error {
+	return r.loadFromBytes(data, format, "")
+}
+
+// loadFromBytes parses, resolves schema files against baseDir, validates and loads
+func (r *Registry) loadFromBytes(data []byte, format Format, baseDir string) error {
 	manifest, err := ParseManifest(data, format)
 	if err != nil {
 		return err
 	}
 
+	if err := loadSchemaFiles(manifest, baseDir); err != nil {
+		return err
+	}
+
 	if err := ValidateManifest(manifest); err != nil {
 		return err
 	}
@@ -78,6 +90,10 @@ func LoadManifest(filepath string) (*Manifest, error) {
 		return nil, err
 	}
 
+	if err := loadSchemaFiles(manifest, manifestDir(filepath)); err != nil {
+		return nil, err
+	}
+
 	if err := ValidateManifest(manifest); err != nil {
 		return nil, err
 	}
@@ -85,6 +101,11 @@ func LoadManifest(filepath string) (*Manifest, error) {
 	return manifest, nil
 }
 
+// manifestDir returns the directory relative schema files are resolved against
+func manifestDir(file string) string {
+	return filepath.Dir(file)
+}
+
 // ParseManifest parses manifest from bytes with specified format
 func ParseManifest(data []byte, format Format) (*Manifest, error) {
 	var manifest Manifest
@@ -166,7 +187,11 @@ func ValidateManifest(manifest *Manifest) error {
 
 	// Track route names to detect duplicates
 	routeNames := make(map[string]bool)
-	validationErrors := make([]error, 0)
+	validationErrors := validateLLMProviders(manifest.LLMProviders)
+
+	if err := manifest.validateLLM(manifest.LLM); err != nil {
+		validationErrors = append(validationErrors, NewValidationError(fmt.Sprintf("llm: %v", err)))
+	}
 
 	// Validate each route
 	for i, route := range manifest.Routes {
@@ -174,6 +199,12 @@ func ValidateManifest(manifest *Manifest) error {
 			validationErrors = append(validationErrors, err)
 		}
 
+		// Validate the effective LLM settings (default + route override)
+		if err := manifest.validateLLM(manifest.LLM.Merge(route.LLM)); err != nil {
+			validationErrors = append(validationErrors,
+				NewValidationError(fmt.Sprintf("route %s llm: %v", route.Name, err)))
+		}
+
 		// Check for duplicate route names
 		if routeNames[route.Name] {
 			validationErrors = append(validationErrors,
@@ -192,6 +223,10 @@ func ValidateManifest(manifest *Manifest) error {
 		if err := validateRoute(manifest.Fallback); err != nil {
 			validationErrors = append(validationErrors, err)
 		}
+		if err := manifest.validateLLM(manifest.LLM.Merge(manifest.Fallback.LLM)); err != nil {
+			validationErrors = append(validationErrors,
+				NewValidationError(fmt.Sprintf("fallback llm: %v", err)))
+		}
 	}
 
 	if len(validationErrors) > 0 {
@@ -217,6 +252,24 @@ func validateRoute(route *Route) error {
 		}
 	}
 
+	if err := route.ResponseFormat.Validate(); err != nil {
+		return NewValidationError(fmt.Sprintf("route %s: %v", route.Name, err))
+	}
+
+	if err := route.Cache.Validate(); err != nil {
+		return NewValidationError(fmt.Sprintf("route %s: %v", route.Name, err))
+	}
+
+	// Validate tool request encoding and response shaping
+	for _, tool := range route.Tools {
+		if err := tool.Config.RequestEncoding.Validate(); err != nil {
+			return NewValidationError(fmt.Sprintf("tool %s: %v", tool.Name, err))
+		}
+		if err := shapex.Validate(tool.Config.ShapeOptions()); err != nil {
+			return NewValidationError(fmt.Sprintf("tool %s: %v", tool.Name, err))
+		}
+	}
+
 	return nil
 }
 
@@ -238,6 +291,14 @@ func validateProvider(provider *Provider) error {
 		}
 	}
 
+	if err := provider.RequestEncoding.Validate(); err != nil {
+		return NewInvalidProviderError(provider.Name, err.Error())
+	}
+
+	if err := shapex.Validate(provider.ShapeOptions()); err != nil {
+		return NewInvalidProviderError(provider.Name, err.Error())
+	}
+
 	return nil
 }
 
@@ -7,6 +7,9 @@ import (
 	"regexp"
 	"sync"
 
+	"github.com/Abraxas-365/ams/pkg/httpx"
+	"github.com/Abraxas-365/ams/pkg/kernel"
+	"github.com/Abraxas-365/ams/pkg/shapex"
 	"gopkg.in/yaml.v3"
 )
 
@@ -15,6 +18,12 @@ type Manifest struct {
 	Version  string  `json:"version" yaml:"version"`
 	Routes   []Route `json:"routes" yaml:"routes"`
 	Fallback *Route  `json:"fallback,omitempty" yaml:"fallback,omitempty"`
+
+	// Default LLM settings, overridden per route
+	LLM *LLMSettings `json:"llm,omitempty" yaml:"llm,omitempty"`
+
+	// Named model backends that LLM settings select with `provider`
+	LLMProviders []LLMProvider `json:"llm_providers,omitempty" yaml:"llm_providers,omitempty"`
 }
 
 // Route represents a single route configuration
@@ -26,6 +35,18 @@ type Route struct {
 	Tools             []Tool  `json:"tools" yaml:"tools"` // ✅ Changed from []string
 	AgentInstructions string  `json:"agent_instructions" yaml:"agent_instructions"`
 	Safety            Safety  `json:"safety" yaml:"safety"`
+
+	// Named agent interceptors registered with the orchestrator, applied in order
+	Interceptors []string `json:"interceptors,omitempty" yaml:"interceptors,omitempty"`
+
+	// Model and agent loop settings (merged with the manifest default on load)
+	LLM *LLMSettings `json:"llm,omitempty" yaml:"llm,omitempty"`
+
+	// Structured JSON reply contract (optional)
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty" yaml:"response_format,omitempty"`
+
+	// Exact-match response caching (opt-in)
+	Cache *CacheSettings `json:"cache,omitempty" yaml:"cache,omitempty"`
 }
 
 // Context holds context provider configurations
@@ -45,6 +66,15 @@ type Provider struct {
 	Params    map[string]any    `json:"params" yaml:"params"`
 	Condition string            `json:"condition" yaml:"condition"`
 	Optional  bool              `json:"optional" yaml:"optional"`
+
+	// Query and body encoding
+	RequestEncoding `yaml:",inline"`
+
+	// Response handling
+	ResponseShaping `yaml:",inline"`
+
+	// Scopes the user must hold for this provider to run (supports "orders:*" wildcards)
+	RequiredScopes []string `json:"required_scopes,omitempty" yaml:"required_scopes,omitempty"`
 }
 
 // Tool represents a tool definition in the manifest
@@ -54,6 +84,12 @@ type Tool struct {
 	Type        string          `json:"type" yaml:"type"` // "http", "internal"
 	Config      ToolConfig      `json:"config" yaml:"config"`
 	Parameters  []ToolParameter `json:"parameters" yaml:"parameters"`
+
+	// Simulated result returned instead of the real call in dry-run mode
+	MockResponse any `json:"mock_response,omitempty" yaml:"mock_response,omitempty"`
+
+	// Scopes the user must hold for this tool to be offered (supports "orders:*" wildcards)
+	RequiredScopes []string `json:"required_scopes,omitempty" yaml:"required_scopes,omitempty"`
 }
 
 // ToolConfig holds tool-specific configuration
@@ -65,13 +101,38 @@ type ToolConfig struct {
 	Body    any               `json:"body,omitempty" yaml:"body,omitempty"`
 	Timeout string            `json:"timeout,omitempty" yaml:"timeout,omitempty"`
 
+	// Query and body encoding
+	RequestEncoding `yaml:",inline"`
+
 	// Response handling
-	ResponsePath string `json:"response_path,omitempty" yaml:"response_path,omitempty"`
+	ResponseShaping `yaml:",inline"`
 
 	// Internal config (for future)
 	Operation string `json:"operation,omitempty" yaml:"operation,omitempty"`
 }
 
+// RequestEncoding controls how query parameters and the request body are sent
+type RequestEncoding struct {
+	Query    map[string]string `json:"query,omitempty" yaml:"query,omitempty"`         // Encoded query parameters (values support templates)
+	BodyType string            `json:"body_type,omitempty" yaml:"body_type,omitempty"` // json (default), form, multipart, text, xml
+	Files    []MultipartFile   `json:"files,omitempty" yaml:"files,omitempty"`         // Multipart file parts
+}
+
+// MultipartFile is a file part read from the configured file system
+type MultipartFile struct {
+	Field       string `json:"field" yaml:"field"`
+	Path        string `json:"path" yaml:"path"` // Supports templates, e.g. "uploads/{user.id}/{file_name}"
+	Filename    string `json:"filename,omitempty" yaml:"filename,omitempty"`
+	ContentType string `json:"content_type,omitempty" yaml:"content_type,omitempty"`
+}
+
+// ResponseShaping reduces a backend response before it reaches the model
+type ResponseShaping struct {
+	ResponsePath     string `json:"response_path,omitempty" yaml:"response_path,omitempty"`           // JMESPath, e.g. "data.items[*].id"
+	ResponseTemplate string `json:"response_template,omitempty" yaml:"response_template,omitempty"`   // Go text/template rendering compact text
+	MaxResponseBytes int    `json:"max_response_bytes,omitempty" yaml:"max_response_bytes,omitempty"` // Truncate larger responses (0 = unlimited)
+}
+
 // ToolParameter defines a tool parameter
 type ToolParameter struct {
 	Name        string   `json:"name" yaml:"name"`
@@ -82,6 +143,7 @@ type ToolParameter struct {
 	ContextPath string   `json:"context_path,omitempty" yaml:"context_path,omitempty"`
 	Enum        []string `json:"enum,omitempty" yaml:"enum,omitempty"`
 	Default     any      `json:"default,omitempty" yaml:"default,omitempty"`
+	Sensitive   bool     `json:"sensitive,omitempty" yaml:"sensitive,omitempty"` // Redacted in audit records
 }
 
 // Safety holds safety settings for the route
@@ -90,6 +152,7 @@ type Safety struct {
 	MaxCostPerQuery     float64  `json:"max_cost_per_query" yaml:"max_cost_per_query"`
 	PIIProtection       bool     `json:"pii_protection" yaml:"pii_protection"`
 	RateLimitPerUser    int      `json:"rate_limit_per_user" yaml:"rate_limit_per_user"`
+	DryRun              bool     `json:"dry_run,omitempty" yaml:"dry_run,omitempty"` // Simulate tool calls instead of sending them
 }
 
 // RouteMatch represents a matched route with extracted parameters
@@ -128,8 +191,9 @@ func (r *Registry) Load(manifest *Manifest) error {
 	r.manifest = manifest
 	r.routes = make([]routeEntry, 0, len(manifest.Routes))
 
-	// Compile all routes
+	// Compile all routes, resolving their LLM settings against the default
 	for i := range manifest.Routes {
+		manifest.Routes[i].LLM = manifest.LLM.Merge(manifest.Routes[i].LLM)
 		entry, err := r.compileRoute(&manifest.Routes[i])
 		if err != nil {
 			return fmt.Errorf("error compiling route %s: %w", manifest.Routes[i].Pattern, err)
@@ -138,6 +202,9 @@ func (r *Registry) Load(manifest *Manifest) error {
 	}
 
 	// Set fallback
+	if manifest.Fallback != nil {
+		manifest.Fallback.LLM = manifest.LLM.Merge(manifest.Fallback.LLM)
+	}
 	r.fallback = manifest.Fallback
 
 	return nil
@@ -360,6 +427,85 @@ func (r *Route) IsToolConfirmationRequired(toolName string) bool {
 	return false
 }
 
+// AllowedTools returns the tools the granted scopes are allowed to use
+func (r *Route) AllowedTools(granted []string) []Tool {
+	tools := make([]Tool, 0, len(r.Tools))
+	for _, tool := range r.Tools {
+		if tool.IsAllowed(granted) {
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
+// IsAllowed reports whether the granted scopes satisfy the tool's required scopes
+func (t *Tool) IsAllowed(granted []string) bool {
+	return HasRequiredScopes(granted, t.RequiredScopes)
+}
+
+// IsAllowed reports whether the granted scopes satisfy the provider's required scopes
+func (p *Provider) IsAllowed(granted []string) bool {
+	return HasRequiredScopes(granted, p.RequiredScopes)
+}
+
+// HasRequiredScopes checks that every required scope is granted
+// Granted scopes follow the IAM semantics: "*" and "resource:*" wildcards
+func HasRequiredScopes(granted, required []string) bool {
+	if len(required) == 0 {
+		return true
+	}
+	auth := kernel.AuthContext{Scopes: granted}
+	return auth.HasAllScopes(required...)
+}
+
+// RequestSpec converts the manifest configuration to an httpx request spec
+func (e RequestEncoding) RequestSpec(method, url string, headers map[string]string, body any) httpx.Spec {
+	files := make([]httpx.File, len(e.Files))
+	for i, file := range e.Files {
+		files[i] = httpx.File{
+			Field:       file.Field,
+			Path:        file.Path,
+			Filename:    file.Filename,
+			ContentType: file.ContentType,
+		}
+	}
+
+	return httpx.Spec{
+		Method:   method,
+		URL:      url,
+		Headers:  headers,
+		Query:    e.Query,
+		BodyType: httpx.BodyType(e.BodyType),
+		Body:     body,
+		Files:    files,
+	}
+}
+
+// Validate checks the body type and multipart configuration
+func (e RequestEncoding) Validate() error {
+	if !httpx.IsValidBodyType(httpx.BodyType(e.BodyType)) {
+		return fmt.Errorf("unsupported body_type %q", e.BodyType)
+	}
+	if len(e.Files) > 0 && e.BodyType != string(httpx.BodyMultipart) {
+		return fmt.Errorf("files require body_type multipart")
+	}
+	for _, file := range e.Files {
+		if file.Field == "" || file.Path == "" {
+			return fmt.Errorf("multipart files require field and path")
+		}
+	}
+	return nil
+}
+
+// ShapeOptions converts the manifest configuration to shaping options
+func (s ResponseShaping) ShapeOptions() shapex.Options {
+	return shapex.Options{
+		Path:     s.ResponsePath,
+		Template: s.ResponseTemplate,
+		MaxBytes: s.MaxResponseBytes,
+	}
+}
+
 func (r *Route) String() string {
 	return fmt.Sprintf("Route{name=%s, pattern=%s, tools=%d}", r.Name, r.Pattern, len(r.Tools))
 }
@@ -381,4 +527,3 @@ func (m *Manifest) ToJSON() ([]byte, error) {
 func (m *Manifest) ToYAML() ([]byte, error) {
 	return yaml.Marshal(m)
 }
-
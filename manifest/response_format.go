@@ -0,0 +1,108 @@
+// manifest/response_format.go
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Abraxas-365/ams/pkg/schemax"
+	"gopkg.in/yaml.v3"
+)
+
+// ResponseFormat asks the route to reply with JSON matching a schema
+// The schema is given inline or loaded from a JSON/YAML file relative to the manifest
+type ResponseFormat struct {
+	Name       string         `json:"name,omitempty" yaml:"name,omitempty"`
+	Schema     map[string]any `json:"schema,omitempty" yaml:"schema,omitempty"`
+	SchemaFile string         `json:"schema_file,omitempty" yaml:"schema_file,omitempty"`
+
+	fromFile bool // Schema was loaded from SchemaFile
+}
+
+// SchemaName returns the schema name sent to the model
+func (f *ResponseFormat) SchemaName(routeName string) string {
+	if f.Name != "" {
+		return f.Name
+	}
+	return routeName + "_response"
+}
+
+// Validate checks that exactly one schema source is set and that it is usable
+func (f *ResponseFormat) Validate() error {
+	if f == nil {
+		return nil
+	}
+	if f.Schema == nil && f.SchemaFile == "" {
+		return fmt.Errorf("response_format requires schema or schema_file")
+	}
+	if f.Schema != nil && f.SchemaFile != "" && !f.fromFile {
+		return fmt.Errorf("response_format cannot set both schema and schema_file")
+	}
+	if f.Schema == nil {
+		return fmt.Errorf("schema_file %s was not loaded", f.SchemaFile)
+	}
+
+	normalized, err := schemax.Normalize(f.Schema)
+	if err != nil {
+		return err
+	}
+	return schemax.Check(normalized)
+}
+
+// loadSchemaFile reads SchemaFile into Schema, resolving it against baseDir
+func (f *ResponseFormat) loadSchemaFile(baseDir string) error {
+	if f == nil || f.SchemaFile == "" || f.fromFile {
+		return nil
+	}
+	if f.Schema != nil {
+		return fmt.Errorf("response_format cannot set both schema and schema_file")
+	}
+
+	path := f.SchemaFile
+	if !filepath.IsAbs(path) && baseDir != "" {
+		path = filepath.Join(baseDir, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read schema_file %s: %w", f.SchemaFile, err)
+	}
+
+	var schema map[string]any
+	if strings.HasSuffix(path, ".json") {
+		err = json.Unmarshal(data, &schema)
+	} else {
+		err = yaml.Unmarshal(data, &schema)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse schema_file %s: %w", f.SchemaFile, err)
+	}
+
+	f.Schema = schema
+	f.fromFile = true
+	return nil
+}
+
+// JSONSchema returns the schema as plain JSON types, ready for the model and validation
+func (f *ResponseFormat) JSONSchema() (map[string]any, error) {
+	return schemax.Normalize(f.Schema)
+}
+
+// loadSchemaFiles loads every route's schema_file relative to baseDir
+func loadSchemaFiles(manifest *Manifest, baseDir string) error {
+	for i := range manifest.Routes {
+		route := &manifest.Routes[i]
+		if err := route.ResponseFormat.loadSchemaFile(baseDir); err != nil {
+			return NewValidationError(fmt.Sprintf("route %s: %v", route.Name, err))
+		}
+	}
+	if manifest.Fallback != nil {
+		if err := manifest.Fallback.ResponseFormat.loadSchemaFile(baseDir); err != nil {
+			return NewValidationError(fmt.Sprintf("fallback: %v", err))
+		}
+	}
+	return nil
+}
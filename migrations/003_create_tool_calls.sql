@@ -0,0 +1,27 @@
+-- migrations/003_create_tool_calls.sql
+
+-- Tool call audit trail
+CREATE TABLE IF NOT EXISTS tool_calls (
+    id BIGSERIAL PRIMARY KEY,
+    session_id VARCHAR(64) NOT NULL DEFAULT '',
+    user_id VARCHAR(255) NOT NULL DEFAULT '',
+    tenant_id VARCHAR(255) NOT NULL DEFAULT '',
+    route VARCHAR(255) NOT NULL DEFAULT '',
+    tool_name VARCHAR(255) NOT NULL,
+    arguments TEXT NOT NULL DEFAULT '',
+    http_status INTEGER NOT NULL DEFAULT 0,
+    latency_ms BIGINT NOT NULL DEFAULT 0,
+    result_size INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Indexes for audit queries
+CREATE INDEX idx_tool_calls_session_id ON tool_calls(session_id, created_at DESC);
+CREATE INDEX idx_tool_calls_user_id ON tool_calls(user_id, created_at DESC);
+CREATE INDEX idx_tool_calls_tool_name ON tool_calls(tool_name, created_at DESC);
+CREATE INDEX idx_tool_calls_created_at ON tool_calls(created_at);
+
+-- Add comment
+COMMENT ON TABLE tool_calls IS 'Audit trail of every tool executed by the assistant';
+COMMENT ON COLUMN tool_calls.arguments IS 'Resolved tool arguments as JSON with secrets redacted';
@@ -0,0 +1,7 @@
+-- migrations/004_add_session_message_metadata.sql
+
+-- Message metadata (e.g. interrupted runs)
+ALTER TABLE session_messages ADD COLUMN IF NOT EXISTS metadata TEXT NOT NULL DEFAULT '';
+
+-- Add comment
+COMMENT ON COLUMN session_messages.metadata IS 'Message metadata as JSON, e.g. {"interrupted": true} for cancelled runs';
@@ -0,0 +1,7 @@
+-- migrations/005_add_session_message_parts.sql
+
+-- Multimodal content parts (images, files) attached to a message
+ALTER TABLE session_messages ADD COLUMN IF NOT EXISTS parts TEXT NOT NULL DEFAULT '';
+
+-- Add comment
+COMMENT ON COLUMN session_messages.parts IS 'Content parts as JSON, e.g. [{"type": "image_url", "url": "..."}]; file parts keep their path instead of the data';
@@ -0,0 +1,9 @@
+-- migrations/006_add_session_summary.sql
+
+-- Running summary of older messages, kept so it isn't recomputed every turn
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS summary TEXT NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS summary_count INTEGER NOT NULL DEFAULT 0;
+
+-- Add comment
+COMMENT ON COLUMN sessions.summary IS 'LLM summary of the first summary_count non-system-prompt messages';
+COMMENT ON COLUMN sessions.summary_count IS 'Number of messages, after the leading system messages, covered by summary';
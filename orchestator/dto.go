@@ -1,17 +1,25 @@
 // orchestator/dto.go
 package orchestator
 
-import "github.com/Abraxas-365/ams/context"
+import (
+	"github.com/Abraxas-365/ams/context"
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/agentx"
+)
 
 // ChatRequest represents an incoming chat request from the frontend
 type ChatRequest struct {
 	Message        string                   `json:"message"`
+	Attachments    []Attachment             `json:"attachments,omitempty"` // Images and files sent with the message
 	Route          RouteInfo                `json:"route"`
 	Frontend       *context.FrontendContext `json:"frontend,omitempty"`
 	User           *context.User            `json:"user,omitempty"`
 	ConversationID string                   `json:"conversation_id,omitempty"`
 	SessionID      string                   `json:"session_id,omitempty"` // ✅ For session-based memory
 	StreamResponse bool                     `json:"stream_response,omitempty"`
+	DryRun         bool                     `json:"dry_run,omitempty"` // Simulate tool calls instead of sending them
+	RunID          string                   `json:"run_id,omitempty"`  // Optional client-chosen ID used to cancel the run
+	Debug          bool                     `json:"debug,omitempty"`   // Return the execution trace (requires the debug scope)
 
 	// 🔐 Authentication (user's token from frontend)
 	BearerToken   string            `json:"bearer_token,omitempty"`
@@ -22,6 +30,39 @@ type ChatRequest struct {
 	ShouldFetchContext bool              `json:"should_fetch_context"`   // Explicit flag to request fresh backend data
 }
 
+// Attachment is an image or file sent with the message
+type Attachment struct {
+	Type     string `json:"type"`                // image_url, image (base64 data) or file
+	URL      string `json:"url,omitempty"`       // image_url
+	Data     string `json:"data,omitempty"`      // Base64 content for image and file
+	MIMEType string `json:"mime_type,omitempty"` // e.g. image/png, application/pdf
+	Path     string `json:"path,omitempty"`      // File in the server's file system, instead of data
+	Filename string `json:"filename,omitempty"`
+	Detail   string `json:"detail,omitempty"` // Image detail: auto, low or high
+}
+
+// ContentPart converts the attachment to an LLM content part
+func (a Attachment) ContentPart() llm.ContentPart {
+	return llm.ContentPart{
+		Type:     a.Type,
+		URL:      a.URL,
+		Data:     a.Data,
+		MIMEType: a.MIMEType,
+		Path:     a.Path,
+		Filename: a.Filename,
+		Detail:   a.Detail,
+	}
+}
+
+// UserMessage builds the LLM user message for the request
+func (r ChatRequest) UserMessage() llm.Message {
+	parts := make([]llm.ContentPart, len(r.Attachments))
+	for i, attachment := range r.Attachments {
+		parts[i] = attachment.ContentPart()
+	}
+	return llm.NewUserMessageWithParts(r.Message, parts...)
+}
+
 // RouteInfo contains information about the current route
 type RouteInfo struct {
 	Path  string            `json:"path"`
@@ -32,9 +73,12 @@ type RouteInfo struct {
 type ChatResponse struct {
 	Response       string         `json:"response"`
 	SessionID      string         `json:"session_id,omitempty"` // ✅ Return session ID
+	RunID          string         `json:"run_id,omitempty"`
 	ConversationID string         `json:"conversation_id,omitempty"`
 	Usage          *UsageInfo     `json:"usage,omitempty"`
 	Metadata       map[string]any `json:"metadata,omitempty"`
+	Trace          *agentx.Trace  `json:"trace,omitempty"` // Set for debug requests
+	Data           any            `json:"data,omitempty"`  // Parsed reply for routes with a response_format
 }
 
 // UsageInfo contains token usage information
@@ -44,11 +88,50 @@ type UsageInfo struct {
 	TotalTokens      int `json:"total_tokens"`
 }
 
+// StreamEventType identifies the kind of streaming event
+type StreamEventType string
+
+const (
+	StreamEventRunStarted       StreamEventType = "run_started"
+	StreamEventToken            StreamEventType = "token"
+	StreamEventToolCallStarted  StreamEventType = "tool_call_started"
+	StreamEventToolCallFinished StreamEventType = "tool_call_finished"
+	StreamEventReplaced         StreamEventType = "replaced" // Content replaces the streamed reply (repaired structured output)
+	StreamEventUsage            StreamEventType = "usage"
+	StreamEventTrace            StreamEventType = "trace"
+	StreamEventError            StreamEventType = "error"
+	StreamEventDone             StreamEventType = "done"
+)
+
 // StreamChunk represents a chunk in streaming response
 type StreamChunk struct {
-	Content   string         `json:"content"`
-	Done      bool           `json:"done"`
-	SessionID string         `json:"session_id,omitempty"` // ✅ For streaming
-	Error     string         `json:"error,omitempty"`
-	Metadata  map[string]any `json:"metadata,omitempty"`
+	Type      StreamEventType `json:"type"`
+	Content   string          `json:"content,omitempty"`
+	Done      bool            `json:"done"`
+	SessionID string          `json:"session_id,omitempty"` // ✅ For streaming
+	RunID     string          `json:"run_id,omitempty"`     // run_started and done
+	Error     string          `json:"error,omitempty"`
+	ToolCall  *ToolCallInfo   `json:"tool_call,omitempty"` // tool_call_started / tool_call_finished
+	Usage     *UsageInfo      `json:"usage,omitempty"`     // usage
+	Trace     *agentx.Trace   `json:"trace,omitempty"`     // trace
+	Data      any             `json:"data,omitempty"`      // done (routes with a response_format)
+	Metadata  map[string]any  `json:"metadata,omitempty"`
+}
+
+// ToolCallInfo describes tool progress in a stream
+type ToolCallInfo struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Status     string `json:"status,omitempty"` // "success" or "error" once finished
+	DurationMs int64  `json:"duration_ms,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// NewErrorChunk creates a terminal error event
+func NewErrorChunk(err error) StreamChunk {
+	return StreamChunk{
+		Type:  StreamEventError,
+		Error: err.Error(),
+		Done:  true,
+	}
 }
@@ -26,6 +26,20 @@ var (
 		"Message is required",
 	)
 
+	ErrCodeInvalidAttachment = errRegistry.Register(
+		"INVALID_ATTACHMENT",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Invalid message attachment",
+	)
+
+	ErrCodeAttachmentNotAllowed = errRegistry.Register(
+		"ATTACHMENT_NOT_ALLOWED",
+		errx.TypeAuthorization,
+		http.StatusForbidden,
+		"File attachments by path require an additional scope",
+	)
+
 	ErrCodeMissingRoute = errRegistry.Register(
 		"MISSING_ROUTE",
 		errx.TypeValidation,
@@ -33,6 +47,13 @@ var (
 		"Route information is required",
 	)
 
+	ErrCodeDebugNotAllowed = errRegistry.Register(
+		"DEBUG_NOT_ALLOWED",
+		errx.TypeAuthorization,
+		http.StatusForbidden,
+		"Debug traces require an additional scope",
+	)
+
 	// Route errors
 	ErrCodeRouteNotFound = errRegistry.Register(
 		"ROUTE_NOT_FOUND",
@@ -71,6 +92,27 @@ var (
 		"Agent execution failed",
 	)
 
+	ErrCodeUnknownInterceptor = errRegistry.Register(
+		"UNKNOWN_INTERCEPTOR",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Route references an unregistered interceptor",
+	)
+
+	ErrCodeUnknownLLMProvider = errRegistry.Register(
+		"UNKNOWN_LLM_PROVIDER",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Route references an unconfigured LLM provider",
+	)
+
+	ErrCodeStructuredResponseInvalid = errRegistry.Register(
+		"STRUCTURED_RESPONSE_INVALID",
+		errx.TypeExternal,
+		http.StatusBadGateway,
+		"Assistant reply does not match the route response schema",
+	)
+
 	// Tool errors
 	ErrCodeToolLoadFailed = errRegistry.Register(
 		"TOOL_LOAD_FAILED",
@@ -79,6 +121,36 @@ var (
 		"Failed to load tools",
 	)
 
+	// Run errors
+	ErrCodeRunNotFound = errRegistry.Register(
+		"RUN_NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Run not found or already finished",
+	)
+
+	ErrCodeRunAlreadyActive = errRegistry.Register(
+		"RUN_ALREADY_ACTIVE",
+		errx.TypeConflict,
+		http.StatusConflict,
+		"A run with this ID is already active",
+	)
+
+	ErrCodeRunCancelled = errRegistry.Register(
+		"RUN_CANCELLED",
+		errx.TypeBusiness,
+		http.StatusConflict,
+		"Run was cancelled before completing",
+	)
+
+	// Trace errors
+	ErrCodeTraceNotFound = errRegistry.Register(
+		"TRACE_NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"No trace recorded for this message",
+	)
+
 	// Memory errors
 	ErrCodeMemoryInitFailed = errRegistry.Register(
 		"MEMORY_INIT_FAILED",
@@ -98,10 +170,26 @@ func NewMissingMessageError() *errx.Error {
 	return errRegistry.New(ErrCodeMissingMessage)
 }
 
+func NewInvalidAttachmentError(index int, cause error) *errx.Error {
+	return errRegistry.NewWithCause(ErrCodeInvalidAttachment, cause).
+		WithDetail("index", index)
+}
+
+func NewAttachmentNotAllowedError(index int, scope string) *errx.Error {
+	return errRegistry.New(ErrCodeAttachmentNotAllowed).
+		WithDetail("index", index).
+		WithDetail("required_scope", scope)
+}
+
 func NewMissingRouteError() *errx.Error {
 	return errRegistry.New(ErrCodeMissingRoute)
 }
 
+func NewDebugNotAllowedError(scope string) *errx.Error {
+	return errRegistry.New(ErrCodeDebugNotAllowed).
+		WithDetail("required_scope", scope)
+}
+
 func NewRouteNotFoundError(path string) *errx.Error {
 	return errRegistry.New(ErrCodeRouteNotFound).
 		WithDetail("path", path)
@@ -124,6 +212,23 @@ func NewAgentExecutionFailedError(cause error) *errx.Error {
 	return errRegistry.NewWithCause(ErrCodeAgentExecutionFailed, cause)
 }
 
+func NewUnknownInterceptorError(name, route string) *errx.Error {
+	return errRegistry.New(ErrCodeUnknownInterceptor).
+		WithDetail("interceptor", name).
+		WithDetail("route", route)
+}
+
+func NewUnknownLLMProviderError(name, route string) *errx.Error {
+	return errRegistry.New(ErrCodeUnknownLLMProvider).
+		WithDetail("provider", name).
+		WithDetail("route", route)
+}
+
+func NewStructuredResponseInvalidError(route string, cause error) *errx.Error {
+	return errRegistry.NewWithCause(ErrCodeStructuredResponseInvalid, cause).
+		WithDetail("route", route)
+}
+
 func NewToolLoadFailedError(cause error) *errx.Error {
 	return errRegistry.NewWithCause(ErrCodeToolLoadFailed, cause)
 }
@@ -131,3 +236,24 @@ func NewToolLoadFailedError(cause error) *errx.Error {
 func NewMemoryInitFailedError(cause error) *errx.Error {
 	return errRegistry.NewWithCause(ErrCodeMemoryInitFailed, cause)
 }
+
+func NewRunNotFoundError(runID string) *errx.Error {
+	return errRegistry.New(ErrCodeRunNotFound).
+		WithDetail("run_id", runID)
+}
+
+func NewRunAlreadyActiveError(runID string) *errx.Error {
+	return errRegistry.New(ErrCodeRunAlreadyActive).
+		WithDetail("run_id", runID)
+}
+
+func NewRunCancelledError(runID string) *errx.Error {
+	return errRegistry.New(ErrCodeRunCancelled).
+		WithDetail("run_id", runID)
+}
+
+func NewTraceNotFoundError(sessionID string, messageID int64) *errx.Error {
+	return errRegistry.New(ErrCodeTraceNotFound).
+		WithDetail("session_id", sessionID).
+		WithDetail("message_id", messageID)
+}
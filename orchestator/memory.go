@@ -1,13 +1,16 @@
 package orchestator
 
 import (
+	"context"
+
 	"github.com/Abraxas-365/ams/pkg/ai/llm"
 	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx"
 )
 
 // MemoryFactory creates memory instances for conversations
+// ctx is the request context; memories that call the LLM use it for those calls
 type MemoryFactory interface {
-	Create(systemMessage llm.Message) memoryx.Memory
+	Create(ctx context.Context, systemMessage llm.Message) memoryx.Memory
 }
 
 // BufferMemoryFactory creates buffer memory instances
@@ -30,7 +33,7 @@ func NewBufferMemoryFactoryWithLimit(maxMessages int) *BufferMemoryFactory {
 }
 
 // Create creates a new buffer memory with the given system message
-func (f *BufferMemoryFactory) Create(systemMessage llm.Message) memoryx.Memory {
+func (f *BufferMemoryFactory) Create(ctx context.Context, systemMessage llm.Message) memoryx.Memory {
 	if f.maxMessages > 0 {
 		return memoryx.NewBufferMemory(
 			systemMessage,
@@ -39,3 +42,59 @@ func (f *BufferMemoryFactory) Create(systemMessage llm.Message) memoryx.Memory {
 	}
 	return memoryx.NewBufferMemory(systemMessage)
 }
+
+// MemoryWrapper is implemented by factories that also decorate session memory,
+// so database-backed conversations get the same strategy as buffer ones
+type MemoryWrapper interface {
+	Wrap(ctx context.Context, memory memoryx.Memory) memoryx.Memory
+}
+
+// SummaryMemoryFactory creates memories that summarize older turns once the
+// history exceeds a token threshold
+type SummaryMemoryFactory struct {
+	client llm.LLM
+	opts   []memoryx.SummaryMemoryOption
+}
+
+// NewSummaryMemoryFactory creates a summary memory factory using client for summaries
+func NewSummaryMemoryFactory(client llm.LLM, opts ...memoryx.SummaryMemoryOption) *SummaryMemoryFactory {
+	return &SummaryMemoryFactory{
+		client: client,
+		opts:   opts,
+	}
+}
+
+// Create creates a summarizing buffer memory with the given system message
+func (f *SummaryMemoryFactory) Create(ctx context.Context, systemMessage llm.Message) memoryx.Memory {
+	return f.Wrap(ctx, memoryx.NewBufferMemory(systemMessage))
+}
+
+// Wrap adds summarization to an existing memory; session memories store the summary with the session
+func (f *SummaryMemoryFactory) Wrap(ctx context.Context, memory memoryx.Memory) memoryx.Memory {
+	return memoryx.NewSummaryMemory(ctx, memory, f.client, f.opts...)
+}
+
+// TokenWindowMemoryFactory creates memories that send only the most recent
+// messages within a token budget, keeping tool calls paired with their results
+type TokenWindowMemoryFactory struct {
+	maxTokens int
+	opts      []memoryx.TokenWindowOption
+}
+
+// NewTokenWindowMemoryFactory creates a token window memory factory
+func NewTokenWindowMemoryFactory(maxTokens int, opts ...memoryx.TokenWindowOption) *TokenWindowMemoryFactory {
+	return &TokenWindowMemoryFactory{
+		maxTokens: maxTokens,
+		opts:      opts,
+	}
+}
+
+// Create creates a token window buffer memory with the given system message
+func (f *TokenWindowMemoryFactory) Create(ctx context.Context, systemMessage llm.Message) memoryx.Memory {
+	return f.Wrap(ctx, memoryx.NewBufferMemory(systemMessage))
+}
+
+// Wrap limits an existing memory to the token window
+func (f *TokenWindowMemoryFactory) Wrap(ctx context.Context, memory memoryx.Memory) memoryx.Memory {
+	return memoryx.NewTokenWindowMemory(memory, f.maxTokens, f.opts...)
+}
@@ -0,0 +1,46 @@
+package orchestator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/llmtest"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/tokenizer"
+)
+
+func TestSummaryMemoryFactoryUsesRequestContext(t *testing.T) {
+	fake := llmtest.New()
+	fake.On().Reply("- summary")
+	factory := NewSummaryMemoryFactory(fake,
+		memoryx.WithSummaryThreshold(1),
+		memoryx.WithTokenCounter(tokenizer.NewCounter(func(text string) int { return len(strings.Fields(text)) }, tokenizer.Overhead{})),
+	)
+
+	// A cancelled request must not start summary calls
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	memory := factory.Create(ctx, llm.NewSystemMessage("be brief"))
+	for _, msg := range []llm.Message{
+		llm.NewUserMessage("first question"),
+		llm.NewAssistantMessage("first answer"),
+		llm.NewUserMessage("second question"),
+	} {
+		if err := memory.Add(msg); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	messages, err := memory.Messages()
+	if err != nil {
+		t.Fatalf("Messages: %v", err)
+	}
+	if fake.CallCount() != 0 {
+		t.Errorf("summary model called %d times with a cancelled request context", fake.CallCount())
+	}
+	if len(messages) != 4 {
+		t.Errorf("messages = %d, want the full history", len(messages))
+	}
+}
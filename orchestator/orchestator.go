@@ -4,14 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path"
 	"strings"
 
 	"github.com/Abraxas-365/ams/manifest"
 	"github.com/Abraxas-365/ams/pkg/ai/llm"
 	"github.com/Abraxas-365/ams/pkg/ai/llm/agentx"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/cachex"
 	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx"
 	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx/memorysrv"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/tokenizer"
 	"github.com/Abraxas-365/ams/pkg/ai/llm/toolx"
+	"github.com/Abraxas-365/ams/pkg/fsx"
 	"github.com/Abraxas-365/ams/pkg/logx"
 	"github.com/Abraxas-365/ams/tools"
 
@@ -21,39 +25,110 @@ import (
 // Orchestrator orchestrates the entire AI assistant flow
 type Orchestrator struct {
 	llmClient      llm.Client
+	llmProviders   map[string]llm.Client
+	responseCache  *cachex.Cache
 	contextBuilder *appcontext.Builder
 	manifestReg    *manifest.Registry
 	toolLoader     *tools.ToolLoader
 	memoryFactory  MemoryFactory
 	sessionService *memorysrv.SessionService
+	toolCallSrv    *memorysrv.ToolCallService
+	fileSystem     fsx.FileReader
+	runs           *runRegistry
+	interceptors   map[string]agentx.Interceptor
+	debugScope     string
+	attachScope    string
+	attachDir      string
 }
 
 // Config holds orchestrator configuration
 type Config struct {
 	LLMClient      llm.Client
+	LLMProviders   map[string]llm.Client // Named clients selected by `llm.provider` in the manifest
+	ResponseCache  *cachex.Cache         // Optional cache for routes that enable `cache`
 	ContextBuilder *appcontext.Builder
 	ManifestReg    *manifest.Registry
-	MemoryFactory  MemoryFactory             // For backward compatibility (buffer memory)
-	SessionService *memorysrv.SessionService // For session-based memory
+	MemoryFactory  MemoryFactory              // For backward compatibility (buffer memory)
+	SessionService *memorysrv.SessionService  // For session-based memory
+	ToolCallSrv    *memorysrv.ToolCallService // Optional tool call audit trail
+	FileSystem     fsx.FileReader             // Optional file source for multipart tool uploads and file attachments
+
+	// Named interceptors that routes enable via `interceptors` in the manifest
+	// "logging" is always available unless overridden
+	Interceptors map[string]agentx.Interceptor
+
+	// Scope required for debug traces (defaults to DefaultDebugScope)
+	DebugScope string
+
+	// Scope required to attach files by path (defaults to DefaultAttachmentScope)
+	// Paths are confined to the caller's folder, AttachmentDir/<user id>
+	AttachmentScope string
+	AttachmentDir   string // Defaults to DefaultAttachmentDir
 }
 
+// DefaultDebugScope is required to request execution traces
+const DefaultDebugScope = "assistant:debug"
+
+// DefaultAttachmentScope is required to attach files from the file system by path
+const DefaultAttachmentScope = "files:read"
+
+// DefaultAttachmentDir holds one folder per user for path attachments
+const DefaultAttachmentDir = "uploads"
+
 // NewOrchestrator creates a new orchestrator
 func NewOrchestrator(config Config) *Orchestrator {
+	loaderOpts := make([]tools.ToolLoaderOption, 0)
+	if config.ToolCallSrv != nil {
+		loaderOpts = append(loaderOpts, tools.WithCallRecorder(config.ToolCallSrv))
+	}
+	if config.FileSystem != nil {
+		loaderOpts = append(loaderOpts, tools.WithToolFileSystem(config.FileSystem))
+	}
+
+	debugScope := config.DebugScope
+	if debugScope == "" {
+		debugScope = DefaultDebugScope
+	}
+	attachScope := config.AttachmentScope
+	if attachScope == "" {
+		attachScope = DefaultAttachmentScope
+	}
+	attachDir := config.AttachmentDir
+	if attachDir == "" {
+		attachDir = DefaultAttachmentDir
+	}
+
+	interceptors := map[string]agentx.Interceptor{
+		"logging": agentx.NewLoggingInterceptor(),
+	}
+	for name, interceptor := range config.Interceptors {
+		interceptors[name] = interceptor
+	}
+
 	return &Orchestrator{
 		llmClient:      config.LLMClient,
+		llmProviders:   config.LLMProviders,
+		responseCache:  config.ResponseCache,
 		contextBuilder: config.ContextBuilder,
 		manifestReg:    config.ManifestReg,
-		toolLoader:     tools.NewToolLoader(),
+		toolLoader:     tools.NewToolLoader(loaderOpts...),
 		memoryFactory:  config.MemoryFactory,
 		sessionService: config.SessionService,
+		toolCallSrv:    config.ToolCallSrv,
+		fileSystem:     config.FileSystem,
+		runs:           newRunRegistry(),
+		interceptors:   interceptors,
+		debugScope:     debugScope,
+		attachScope:    attachScope,
+		attachDir:      attachDir,
 	}
 }
 
 // HandleChat processes a chat request and returns a response
-// HandleChat processes a chat request and returns a response
-func (o *Orchestrator) HandleChat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+// Scopes are the caller's verified scopes, never taken from the request
+func (o *Orchestrator) HandleChat(ctx context.Context, req ChatRequest, scopes []string) (*ChatResponse, error) {
 	// 1. Validate request
-	if err := o.validateRequest(req); err != nil {
+	if err := o.validateRequest(req, scopes); err != nil {
 		return nil, err
 	}
 
@@ -135,8 +210,15 @@ func (o *Orchestrator) HandleChat(ctx context.Context, req ChatRequest) (*ChatRe
 			return nil, NewContextBuildFailedError(err)
 		}
 	}
+	o.omitUnsupportedTools(fullContext, routeMatch.Route)
 
-	// 6. Get or create memory (session-based or buffer)
+	// 6. Debug traces are only available to users with the debug scope
+	tracer, err := o.newTracer(req, scopes, routeMatch.Route)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get or create memory (session-based or buffer)
 	memory, sessionID, err := o.getOrCreateMemory(ctx, req, fullContext, routeMatch)
 	if err != nil {
 		return nil, err
@@ -162,57 +244,87 @@ func (o *Orchestrator) HandleChat(ctx context.Context, req ChatRequest) (*ChatRe
 		}
 	}
 
-	// 8. Create agent with tools
-	agent, err := o.createAgentWithMemory(ctx, memory, fullContext, routeMatch, req.BearerToken)
+	// 8. Register a cancellable run (memory keeps the request context so
+	// interrupted output can still be saved after cancellation)
+	runID := req.RunID
+	if runID == "" {
+		runID = NewRunID()
+	}
+	runCtx, finishRun, err := o.runs.start(ctx, runID, sessionID, runOwner(req))
 	if err != nil {
 		return nil, err
 	}
+	defer finishRun()
 
-	// 9. Run agent
-	response, err := agent.Run(ctx, req.Message)
+	// 9. Create agent with tools (route default can't be disabled by the request)
+	dryRun := req.DryRun || routeMatch.Route.Safety.DryRun
+	agent, err := o.createAgentWithMemory(runCtx, memory, fullContext, routeMatch, req.BearerToken, sessionID, dryRun, tracerInterceptors(tracer)...)
 	if err != nil {
+		return nil, err
+	}
+
+	// 10. Run agent
+	response, err := agent.RunMessage(runCtx, req.UserMessage())
+	if err != nil {
+		if runCtx.Err() != nil {
+			return nil, NewRunCancelledError(runID)
+		}
 		return nil, NewAgentExecutionFailedError(err)
 	}
 
-	// 10. Get usage information
+	// 11. Validate structured replies
+	var data any
+	if routeMatch.Route.ResponseFormat != nil {
+		result, err := o.resolveStructuredResponse(runCtx, agent, routeMatch.Route, response)
+		if err != nil {
+			return nil, err
+		}
+		data = result.Data
+		response = result.Content
+	}
+
+	// 12. Get usage information
 	messages, _ := agent.Messages()
-	usage := o.calculateUsage(messages)
+	usage := o.calculateUsage(routeMatch.Route, messages)
 
-	return &ChatResponse{
+	chatResponse := &ChatResponse{
 		Response:       response,
 		SessionID:      sessionID,
+		RunID:          runID,
 		ConversationID: req.ConversationID,
 		Usage:          usage,
+		Data:           data,
 		Metadata: map[string]any{
 			"route":            routeMatch.Route.Name,
 			"tools_count":      len(routeMatch.Route.Tools),
 			"context_injected": contextInjected,
+			"dry_run":          dryRun,
 		},
-	}, nil
+	}
+	if tracer != nil {
+		chatResponse.Trace = tracer.Trace()
+	}
+
+	return chatResponse, nil
 }
 
 // HandleChatStream processes a chat request with streaming
 func (o *Orchestrator) HandleChatStream(
 	ctx context.Context,
 	req ChatRequest,
+	scopes []string,
 	streamHandler func(chunk StreamChunk),
 ) error {
 	// 1. Validate request
-	if err := o.validateRequest(req); err != nil {
-		streamHandler(StreamChunk{
-			Error: err.Error(),
-			Done:  true,
-		})
+	if err := o.validateRequest(req, scopes); err != nil {
+		streamHandler(NewErrorChunk(err))
 		return err
 	}
 
 	// 2. Match route
 	routeMatch, err := o.matchRoute(req.Route.Path, req.Route.Query)
 	if err != nil {
-		streamHandler(StreamChunk{
-			Error: err.Error(),
-			Done:  true,
-		})
+		streamHandler(NewErrorChunk(err))
 		return err
 	}
 
@@ -240,10 +352,7 @@ func (o *Orchestrator) HandleChatStream(
 		fullContext, err = o.contextBuilder.Build(ctx, routeMatch, req.Frontend, user)
 		if err != nil {
 			err = NewContextBuildFailedError(err)
-			streamHandler(StreamChunk{
-				Error: err.Error(),
-				Done:  true,
-			})
+			streamHandler(NewErrorChunk(err))
 			return err
 		}
 
@@ -256,21 +365,23 @@ func (o *Orchestrator) HandleChatStream(
 		fullContext, err = o.contextBuilder.BuildMinimal(routeMatch, user)
 		if err != nil {
 			err = NewContextBuildFailedError(err)
-			streamHandler(StreamChunk{
-				Error: err.Error(),
-				Done:  true,
-			})
+			streamHandler(NewErrorChunk(err))
 			return err
 		}
 	}
+	o.omitUnsupportedTools(fullContext, routeMatch.Route)
 
-	// 6. Get or create memory (session-based or buffer)
+	// 6. Debug traces are only available to users with the debug scope
+	tracer, err := o.newTracer(req, scopes, routeMatch.Route)
+	if err != nil {
+		streamHandler(NewErrorChunk(err))
+		return err
+	}
+
+	// Get or create memory (session-based or buffer)
 	memory, sessionID, err := o.getOrCreateMemory(ctx, req, fullContext, routeMatch)
 	if err != nil {
-		streamHandler(StreamChunk{
-			Error: err.Error(),
-			Done:  true,
-		})
+		streamHandler(NewErrorChunk(err))
 		return err
 	}
 
@@ -290,45 +401,127 @@ func (o *Orchestrator) HandleChatStream(
 		}
 	}
 
-	// 8. Create agent with tools
-	agent, err := o.createAgentWithMemory(ctx, memory, fullContext, routeMatch, req.BearerToken)
+	// 8. Register a cancellable run and announce its ID
+	runID := req.RunID
+	if runID == "" {
+		runID = NewRunID()
+	}
+	runCtx, finishRun, err := o.runs.start(ctx, runID, sessionID, runOwner(req))
 	if err != nil {
-		streamHandler(StreamChunk{
-			Error: err.Error(),
-			Done:  true,
-		})
+		streamHandler(NewErrorChunk(err))
 		return err
 	}
+	defer finishRun()
 
-	// 9. Stream agent response
-	err = agent.StreamWithTools(ctx, req.Message, func(chunk string) {
-		streamHandler(StreamChunk{
-			Content: chunk,
-			Done:    false,
-		})
+	streamHandler(StreamChunk{
+		Type:      StreamEventRunStarted,
+		RunID:     runID,
+		SessionID: sessionID,
+	})
+
+	// 9. Create agent with tools (route default can't be disabled by the request)
+	dryRun := req.DryRun || routeMatch.Route.Safety.DryRun
+	agent, err := o.createAgentWithMemory(runCtx, memory, fullContext, routeMatch, req.BearerToken, sessionID, dryRun, tracerInterceptors(tracer)...)
+	if err != nil {
+		streamHandler(NewErrorChunk(err))
+		return err
+	}
+
+	// 10. Stream agent response
+	err = agent.StreamMessageWithTools(runCtx, req.UserMessage(), func(event agentx.StreamEvent) {
+		streamHandler(newStreamChunk(event))
 	})
 
+	interrupted := false
 	if err != nil {
+		if runCtx.Err() == nil {
+			streamHandler(NewErrorChunk(err))
+			return NewAgentExecutionFailedError(err)
+		}
+		// Cancelled runs finish normally so partial output is acknowledged
+		interrupted = true
+		logx.WithFields(logx.Fields{
+			"run_id":     runID,
+			"session_id": sessionID,
+		}).Info("Streaming run interrupted")
+	}
+
+	// Structured replies are validated once the stream completes
+	var data any
+	if routeMatch.Route.ResponseFormat != nil && !interrupted {
+		result, err := o.resolveStructuredResponse(runCtx, agent, routeMatch.Route, lastAssistantContent(agent))
+		if err != nil {
+			streamHandler(NewErrorChunk(err))
+			return err
+		}
+		data = result.Data
+
+		// The invalid reply was already streamed as tokens
+		if result.Repaired {
+			streamHandler(StreamChunk{
+				Type:    StreamEventReplaced,
+				Content: result.Content,
+			})
+		}
+	}
+
+	// Send usage and final chunk
+	messages, _ := agent.Messages()
+	streamHandler(StreamChunk{
+		Type:  StreamEventUsage,
+		Usage: o.calculateUsage(routeMatch.Route, messages),
+	})
+
+	if tracer != nil {
 		streamHandler(StreamChunk{
-			Error: err.Error(),
-			Done:  true,
+			Type:  StreamEventTrace,
+			Trace: tracer.Trace(),
 		})
-		return NewAgentExecutionFailedError(err)
 	}
 
-	// Send final chunk
 	streamHandler(StreamChunk{
+		Type:      StreamEventDone,
 		Done:      true,
 		SessionID: sessionID,
+		RunID:     runID,
+		Data:      data,
 		Metadata: map[string]any{
 			"route":            routeMatch.Route.Name,
 			"context_injected": contextInjected,
+			"dry_run":          dryRun,
+			"interrupted":      interrupted,
 		},
 	})
 
 	return nil
 }
 
+// newStreamChunk converts an agent stream event to an API stream chunk
+func newStreamChunk(event agentx.StreamEvent) StreamChunk {
+	chunk := StreamChunk{Content: event.Content}
+
+	switch event.Type {
+	case agentx.EventToolCallStarted:
+		chunk.Type = StreamEventToolCallStarted
+	case agentx.EventToolCallFinished:
+		chunk.Type = StreamEventToolCallFinished
+	default:
+		chunk.Type = StreamEventToken
+	}
+
+	if event.ToolCall != nil {
+		chunk.ToolCall = &ToolCallInfo{
+			ID:         event.ToolCall.ID,
+			Name:       event.ToolCall.Name,
+			Status:     event.ToolCall.Status,
+			DurationMs: event.ToolCall.Duration.Milliseconds(),
+			Error:      event.ToolCall.Error,
+		}
+	}
+
+	return chunk
+}
+
 // ✅ NEW: createContextInjectionMessage creates a system message with fresh backend data
 func (o *Orchestrator) createContextInjectionMessage(fullContext *appcontext.FullContext) llm.Message {
 	var sb strings.Builder
@@ -377,7 +570,7 @@ func (o *Orchestrator) getOrCreateMemory(
 			return nil, "", err
 		}
 
-		return memory, string(sessionID), nil
+		return o.wrapSessionMemory(ctx, memory), string(sessionID), nil
 	}
 
 	// If session service is available but no session ID, create new session
@@ -422,7 +615,7 @@ func (o *Orchestrator) getOrCreateMemory(
 			return nil, "", err
 		}
 
-		return memory, string(session.ID), nil
+		return o.wrapSessionMemory(ctx, memory), string(session.ID), nil
 	}
 
 	// Fallback to buffer memory (backward compatibility)
@@ -433,16 +626,39 @@ func (o *Orchestrator) getOrCreateMemory(
 		o.memoryFactory = NewBufferMemoryFactory()
 	}
 
-	memory := o.memoryFactory.Create(fullContext.ToSystemMessage())
+	memory := o.memoryFactory.Create(ctx, fullContext.ToSystemMessage())
 	return memory, "", nil
 }
 
+// wrapSessionMemory applies the memory factory's strategy to session memory
+func (o *Orchestrator) wrapSessionMemory(ctx context.Context, memory memoryx.Memory) memoryx.Memory {
+	if wrapper, ok := o.memoryFactory.(MemoryWrapper); ok {
+		return wrapper.Wrap(ctx, memory)
+	}
+	return memory
+}
+
 // validateRequest validates the incoming request
-func (o *Orchestrator) validateRequest(req ChatRequest) error {
-	if req.Message == "" {
+func (o *Orchestrator) validateRequest(req ChatRequest, scopes []string) error {
+	if req.Message == "" && len(req.Attachments) == 0 {
 		return NewMissingMessageError()
 	}
 
+	for i, attachment := range req.Attachments {
+		part := attachment.ContentPart()
+		if part.Type == llm.PartText {
+			return NewInvalidAttachmentError(i, fmt.Errorf("text belongs in the message"))
+		}
+		if err := part.Validate(); err != nil {
+			return NewInvalidAttachmentError(i, err)
+		}
+		if part.Path != "" {
+			if err := o.validateAttachmentPath(i, part.Path, req.User, scopes); err != nil {
+				return err
+			}
+		}
+	}
+
 	if req.Route.Path == "" {
 		return NewMissingRouteError()
 	}
@@ -450,6 +666,30 @@ func (o *Orchestrator) validateRequest(req ChatRequest) error {
 	return nil
 }
 
+// validateAttachmentPath confines path attachments to the caller's own folder
+// Anonymous callers and callers without the attachment scope can only send inline data
+func (o *Orchestrator) validateAttachmentPath(index int, filePath string, user *appcontext.User, scopes []string) error {
+	if o.fileSystem == nil {
+		return NewInvalidAttachmentError(index, fmt.Errorf("file attachments by path are not enabled"))
+	}
+	if !manifest.HasRequiredScopes(scopes, []string{o.attachScope}) {
+		return NewAttachmentNotAllowedError(index, o.attachScope)
+	}
+	if !fsx.IsRelativePath(filePath) {
+		return NewInvalidAttachmentError(index, fmt.Errorf("attachment path must be relative to the file root"))
+	}
+
+	// Scopes only come from a verified token, so the user ID is verified too
+	if user == nil || user.ID == "" || user.ID == "." || user.ID == ".." || strings.ContainsAny(user.ID, `/\`) {
+		return NewAttachmentNotAllowedError(index, o.attachScope)
+	}
+	userDir := path.Join(o.attachDir, user.ID) + "/"
+	if !strings.HasPrefix(path.Clean(strings.ReplaceAll(filePath, "\\", "/")), userDir) {
+		return NewInvalidAttachmentError(index, fmt.Errorf("attachment path must be inside %s", userDir))
+	}
+	return nil
+}
+
 // matchRoute matches the route and returns the route match
 func (o *Orchestrator) matchRoute(path string, query map[string]string) (*manifest.RouteMatch, error) {
 	match, err := o.manifestReg.GetRouteContext(path, query)
@@ -474,12 +714,12 @@ func (o *Orchestrator) createAgent(
 	// Create memory with context as system message
 	var memory memoryx.Memory
 	if o.memoryFactory != nil {
-		memory = o.memoryFactory.Create(fullContext.ToSystemMessage())
+		memory = o.memoryFactory.Create(ctx, fullContext.ToSystemMessage())
 	} else {
 		memory = memoryx.NewBufferMemory(fullContext.ToSystemMessage())
 	}
 
-	return o.createAgentWithMemory(ctx, memory, fullContext, routeMatch, userToken)
+	return o.createAgentWithMemory(ctx, memory, fullContext, routeMatch, userToken, "", routeMatch.Route.Safety.DryRun)
 }
 
 // createAgentWithMemory creates an agent with provided memory
@@ -489,18 +729,39 @@ func (o *Orchestrator) createAgentWithMemory(
 	fullContext *appcontext.FullContext,
 	routeMatch *manifest.RouteMatch,
 	userToken string,
+	sessionID string,
+	dryRun bool,
+	extraInterceptors ...agentx.Interceptor,
 ) (*agentx.Agent, error) {
 	// 1. Build workflow context for tools
-	workflowContext := o.buildWorkflowContext(fullContext, routeMatch)
+	workflowContext := o.buildWorkflowContext(fullContext, routeMatch, sessionID)
 
 	// 2. Load tools from manifest
-	manifestTools, err := o.toolLoader.LoadFromRoute(
-		routeMatch.Route,
-		workflowContext,
-		userToken,
-	)
-	if err != nil {
-		return nil, NewToolLoadFailedError(err)
+	toolOpts := make([]tools.HTTPToolOption, 0)
+	if dryRun {
+		toolOpts = append(toolOpts, tools.WithDryRun())
+		logx.WithField("route_name", routeMatch.Route.Name).Info("🧪 Dry run enabled, tool calls will be simulated")
+	}
+
+	// Providers without function calling get no tools at all
+	var manifestTools []toolx.Toolx
+	if o.providerSupportsTools(routeMatch.Route) {
+		loaded, err := o.toolLoader.LoadFromRoute(
+			routeMatch.Route,
+			workflowContext,
+			userToken,
+			fullContext.User.GrantedScopes(),
+			toolOpts...,
+		)
+		if err != nil {
+			return nil, NewToolLoadFailedError(err)
+		}
+		manifestTools = loaded
+	} else if len(routeMatch.Route.Tools) > 0 {
+		logx.WithFields(logx.Fields{
+			"route_name": routeMatch.Route.Name,
+			"provider":   routeMatch.Route.LLM.Provider,
+		}).Warn("⚠️ Provider lacks function calling, tools will not be offered")
 	}
 
 	// 3. Create tool registry
@@ -512,27 +773,165 @@ func (o *Orchestrator) createAgentWithMemory(
 		toolRegistry = toolx.FromToolx()
 	}
 
-	// 4. Create agent options
+	// 4. Create agent options from the route's LLM settings
+	settings := routeMatch.Route.LLM
+	llmOptions, err := o.routeLLMOptions(routeMatch.Route)
+	if err != nil {
+		return nil, NewAgentCreationFailedError(err)
+	}
+
 	options := []agentx.AgentOption{
 		agentx.WithTools(toolRegistry),
-		agentx.WithOptions(
-			llm.WithTemperature(1),
-		),
+		agentx.WithOptions(llmOptions...),
+	}
+	if settings != nil && settings.MaxAutoIterations > 0 {
+		options = append(options, agentx.WithMaxAutoIterations(settings.MaxAutoIterations))
+	}
+	if settings != nil && settings.MaxTotalIterations > 0 {
+		options = append(options, agentx.WithMaxTotalIterations(settings.MaxTotalIterations))
+	}
+
+	// 5. Add route interceptors, then request-scoped ones (e.g. tracing)
+	if len(routeMatch.Route.Interceptors) > 0 {
+		interceptors, err := o.routeInterceptors(routeMatch.Route)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, agentx.WithInterceptors(interceptors...))
+	}
+	if len(extraInterceptors) > 0 {
+		options = append(options, agentx.WithInterceptors(extraInterceptors...))
 	}
 
-	// 5. Create and return agent
-	agent := agentx.New(o.llmClient, memory, options...)
+	// 6. Create and return agent on the route's provider
+	client, err := o.routeLLMClient(routeMatch.Route)
+	if err != nil {
+		return nil, err
+	}
+	agent := agentx.New(client, memory, options...)
 
 	return agent, nil
 }
 
+// routeLLMClient returns the client for the route's provider (the default client when unset)
+// Routes that enable caching get the client wrapped by the response cache
+func (o *Orchestrator) routeLLMClient(route *manifest.Route) (llm.Client, error) {
+	client, provider := o.llmClient, "default"
+	if route.LLM != nil && route.LLM.Provider != "" {
+		provider = route.LLM.Provider
+		named, ok := o.llmProviders[provider]
+		if !ok {
+			return llm.Client{}, NewUnknownLLMProviderError(provider, route.Name)
+		}
+		client = named
+	}
+
+	if route.Cache.IsEnabled() && o.responseCache != nil {
+		backend := client // Wrap a copy, client is overwritten below
+		client = *llm.NewClient(o.responseCache.Wrap(&backend, provider, route.Cache.TTLDuration()))
+	}
+
+	// File references stay as paths in memory and are read just before each call
+	// (outside the cache, so entries are keyed on file contents)
+	if o.fileSystem != nil {
+		backend := client
+		client = *llm.NewClient(llm.ResolveFiles(&backend, o.fileSystem))
+	}
+	return client, nil
+}
+
+// providerSupportsTools reports whether the route's provider declares function calling
+func (o *Orchestrator) providerSupportsTools(route *manifest.Route) bool {
+	if route.LLM == nil || route.LLM.Provider == "" {
+		return true
+	}
+	provider := o.manifestReg.GetManifest().GetLLMProvider(route.LLM.Provider)
+	return provider == nil || provider.Capabilities.ToLLM().Tools
+}
+
+// omitUnsupportedTools drops the tool listing from the context when the
+// route's provider can't call them
+func (o *Orchestrator) omitUnsupportedTools(fullContext *appcontext.FullContext, route *manifest.Route) {
+	if !o.providerSupportsTools(route) {
+		fullContext.AvailableTools = nil
+	}
+}
+
+// routeLLMOptions returns the model options for a route
+func (o *Orchestrator) routeLLMOptions(route *manifest.Route) ([]llm.Option, error) {
+	// Temperature is left to the provider unless the route or manifest sets it
+	options := route.LLM.Options()
+
+	if route.ResponseFormat != nil {
+		formatOption, err := responseFormatOption(route)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, formatOption)
+	}
+
+	return options, nil
+}
+
+// newTracer returns a tracer for debug requests, or nil when debug is off
+// Tool arguments are redacted like the audit trail
+func (o *Orchestrator) newTracer(req ChatRequest, scopes []string, route *manifest.Route) (*agentx.Tracer, error) {
+	if !req.Debug {
+		return nil, nil
+	}
+
+	// Client-supplied user permissions don't count
+	if !manifest.HasRequiredScopes(scopes, []string{o.debugScope}) {
+		return nil, NewDebugNotAllowedError(o.debugScope)
+	}
+
+	redact := func(toolName, arguments string) string {
+		for _, tool := range route.Tools {
+			if tool.Name == toolName {
+				return tools.RedactArguments(tool, arguments)
+			}
+		}
+		return tools.RedactArguments(manifest.Tool{Name: toolName}, arguments)
+	}
+
+	logx.WithField("route_name", route.Name).Info("🔍 Debug trace enabled")
+	return agentx.NewTracer(agentx.DefaultTraceResultBytes, agentx.WithArgumentRedactor(redact)), nil
+}
+
+// tracerInterceptors wraps an optional tracer as an interceptor list
+func tracerInterceptors(tracer *agentx.Tracer) []agentx.Interceptor {
+	if tracer == nil {
+		return nil
+	}
+	return []agentx.Interceptor{tracer}
+}
+
+// routeInterceptors resolves the interceptors enabled by a route
+func (o *Orchestrator) routeInterceptors(route *manifest.Route) ([]agentx.Interceptor, error) {
+	interceptors := make([]agentx.Interceptor, 0, len(route.Interceptors))
+	for _, name := range route.Interceptors {
+		interceptor, ok := o.interceptors[name]
+		if !ok {
+			return nil, NewUnknownInterceptorError(name, route.Name)
+		}
+		interceptors = append(interceptors, interceptor)
+	}
+	return interceptors, nil
+}
+
 // buildWorkflowContext creates the workflow context for tools
 func (o *Orchestrator) buildWorkflowContext(
 	fullContext *appcontext.FullContext,
 	routeMatch *manifest.RouteMatch,
+	sessionID string,
 ) map[string]any {
 	workflowContext := make(map[string]any)
 
+	// Add session (used to correlate tool call audit records)
+	if sessionID != "" {
+		workflowContext["session_id"] = sessionID
+	}
+
 	// Add route params (e.g., {id} from /products/:id)
 	for key, value := range routeMatch.Params {
 		workflowContext[key] = value
@@ -546,10 +945,11 @@ func (o *Orchestrator) buildWorkflowContext(
 	// Add user context
 	if fullContext.User != nil {
 		workflowContext["user"] = map[string]any{
-			"id":    fullContext.User.ID,
-			"email": fullContext.User.Email,
-			"name":  fullContext.User.Name,
-			"token": fullContext.User.Token,
+			"id":        fullContext.User.ID,
+			"tenant_id": fullContext.User.TenantID,
+			"email":     fullContext.User.Email,
+			"name":      fullContext.User.Name,
+			"token":     fullContext.User.Token,
 		}
 	}
 
@@ -572,19 +972,26 @@ func (o *Orchestrator) buildWorkflowContext(
 	return workflowContext
 }
 
-// calculateUsage calculates token usage from messages
-func (o *Orchestrator) calculateUsage(messages []llm.Message) *UsageInfo {
-	// Simple estimation - in production you'd track this from actual API responses
-	totalTokens := 0
-	for _, msg := range messages {
-		// Rough estimate: 1 token ≈ 4 characters
-		totalTokens += len(msg.Content) / 4
+// calculateUsage counts tokens for the conversation: the final reply is the
+// completion, everything before it the prompt
+func (o *Orchestrator) calculateUsage(route *manifest.Route, messages []llm.Message) *UsageInfo {
+	model := ""
+	if route.LLM != nil {
+		model = route.LLM.Model
+	}
+	counter := tokenizer.ForModel(model)
+
+	prompt, completion := messages, ""
+	if n := len(messages); n > 0 && messages[n-1].Role == llm.RoleAssistant {
+		prompt, completion = messages[:n-1], messages[n-1].Content
 	}
 
+	promptTokens := counter.CountMessages(prompt, nil)
+	completionTokens := counter.CountText(completion)
 	return &UsageInfo{
-		PromptTokens:     totalTokens / 2,
-		CompletionTokens: totalTokens / 2,
-		TotalTokens:      totalTokens,
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      promptTokens + completionTokens,
 	}
 }
 
@@ -615,7 +1022,7 @@ func (o *Orchestrator) Health(ctx context.Context) error {
 	}
 
 	// Check if LLM client is available
-	if o.llmClient == (llm.Client{}) {
+	if o.llmClient == (llm.Client{}) && len(o.llmProviders) == 0 {
 		return fmt.Errorf("LLM client not initialized")
 	}
 
@@ -626,10 +1033,24 @@ func (o *Orchestrator) Health(ctx context.Context) error {
 func (o *Orchestrator) Stats() map[string]any {
 	manifestStats := o.manifestReg.Stats()
 
-	return map[string]any{
-		"manifest": manifestStats,
-		"healthy":  o.Health(context.Background()) == nil,
+	stats := map[string]any{
+		"manifest":    manifestStats,
+		"active_runs": o.runs.count(),
+		"healthy":     o.Health(context.Background()) == nil,
+	}
+	if o.responseCache != nil {
+		stats["response_cache"] = o.responseCache.Stats()
+	}
+	return stats
+}
+
+// CancelRun cancels an in-flight chat run started by userID
+// Partial assistant output is saved to the session marked as interrupted
+func (o *Orchestrator) CancelRun(runID, userID string) error {
+	if !o.runs.cancel(runID, userID) {
+		return NewRunNotFoundError(runID)
 	}
+	return nil
 }
 
 // ============================================================================
@@ -699,6 +1120,7 @@ func (o *Orchestrator) CreateSessionWithContext(
 
 		logx.Info("⚠️ Session created without backend data (backend will be fetched on first chat message)")
 	}
+	o.omitUnsupportedTools(fullContext, routeMatch.Route)
 
 	// Create session with system message
 	session, err := o.sessionService.CreateSession(
@@ -756,10 +1178,90 @@ func (o *Orchestrator) GetSession(ctx context.Context, sessionID string) (*memor
 }
 
 // GetSessionWithMessages gets a session with all messages
-func (o *Orchestrator) GetSessionWithMessages(ctx context.Context, sessionID string) (*memoryx.SessionWithMessages, error) {
+// Debug traces are removed from message metadata unless scopes include the debug scope
+func (o *Orchestrator) GetSessionWithMessages(ctx context.Context, sessionID string, scopes []string) (*memoryx.SessionWithMessages, error) {
 	if o.sessionService == nil {
 		return nil, fmt.Errorf("session service not configured")
 	}
 
-	return o.sessionService.GetSessionWithMessages(ctx, memoryx.SessionID(sessionID))
+	sessionWithMessages, err := o.sessionService.GetSessionWithMessages(ctx, memoryx.SessionID(sessionID))
+	if err != nil {
+		return nil, err
+	}
+
+	if !manifest.HasRequiredScopes(scopes, []string{o.debugScope}) {
+		for i := range sessionWithMessages.Messages {
+			sessionWithMessages.Messages[i].Metadata = withoutTrace(sessionWithMessages.Messages[i].Metadata)
+		}
+	}
+	return sessionWithMessages, nil
+}
+
+// withoutTrace removes the debug trace from serialized message metadata
+func withoutTrace(metadata string) string {
+	if metadata == "" {
+		return ""
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(metadata), &fields); err != nil {
+		return ""
+	}
+	if _, ok := fields[agentx.MetadataTrace]; !ok {
+		return metadata
+	}
+
+	delete(fields, agentx.MetadataTrace)
+	if len(fields) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// GetMessageTrace returns the execution trace persisted with a session message
+// scopes are the caller's verified scopes and must include the debug scope
+func (o *Orchestrator) GetMessageTrace(ctx context.Context, sessionID string, messageID int64, scopes []string) (*agentx.Trace, error) {
+	if !manifest.HasRequiredScopes(scopes, []string{o.debugScope}) {
+		return nil, NewDebugNotAllowedError(o.debugScope)
+	}
+
+	sessionWithMessages, err := o.GetSessionWithMessages(ctx, sessionID, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, message := range sessionWithMessages.Messages {
+		if message.ID != messageID {
+			continue
+		}
+		if message.Metadata == "" {
+			break
+		}
+
+		var metadata struct {
+			Trace *agentx.Trace `json:"trace"`
+		}
+		if err := json.Unmarshal([]byte(message.Metadata), &metadata); err != nil {
+			return nil, err
+		}
+		if metadata.Trace != nil {
+			return metadata.Trace, nil
+		}
+		break
+	}
+
+	return nil, NewTraceNotFoundError(sessionID, messageID)
+}
+
+// ListToolCalls queries the tool call audit trail
+func (o *Orchestrator) ListToolCalls(ctx context.Context, filter memoryx.ToolCallFilter) ([]*memoryx.ToolCallRecord, error) {
+	if o.toolCallSrv == nil {
+		return nil, fmt.Errorf("tool call audit not configured")
+	}
+
+	return o.toolCallSrv.ListToolCalls(ctx, filter)
 }
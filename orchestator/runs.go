@@ -0,0 +1,96 @@
+package orchestator
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Abraxas-365/ams/pkg/logx"
+	"github.com/google/uuid"
+)
+
+// activeRun is a chat run that can be cancelled
+type activeRun struct {
+	cancel    context.CancelFunc
+	sessionID string
+	userID    string // Only the user who started the run can cancel it
+	startedAt time.Time
+}
+
+// runRegistry tracks in-flight chat runs by ID
+type runRegistry struct {
+	mu   sync.Mutex
+	runs map[string]*activeRun
+}
+
+func newRunRegistry() *runRegistry {
+	return &runRegistry{runs: make(map[string]*activeRun)}
+}
+
+// start registers a run under runID and returns its cancellable context
+// finish must be called when the run ends
+func (r *runRegistry) start(ctx context.Context, runID, sessionID, userID string) (context.Context, func(), error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.runs[runID]; exists {
+		return nil, nil, NewRunAlreadyActiveError(runID)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	r.runs[runID] = &activeRun{
+		cancel:    cancel,
+		sessionID: sessionID,
+		userID:    userID,
+		startedAt: time.Now(),
+	}
+
+	finish := func() {
+		r.mu.Lock()
+		delete(r.runs, runID)
+		r.mu.Unlock()
+		cancel()
+	}
+
+	return runCtx, finish, nil
+}
+
+// cancel cancels a run owned by userID, reporting whether it was active
+// Runs of other users are reported as not active
+func (r *runRegistry) cancel(runID, userID string) bool {
+	r.mu.Lock()
+	run, exists := r.runs[runID]
+	r.mu.Unlock()
+
+	if !exists || run.userID != userID {
+		return false
+	}
+
+	run.cancel()
+	logx.WithFields(logx.Fields{
+		"run_id":     runID,
+		"session_id": run.sessionID,
+		"duration":   time.Since(run.startedAt),
+	}).Info("Run cancelled")
+	return true
+}
+
+// count returns the number of active runs
+func (r *runRegistry) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.runs)
+}
+
+// runOwner returns the ID of the user a request's run belongs to
+func runOwner(req ChatRequest) string {
+	if req.User == nil {
+		return ""
+	}
+	return req.User.ID
+}
+
+// NewRunID generates a new run ID
+func NewRunID() string {
+	return "run_" + uuid.NewString()
+}
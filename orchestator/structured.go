@@ -0,0 +1,152 @@
+package orchestator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Abraxas-365/ams/manifest"
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/agentx"
+	"github.com/Abraxas-365/ams/pkg/logx"
+	"github.com/Abraxas-365/ams/pkg/schemax"
+)
+
+// MetadataRepaired marks the messages of the schema repair retry: the repair
+// prompt and the repaired reply
+const MetadataRepaired = "repaired"
+
+// structuredResult is the validated reply of a route with a response_format
+type structuredResult struct {
+	Data     any    // Parsed object
+	Content  string // Raw JSON reply (the repaired one when a retry was needed)
+	Repaired bool   // The first reply was invalid and Content replaces it
+}
+
+// responseFormatOption converts a route response_format to an LLM option
+func responseFormatOption(route *manifest.Route) (llm.Option, error) {
+	schema, err := route.ResponseFormat.JSONSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	return llm.WithResponseFormat(&llm.ResponseFormat{
+		Type:       llm.JSONSchema,
+		JSONSchema: schema,
+		Name:       route.ResponseFormat.SchemaName(route.Name),
+	}), nil
+}
+
+// resolveStructuredResponse validates the reply against the route schema
+// Invalid output gets one repair attempt: the model sees the violations and
+// answers again without tools. The repair prompt and the repaired reply are
+// added to memory, so the conversation keeps alternating turns
+func (o *Orchestrator) resolveStructuredResponse(
+	ctx context.Context,
+	agent *agentx.Agent,
+	route *manifest.Route,
+	content string,
+) (*structuredResult, error) {
+	schema, err := route.ResponseFormat.JSONSchema()
+	if err != nil {
+		return nil, NewStructuredResponseInvalidError(route.Name, err)
+	}
+
+	data, validationErr := schemax.ValidateJSON([]byte(extractJSON(content)), schema)
+	if validationErr == nil {
+		return &structuredResult{Data: data, Content: content}, nil
+	}
+
+	logx.WithFields(logx.Fields{
+		"route_name": route.Name,
+	}).WithError(validationErr).Warn("Structured response invalid, attempting repair")
+
+	messages, err := agent.Messages()
+	if err != nil {
+		return nil, NewStructuredResponseInvalidError(route.Name, err)
+	}
+	repairMessage := llm.NewUserMessage(repairPrompt(validationErr))
+	repairMessage.Metadata = map[string]any{MetadataRepaired: true}
+	messages = append(messages, repairMessage)
+
+	options, err := o.routeLLMOptions(route)
+	if err != nil {
+		return nil, NewStructuredResponseInvalidError(route.Name, err)
+	}
+
+	client, err := o.routeLLMClient(route)
+	if err != nil {
+		return nil, err
+	}
+	response, err := client.Chat(ctx, messages, options...)
+	if err != nil {
+		return nil, NewStructuredResponseInvalidError(route.Name, err)
+	}
+
+	repaired := response.Message.Content
+	data, err = schemax.ValidateJSON([]byte(extractJSON(repaired)), schema)
+	if err != nil {
+		logx.WithField("route_name", route.Name).WithError(err).Error("Structured response repair failed")
+		return nil, NewStructuredResponseInvalidError(route.Name, err)
+	}
+
+	message := llm.NewAssistantMessage(repaired)
+	message.Metadata = map[string]any{MetadataRepaired: true}
+	for _, saved := range []llm.Message{repairMessage, message} {
+		if err := agent.AddMessage(saved); err != nil {
+			logx.WithError(err).Warn("Failed to save repaired structured response")
+			break
+		}
+	}
+
+	logx.WithField("route_name", route.Name).Info("✅ Structured response repaired")
+	return &structuredResult{Data: data, Content: repaired, Repaired: true}, nil
+}
+
+// lastAssistantContent returns the final reply of a streamed run
+// Streamed tokens span every turn, so the reply is read back from memory
+func lastAssistantContent(agent *agentx.Agent) string {
+	messages, err := agent.Messages()
+	if err != nil {
+		return ""
+	}
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == llm.RoleAssistant {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
+
+// repairPrompt explains the schema violations to the model
+func repairPrompt(err error) string {
+	var sb strings.Builder
+	sb.WriteString("Your previous reply did not match the required JSON schema.\n")
+
+	var validationErr *schemax.ValidationError
+	if errors.As(err, &validationErr) {
+		sb.WriteString("Problems:\n")
+		for _, violation := range validationErr.Violations {
+			fmt.Fprintf(&sb, "- %s\n", violation)
+		}
+	} else {
+		fmt.Fprintf(&sb, "Problem: %v\n", err)
+	}
+
+	sb.WriteString("Reply again with only the corrected JSON object, no prose or code fences.")
+	return sb.String()
+}
+
+// extractJSON strips markdown code fences models sometimes add around JSON
+func extractJSON(content string) string {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "```") {
+		return content
+	}
+
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+	return strings.TrimSpace(content)
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
+	"time"
 
 	"github.com/Abraxas-365/ams/pkg/ai/llm"
 	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx"
@@ -12,6 +14,12 @@ import (
 	"github.com/Abraxas-365/ams/pkg/logx"
 )
 
+// MetadataInterrupted marks messages cut short by a cancelled run
+const MetadataInterrupted = "interrupted"
+
+// interruptedToolResult is recorded for tool calls that never ran
+const interruptedToolResult = "Tool call was not executed: the run was cancelled"
+
 // Agent represents an LLM-powered agent with memory and tool capabilities
 type Agent struct {
 	client             *llm.Client
@@ -20,6 +28,7 @@ type Agent struct {
 	options            []llm.Option
 	maxAutoIterations  int // Max iterations with "auto" tool choice
 	maxTotalIterations int // Hard limit to prevent infinite loops
+	interceptors       []Interceptor
 }
 
 // AgentOption configures an Agent
@@ -70,6 +79,7 @@ func New(client llm.Client, memory memoryx.Memory, opts ...AgentOption) *Agent {
 		"max_auto_iterations":  agent.maxAutoIterations,
 		"max_total_iterations": agent.maxTotalIterations,
 		"has_tools":            agent.tools != nil,
+		"interceptors":         len(agent.interceptors),
 	}).Debug("Agent initialized")
 
 	return agent
@@ -77,10 +87,26 @@ func New(client llm.Client, memory memoryx.Memory, opts ...AgentOption) *Agent {
 
 // Run processes a user message and returns the final response
 func (a *Agent) Run(ctx context.Context, userInput string) (string, error) {
-	logx.WithField("user_input", userInput).Info("Starting agent run")
+	return a.RunMessage(ctx, llm.NewUserMessage(userInput))
+}
+
+// RunMessage is Run for a prepared user message, e.g. one with image or file parts
+func (a *Agent) RunMessage(ctx context.Context, message llm.Message) (string, error) {
+	tracker := newRunTracker(message.Content)
+	output, err := a.run(ctx, message, tracker)
+	a.finish(ctx, tracker, output, err)
+	return output, err
+}
+
+// run executes Run, recording progress in tracker
+func (a *Agent) run(ctx context.Context, message llm.Message, tracker *runTracker) (string, error) {
+	logx.WithFields(logx.Fields{
+		"user_input": message.Content,
+		"parts":      len(message.Parts),
+	}).Info("Starting agent run")
 
 	// Add user message to memory
-	if err := a.memory.Add(llm.NewUserMessage(userInput)); err != nil {
+	if err := a.memory.Add(message); err != nil {
 		logx.WithError(err).Error("Failed to add user message to memory")
 		return "", fmt.Errorf("failed to add user message: %w", err)
 	}
@@ -94,20 +120,9 @@ func (a *Agent) Run(ctx context.Context, userInput string) (string, error) {
 	}
 	logx.WithField("message_count", len(messages)).Debug("Retrieved messages from memory")
 
-	// Check if tools are available and add them as options if so
-	options := a.options
-	if a.tools != nil {
-		// Convert tools to LLM-compatible format
-		toolList := a.getToolsList()
-		if len(toolList) > 0 {
-			options = append(options, llm.WithTools(toolList))
-			logx.WithField("tool_count", len(toolList)).Debug("Added tools to LLM options")
-		}
-	}
-
 	// Get response from LLM
 	logx.Debug("Calling LLM")
-	response, err := a.client.Chat(ctx, messages, options...)
+	response, err := a.chat(ctx, messages, a.turnOptions(0), 0, tracker)
 	if err != nil {
 		logx.WithError(err).Error("LLM call failed")
 		return "", fmt.Errorf("LLM error: %w", err)
@@ -128,7 +143,7 @@ func (a *Agent) Run(ctx context.Context, userInput string) (string, error) {
 	// Check if the response contains tool calls
 	if len(response.Message.ToolCalls) > 0 && a.tools != nil {
 		logx.WithField("tool_call_count", len(response.Message.ToolCalls)).Info("Processing tool calls")
-		return a.handleToolCalls(ctx, response.Message.ToolCalls)
+		return a.handleToolCalls(ctx, response.Message.ToolCalls, tracker, nil)
 	}
 
 	logx.Info("Agent run completed successfully")
@@ -136,7 +151,8 @@ func (a *Agent) Run(ctx context.Context, userInput string) (string, error) {
 }
 
 // RunStream streams the agent's initial response
-// Note: This doesn't handle tool calls in streaming mode
+// Note: This doesn't handle tool calls in streaming mode or run interceptors
+// other than BeforeLLMCall
 func (a *Agent) RunStream(ctx context.Context, userInput string) (llm.Stream, error) {
 	logx.WithField("user_input", userInput).Info("Starting agent stream")
 
@@ -163,122 +179,255 @@ func (a *Agent) RunStream(ctx context.Context, userInput string) (llm.Stream, er
 		}
 	}
 
+	// Only BeforeLLMCall applies: the caller consumes the raw stream
+	call := &LLMCall{Messages: messages, Options: options, Stream: true}
+	if err := a.beforeLLMCall(ctx, call); err != nil {
+		return nil, err
+	}
+
 	// Get streaming response
 	logx.Debug("Initiating stream")
-	return a.client.ChatStream(ctx, messages, options...)
+	return a.client.ChatStream(ctx, call.Messages, call.Options...)
 }
 
 // handleToolCalls processes tool calls and returns the final response
-func (a *Agent) handleToolCalls(ctx context.Context, toolCalls []llm.ToolCall) (string, error) {
-	return a.handleToolCallsWithLimit(ctx, toolCalls, 0)
+// onEvent is optional and receives tool progress events
+func (a *Agent) handleToolCalls(ctx context.Context, toolCalls []llm.ToolCall, tracker *runTracker, onEvent StreamHandler) (string, error) {
+	return a.handleToolCallsWithLimit(ctx, toolCalls, 0, tracker, onEvent)
 }
 
 // handleToolCallsWithLimit processes tool calls with iteration limit
-func (a *Agent) handleToolCallsWithLimit(ctx context.Context, toolCalls []llm.ToolCall, iteration int) (string, error) {
+func (a *Agent) handleToolCallsWithLimit(ctx context.Context, toolCalls []llm.ToolCall, iteration int, tracker *runTracker, onEvent StreamHandler) (string, error) {
 	logx.WithFields(logx.Fields{
 		"iteration":       iteration,
 		"tool_call_count": len(toolCalls),
 	}).Debug("Handling tool calls")
 
 	// Hard limit check
+	if err := a.checkIterationLimit(iteration); err != nil {
+		return "", err
+	}
+
+	// Process each tool call
+	if err := a.executeToolCalls(ctx, toolCalls, tracker, onEvent); err != nil {
+		return "", err
+	}
+
+	// Get messages from memory
+	messages, err := a.memory.Messages()
+	if err != nil {
+		logx.WithError(err).Error("Failed to retrieve messages from memory")
+		return "", fmt.Errorf("failed to retrieve messages: %w", err)
+	}
+
+	logx.WithField("iteration", iteration).Debug("Calling LLM with tool results")
+
+	// Get next response from LLM with tool results
+	response, err := a.chat(ctx, messages, a.turnOptions(iteration+1), iteration+1, tracker)
+	if err != nil {
+		logx.WithError(err).Error("LLM call failed after tool execution")
+		return "", fmt.Errorf("LLM error: %w", err)
+	}
+
+	logx.WithFields(logx.Fields{
+		"has_content":    response.Message.Content != "",
+		"has_tool_calls": len(response.Message.ToolCalls) > 0,
+		"token_usage":    response.Usage,
+	}).Debug("LLM response received after tool execution")
+
+	// Add the response to memory
+	if err := a.memory.Add(response.Message); err != nil {
+		logx.WithError(err).Error("Failed to add assistant response to memory")
+		return "", fmt.Errorf("failed to add assistant response: %w", err)
+	}
+
+	// Check if we have more tool calls to handle
+	if len(response.Message.ToolCalls) > 0 {
+		logx.WithField("iteration", iteration+1).Debug("More tool calls to process")
+		return a.handleToolCallsWithLimit(ctx, response.Message.ToolCalls, iteration+1, tracker, onEvent)
+	}
+
+	logx.WithField("iteration", iteration).Info("Tool call chain completed")
+	return response.Message.Content, nil
+}
+
+// checkIterationLimit enforces the hard limit on tool call rounds
+func (a *Agent) checkIterationLimit(iteration int) error {
 	if iteration >= a.maxTotalIterations {
 		logx.WithFields(logx.Fields{
 			"iteration":            iteration,
 			"max_total_iterations": a.maxTotalIterations,
 		}).Warn("Maximum total iterations exceeded")
-		return "", fmt.Errorf("maximum total iterations (%d) exceeded", a.maxTotalIterations)
+		return fmt.Errorf("maximum total iterations (%d) exceeded", a.maxTotalIterations)
 	}
+	return nil
+}
 
-	// Process each tool call
+// executeToolCalls runs each tool call and adds its response to memory
+// onEvent is optional and receives tool progress events
+func (a *Agent) executeToolCalls(ctx context.Context, toolCalls []llm.ToolCall, tracker *runTracker, onEvent StreamHandler) error {
 	for i, tc := range toolCalls {
+		// Stop on cancellation, keeping every tool call paired with a response
+		if err := ctx.Err(); err != nil {
+			a.recordInterruptedToolCalls(toolCalls[i:])
+			return err
+		}
+
 		logx.WithFields(logx.Fields{
 			"tool_index": i,
 			"tool_name":  tc.Function.Name,
 			"tool_id":    tc.ID,
 		}).Debug("Executing tool")
 
+		onEvent.emit(StreamEvent{
+			Type:     EventToolCallStarted,
+			ToolCall: &ToolCallEvent{ID: tc.ID, Name: tc.Function.Name},
+		})
+
 		// Call the tool
-		toolResponse, err := a.tools.Call(ctx, tc)
+		toolResponse, duration, err := a.callTool(ctx, tc, tracker)
+		finished := &ToolCallEvent{
+			ID:       tc.ID,
+			Name:     tc.Function.Name,
+			Status:   ToolCallStatusSuccess,
+			Duration: duration,
+		}
 		if err != nil {
+			finished.Status = ToolCallStatusError
+			finished.Error = err.Error()
+			onEvent.emit(StreamEvent{Type: EventToolCallFinished, ToolCall: finished})
+
 			logx.WithFields(logx.Fields{
 				"tool_name": tc.Function.Name,
 				"tool_id":   tc.ID,
 			}).WithError(err).Error("Tool execution failed")
-			return "", fmt.Errorf("tool execution error: %w", err)
+			return fmt.Errorf("tool execution error: %w", err)
+		}
+		if toolErr, ok := toolResponse.Metadata[toolx.MetadataToolError].(string); ok {
+			// The error is reported back to the model, the run continues
+			finished.Status = ToolCallStatusError
+			finished.Error = toolErr
 		}
+		onEvent.emit(StreamEvent{Type: EventToolCallFinished, ToolCall: finished})
 
 		logx.WithFields(logx.Fields{
 			"tool_name": tc.Function.Name,
 			"tool_id":   tc.ID,
-		}).Debug("Tool executed successfully")
+			"duration":  finished.Duration,
+			"status":    finished.Status,
+		}).Debug("Tool executed")
 
 		// Add tool response to memory
 		if err := a.memory.Add(toolResponse); err != nil {
 			logx.WithError(err).Error("Failed to add tool response to memory")
-			return "", fmt.Errorf("failed to add tool response: %w", err)
+			return fmt.Errorf("failed to add tool response: %w", err)
 		}
 	}
+	return nil
+}
 
-	// Get messages from memory
-	messages, err := a.memory.Messages()
+// chat makes a model call through the interceptor chain
+func (a *Agent) chat(ctx context.Context, messages []llm.Message, options []llm.Option, toolRound int, tracker *runTracker) (llm.Response, error) {
+	call := &LLMCall{Messages: messages, Options: options, ToolRound: toolRound}
+	if err := a.beforeLLMCall(ctx, call); err != nil {
+		return llm.Response{}, err
+	}
+
+	response, err := a.client.Chat(ctx, call.Messages, call.Options...)
+	a.afterLLMCall(ctx, call, &response, err)
 	if err != nil {
-		logx.WithError(err).Error("Failed to retrieve messages from memory")
-		return "", fmt.Errorf("failed to retrieve messages: %w", err)
+		return llm.Response{}, err
 	}
 
-	// Smart tool choice: "auto" for first maxAutoIterations, then "none"
-	options := a.options
-	toolChoice := "none"
-	if a.tools != nil {
-		toolList := a.getToolsList()
-		if len(toolList) > 0 {
-			options = append(options, llm.WithTools(toolList))
+	if tracker != nil {
+		tracker.addUsage(response.Usage)
+	}
+	return response, nil
+}
 
-			if iteration < a.maxAutoIterations {
-				// First N iterations: allow "auto" tool calling
-				toolChoice = "auto"
-				options = append(options, llm.WithToolChoice("auto"))
-			} else {
-				// After N iterations: force "none" to prevent more tool calls
-				toolChoice = "none"
-				options = append(options, llm.WithToolChoice("none"))
-				logx.WithField("iteration", iteration).Warn("Forcing tool choice to 'none' due to iteration limit")
-			}
+// callTool executes a tool call through the interceptor chain
+// A vetoed call returns an error result for the model instead of running the tool
+func (a *Agent) callTool(ctx context.Context, tc llm.ToolCall, tracker *runTracker) (llm.Message, time.Duration, error) {
+	if tracker != nil {
+		tracker.toolCalls++
+	}
+
+	startTime := time.Now()
+	var result llm.Message
+	if vetoErr := a.beforeToolCall(ctx, &tc); vetoErr != nil {
+		result = llm.NewToolMessage(tc.ID, fmt.Sprintf("Tool call rejected: %v", vetoErr))
+		result.Metadata = map[string]any{toolx.MetadataToolError: vetoErr.Error()}
+	} else {
+		var err error
+		result, err = a.tools.Call(ctx, tc)
+		if err != nil {
+			return llm.Message{}, time.Since(startTime), err
 		}
 	}
 
-	logx.WithFields(logx.Fields{
-		"iteration":   iteration,
-		"tool_choice": toolChoice,
-	}).Debug("Calling LLM with tool results")
+	duration := time.Since(startTime)
+	a.afterToolCall(ctx, tc, &result, duration)
+	return result, duration, nil
+}
 
-	// Get next response from LLM with tool results
-	response, err := a.client.Chat(ctx, messages, options...)
-	if err != nil {
-		logx.WithError(err).Error("LLM call failed after tool execution")
-		return "", fmt.Errorf("LLM error: %w", err)
+// recordInterruptedToolCalls adds placeholder responses for tool calls skipped by a cancelled run
+// Providers reject assistant tool calls without matching tool messages
+func (a *Agent) recordInterruptedToolCalls(toolCalls []llm.ToolCall) {
+	for _, tc := range toolCalls {
+		msg := llm.NewToolMessage(tc.ID, interruptedToolResult)
+		msg.Metadata = map[string]any{MetadataInterrupted: true}
+		if err := a.memory.Add(msg); err != nil {
+			logx.WithError(err).Warn("Failed to record interrupted tool call")
+		}
 	}
+	logx.WithField("tool_call_count", len(toolCalls)).Info("Tool calls skipped due to cancellation")
+}
 
-	logx.WithFields(logx.Fields{
-		"has_content":    response.Message.Content != "",
-		"has_tool_calls": len(response.Message.ToolCalls) > 0,
-		"token_usage":    response.Usage,
-	}).Debug("LLM response received after tool execution")
+// recordInterruptedMessage saves the partial assistant output of a cancelled turn
+// Incomplete tool calls are dropped since their arguments may be truncated
+func (a *Agent) recordInterruptedMessage(partial llm.Message) {
+	if partial.Content == "" {
+		return
+	}
 
-	// Add the response to memory
-	if err := a.memory.Add(response.Message); err != nil {
-		logx.WithError(err).Error("Failed to add assistant response to memory")
-		return "", fmt.Errorf("failed to add assistant response: %w", err)
+	msg := llm.NewAssistantMessage(partial.Content)
+	msg.Metadata = map[string]any{MetadataInterrupted: true}
+	if err := a.memory.Add(msg); err != nil {
+		logx.WithError(err).Warn("Failed to save interrupted assistant output")
+		return
 	}
+	logx.WithField("content_length", len(partial.Content)).Info("Interrupted assistant output saved")
+}
 
-	// Check if we have more tool calls to handle
-	if len(response.Message.ToolCalls) > 0 {
-		logx.WithField("iteration", iteration+1).Debug("More tool calls to process")
-		return a.handleToolCallsWithLimit(ctx, response.Message.ToolCalls, iteration+1)
+// turnOptions returns the LLM options for a model turn
+// toolRounds is the number of tool rounds already executed (0 = initial turn)
+// Smart tool choice: "auto" for the first maxAutoIterations rounds, then "none"
+func (a *Agent) turnOptions(toolRounds int) []llm.Option {
+	options := append([]llm.Option{}, a.options...)
+	if a.tools == nil {
+		return options
 	}
 
-	logx.WithField("iteration", iteration).Info("Tool call chain completed")
-	return response.Message.Content, nil
+	toolList := a.getToolsList()
+	if len(toolList) == 0 {
+		return options
+	}
+	options = append(options, llm.WithTools(toolList))
+
+	if toolRounds == 0 {
+		return options
+	}
+
+	if toolRounds-1 < a.maxAutoIterations {
+		// First N iterations: allow "auto" tool calling
+		options = append(options, llm.WithToolChoice("auto"))
+	} else {
+		// After N iterations: force "none" to prevent more tool calls
+		options = append(options, llm.WithToolChoice("none"))
+		logx.WithField("iteration", toolRounds-1).Warn("Forcing tool choice to 'none' due to iteration limit")
+	}
+
+	return options
 }
 
 // getToolsList converts the tools to LLM-compatible format
@@ -320,43 +469,108 @@ func (a *Agent) Messages() ([]llm.Message, error) {
 }
 
 // StreamWithTools streams responses while handling tool calls
-// This is a more advanced implementation that processes tool calls in streaming mode
-func (a *Agent) StreamWithTools(ctx context.Context, userInput string, streamHandler func(chunk string)) error {
-	logx.WithField("user_input", userInput).Info("Starting stream with tools")
+// Every model turn streams, including the ones following tool execution.
+// Content is delivered as EventToken events and tool progress as tool call events
+func (a *Agent) StreamWithTools(ctx context.Context, userInput string, streamHandler StreamHandler) error {
+	return a.StreamMessageWithTools(ctx, llm.NewUserMessage(userInput), streamHandler)
+}
 
-	if err := a.memory.Add(llm.NewUserMessage(userInput)); err != nil {
+// StreamMessageWithTools is StreamWithTools for a prepared user message
+func (a *Agent) StreamMessageWithTools(ctx context.Context, message llm.Message, streamHandler StreamHandler) error {
+	tracker := newRunTracker(message.Content)
+	output, err := a.streamWithTools(ctx, message, tracker, streamHandler)
+	a.finish(ctx, tracker, output, err)
+	return err
+}
+
+// streamWithTools executes StreamWithTools and returns the final content
+func (a *Agent) streamWithTools(ctx context.Context, message llm.Message, tracker *runTracker, streamHandler StreamHandler) (string, error) {
+	logx.WithFields(logx.Fields{
+		"user_input": message.Content,
+		"parts":      len(message.Parts),
+	}).Info("Starting stream with tools")
+
+	if err := a.memory.Add(message); err != nil {
 		logx.WithError(err).Error("Failed to add user message to memory")
-		return fmt.Errorf("failed to add user message: %w", err)
+		return "", fmt.Errorf("failed to add user message: %w", err)
 	}
 
-	messages, err := a.memory.Messages()
-	if err != nil {
-		logx.WithError(err).Error("Failed to retrieve messages from memory")
-		return fmt.Errorf("failed to retrieve messages: %w", err)
-	}
+	for toolRounds := 0; ; toolRounds++ {
+		messages, err := a.memory.Messages()
+		if err != nil {
+			logx.WithError(err).Error("Failed to retrieve messages from memory")
+			return "", fmt.Errorf("failed to retrieve messages: %w", err)
+		}
 
-	options := a.options
-	if a.tools != nil {
-		toolList := a.getToolsList()
-		if len(toolList) > 0 {
-			options = append(options, llm.WithTools(toolList))
-			logx.WithField("tool_count", len(toolList)).Debug("Added tools to stream")
+		message, err := a.streamTurn(ctx, messages, a.turnOptions(toolRounds), toolRounds, tracker, streamHandler)
+		if err != nil {
+			if ctx.Err() != nil {
+				a.recordInterruptedMessage(message)
+				return message.Content, ctx.Err()
+			}
+			return "", err
+		}
+
+		// Add the full message to memory
+		if err := a.memory.Add(message); err != nil {
+			logx.WithError(err).Error("Failed to add assistant response to memory")
+			return "", fmt.Errorf("failed to add assistant response: %w", err)
+		}
+
+		if len(message.ToolCalls) == 0 || a.tools == nil {
+			logx.WithField("tool_rounds", toolRounds).Info("Stream with tools completed successfully")
+			return message.Content, nil
+		}
+
+		// Hard limit check
+		if err := a.checkIterationLimit(toolRounds); err != nil {
+			return "", err
+		}
+
+		logx.WithFields(logx.Fields{
+			"iteration":       toolRounds,
+			"tool_call_count": len(message.ToolCalls),
+		}).Info("Processing tool calls from stream")
+
+		if err := a.executeToolCalls(ctx, message.ToolCalls, tracker, streamHandler); err != nil {
+			return "", err
 		}
 	}
+}
+
+// streamTurn streams a single model turn and returns the assembled message
+// On error the content received so far is returned alongside it
+func (a *Agent) streamTurn(ctx context.Context, messages []llm.Message, options []llm.Option, toolRound int, tracker *runTracker, streamHandler StreamHandler) (llm.Message, error) {
+	call := &LLMCall{Messages: messages, Options: options, ToolRound: toolRound, Stream: true}
+	if err := a.beforeLLMCall(ctx, call); err != nil {
+		return llm.Message{}, err
+	}
+
+	message, err := a.readStream(ctx, call, streamHandler)
+	usage, _ := llm.StreamUsage(message)
+	response := llm.Response{Message: llm.WithoutStreamUsage(message), Usage: usage}
+	a.afterLLMCall(ctx, call, &response, err)
+
+	if tracker != nil {
+		tracker.addUsage(response.Usage)
+	}
+	return response.Message, err
+}
 
-	// Initial streaming response
-	logx.Debug("Starting stream")
-	stream, err := a.client.ChatStream(ctx, messages, options...)
+// readStream runs a streaming model call and assembles its message
+func (a *Agent) readStream(ctx context.Context, call *LLMCall, streamHandler StreamHandler) (llm.Message, error) {
+	logx.WithField("message_count", len(call.Messages)).Debug("Starting stream")
+	stream, err := a.client.ChatStream(ctx, call.Messages, call.Options...)
 	if err != nil {
 		logx.WithError(err).Error("Failed to start stream")
-		return err
+		return llm.Message{}, err
 	}
 	defer stream.Close()
 
-	// Collect the full message and stream chunks to the handler
 	var fullMessage llm.Message
-	var responseContent string
+	var content strings.Builder
 	var toolCalls []llm.ToolCall
+	toolIndexes := make(map[int]int) // fragment index -> position in toolCalls
 	chunkCount := 0
 
 	for {
@@ -365,68 +579,76 @@ func (a *Agent) StreamWithTools(ctx context.Context, userInput string, streamHan
 			// Check if it's the end of the stream
 			if errors.Is(err, io.EOF) {
 				logx.WithField("chunk_count", chunkCount).Debug("Stream ended")
-				// Some implementations might return a final chunk with the error
+				// Providers may return the assembled message with io.EOF
 				if chunk.Role != "" {
 					fullMessage = chunk
 				}
 				break
 			}
-			// Any other error is returned
+			// Any other error is returned with the partial content
 			logx.WithError(err).Error("Stream error")
-			return err
+			return llm.Message{Role: llm.RoleAssistant, Content: content.String()}, err
 		}
 
 		chunkCount++
 
-		// Accumulate content
+		// Forward content deltas as they arrive
 		if chunk.Content != "" {
-			responseContent += chunk.Content
-			streamHandler(chunk.Content)
+			content.WriteString(chunk.Content)
+			streamHandler.emit(StreamEvent{Type: EventToken, Content: chunk.Content})
 		}
 
-		// Collect tool calls if present
-		if len(chunk.ToolCalls) > 0 {
-			toolCalls = chunk.ToolCalls
-			logx.WithField("tool_call_count", len(chunk.ToolCalls)).Debug("Tool calls detected in stream")
-		}
+		// Providers that don't assemble on EOF send tool calls in chunks
+		toolCalls = mergeToolCallDeltas(toolCalls, toolIndexes, chunk.ToolCalls)
 	}
 
-	// If we don't have a full message yet, construct one
 	if fullMessage.Role == "" {
 		fullMessage = llm.Message{
 			Role:      llm.RoleAssistant,
-			Content:   responseContent,
+			Content:   content.String(),
 			ToolCalls: toolCalls,
 		}
 	}
+	if fullMessage.Content == "" {
+		fullMessage.Content = content.String()
+	}
 
 	logx.WithFields(logx.Fields{
-		"content_length": len(responseContent),
-		"tool_calls":     len(toolCalls),
-	}).Debug("Stream completed, processing full message")
+		"content_length": len(fullMessage.Content),
+		"tool_calls":     len(fullMessage.ToolCalls),
+	}).Debug("Stream turn completed")
 
-	// Add the full message to memory
-	if err := a.memory.Add(fullMessage); err != nil {
-		logx.WithError(err).Error("Failed to add assistant response to memory")
-		return fmt.Errorf("failed to add assistant response: %w", err)
-	}
-
-	// Process tool calls if any
-	if len(fullMessage.ToolCalls) > 0 && a.tools != nil {
-		logx.Info("Processing tool calls from stream")
-		streamHandler("\n[Processing tool calls...]\n")
+	return fullMessage, nil
+}
 
-		finalResponse, err := a.handleToolCalls(ctx, fullMessage.ToolCalls)
-		if err != nil {
-			logx.WithError(err).Error("Failed to handle tool calls")
-			return err
+// mergeToolCallDeltas folds streamed tool call fragments into calls
+// Fragments are keyed on their index: the first one for an index starts a
+// call and later ones append their name and argument text. A fragment whose
+// ID differs from its call's starts a new call, for providers that send
+// complete calls without numbering them
+func mergeToolCallDeltas(calls []llm.ToolCall, indexes map[int]int, deltas []llm.ToolCall) []llm.ToolCall {
+	for _, delta := range deltas {
+		position, exists := indexes[delta.Index]
+		if exists && delta.ID != "" && calls[position].ID != "" && delta.ID != calls[position].ID {
+			exists = false
+		}
+		if !exists {
+			indexes[delta.Index] = len(calls)
+			calls = append(calls, delta)
+			continue
 		}
 
-		streamHandler("\n[Final response after tool calls]\n" + finalResponse)
-		logx.Info("Stream with tools completed successfully")
+		call := &calls[position]
+		if call.ID == "" {
+			call.ID = delta.ID
+		}
+		if call.Type == "" {
+			call.Type = delta.Type
+		}
+		call.Function.Name += delta.Function.Name
+		call.Function.Arguments += delta.Function.Arguments
 	}
-
-	return nil
+	return calls
 }
 
 // RunConversation runs a complete conversation with multiple turns
@@ -495,7 +717,7 @@ func (a *Agent) EvaluateWithTools(ctx context.Context, userInput string) (*Agent
 
 	// Get response from LLM
 	logx.Debug("Getting initial LLM response for evaluation")
-	response, err := a.client.Chat(ctx, messages, options...)
+	response, err := a.chat(ctx, messages, options, 0, nil)
 	if err != nil {
 		logx.WithError(err).Error("LLM call failed during evaluation")
 		return nil, fmt.Errorf("LLM error: %w", err)
@@ -573,7 +795,7 @@ func (a *Agent) evaluateToolCallsWithLimit(ctx context.Context, toolCalls []llm.
 		}).Debug("Evaluating tool execution")
 
 		// Call the tool
-		toolResponse, err := a.tools.Call(ctx, tc)
+		toolResponse, _, err := a.callTool(ctx, tc, nil)
 		if err != nil {
 			logx.WithFields(logx.Fields{
 				"tool_name": tc.Function.Name,
@@ -632,7 +854,7 @@ func (a *Agent) evaluateToolCallsWithLimit(ctx context.Context, toolCalls []llm.
 		"tool_choice": toolChoice,
 	}).Debug("Getting LLM response with tool results")
 
-	response, err := a.client.Chat(ctx, messages, options...)
+	response, err := a.chat(ctx, messages, options, iteration+1, nil)
 	if err != nil {
 		logx.WithError(err).Error("LLM call failed during evaluation")
 		return "", steps, fmt.Errorf("LLM error: %w", err)
@@ -0,0 +1,73 @@
+package agentx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/llmtest"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx"
+)
+
+type finishRecorder struct {
+	NoopInterceptor
+	result RunResult
+}
+
+func (r *finishRecorder) OnFinish(ctx context.Context, result RunResult) {
+	r.result = result
+}
+
+func TestStreamReportsUsage(t *testing.T) {
+	fake := llmtest.New()
+	fake.On().Reply("Hello there").WithUsage(llm.Usage{PromptTokens: 10, CompletionTokens: 3, TotalTokens: 13})
+
+	recorder := &finishRecorder{}
+	memory := memoryx.NewBufferMemory(llm.NewSystemMessage("You are helpful"))
+	agent := New(fake.Client(), memory, WithInterceptors(recorder))
+
+	if err := agent.StreamWithTools(context.Background(), "Hi", func(StreamEvent) {}); err != nil {
+		t.Fatalf("StreamWithTools: %v", err)
+	}
+
+	if recorder.result.Usage.TotalTokens != 13 {
+		t.Errorf("usage = %+v, want 13 total tokens", recorder.result.Usage)
+	}
+
+	messages, _ := memory.Messages()
+	last := messages[len(messages)-1]
+	if _, ok := llm.StreamUsage(last); ok {
+		t.Errorf("stored message kept the stream usage: %+v", last.Metadata)
+	}
+}
+
+func TestMergeToolCallDeltas(t *testing.T) {
+	// Fragments of two calls interleave; only the first of each carries the ID
+	deltas := [][]llm.ToolCall{
+		{{Index: 0, ID: "call_1", Type: "function", Function: llm.FunctionCall{Name: "get_weather", Arguments: `{"loc`}}},
+		{{Index: 1, ID: "call_2", Type: "function", Function: llm.FunctionCall{Name: "get_time", Arguments: `{"zone`}}},
+		{{Index: 0, Function: llm.FunctionCall{Arguments: `ation": "Lima"}`}}},
+		{{Index: 1, Function: llm.FunctionCall{Arguments: `": "UTC"}`}}},
+		// Unnumbered complete call
+		{{ID: "call_3", Type: "function", Function: llm.FunctionCall{Name: "get_date", Arguments: `{}`}}},
+	}
+
+	var calls []llm.ToolCall
+	indexes := make(map[int]int)
+	for _, delta := range deltas {
+		calls = mergeToolCallDeltas(calls, indexes, delta)
+	}
+
+	if len(calls) != 3 {
+		t.Fatalf("calls = %+v, want 3", calls)
+	}
+	if calls[0].ID != "call_1" || calls[0].Function.Arguments != `{"location": "Lima"}` {
+		t.Errorf("first call = %+v", calls[0])
+	}
+	if calls[1].ID != "call_2" || calls[1].Function.Arguments != `{"zone": "UTC"}` {
+		t.Errorf("second call = %+v", calls[1])
+	}
+	if calls[2].ID != "call_3" || calls[2].Function.Arguments != `{}` {
+		t.Errorf("third call = %+v", calls[2])
+	}
+}
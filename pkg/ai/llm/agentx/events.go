@@ -0,0 +1,44 @@
+package agentx
+
+import "time"
+
+// StreamEventType identifies an event emitted while the agent streams
+type StreamEventType string
+
+const (
+	EventToken            StreamEventType = "token"              // Content delta from the model
+	EventToolCallStarted  StreamEventType = "tool_call_started"  // A tool is about to execute
+	EventToolCallFinished StreamEventType = "tool_call_finished" // A tool finished (successfully or not)
+)
+
+// Tool call statuses reported in EventToolCallFinished
+const (
+	ToolCallStatusSuccess = "success"
+	ToolCallStatusError   = "error"
+)
+
+// StreamEvent is a single event in the agent stream
+type StreamEvent struct {
+	Type     StreamEventType
+	Content  string         // Set for EventToken
+	ToolCall *ToolCallEvent // Set for tool call events
+}
+
+// ToolCallEvent describes tool progress
+type ToolCallEvent struct {
+	ID       string        `json:"id"`
+	Name     string        `json:"name"`
+	Status   string        `json:"status,omitempty"`   // Set when finished
+	Duration time.Duration `json:"duration,omitempty"` // Set when finished
+	Error    string        `json:"error,omitempty"`
+}
+
+// StreamHandler receives agent stream events
+type StreamHandler func(event StreamEvent)
+
+// emit sends an event when a handler is registered
+func (h StreamHandler) emit(event StreamEvent) {
+	if h != nil {
+		h(event)
+	}
+}
@@ -0,0 +1,196 @@
+package agentx
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/logx"
+)
+
+// Interceptor hooks into the agent loop
+// Interceptors run in registration order. Embed NoopInterceptor to only
+// implement the hooks you need
+type Interceptor interface {
+	// BeforeLLMCall runs before every model call and may modify the request
+	// Returning an error aborts the run
+	BeforeLLMCall(ctx context.Context, call *LLMCall) error
+
+	// AfterLLMCall runs after every model call with its response or error
+	// The response may be modified before the agent uses it
+	AfterLLMCall(ctx context.Context, call *LLMCall, response *llm.Response, err error)
+
+	// BeforeToolCall runs before a tool executes and may modify its arguments
+	// Returning an error vetoes the call; the reason is reported to the model
+	BeforeToolCall(ctx context.Context, toolCall *llm.ToolCall) error
+
+	// AfterToolCall runs after a tool executes (or is vetoed)
+	// The result message may be modified before it is added to memory
+	AfterToolCall(ctx context.Context, toolCall llm.ToolCall, result *llm.Message, duration time.Duration)
+
+	// OnFinish runs once when Run or StreamWithTools ends
+	OnFinish(ctx context.Context, result RunResult)
+}
+
+// LLMCall describes a model call about to be made
+type LLMCall struct {
+	Messages  []llm.Message
+	Options   []llm.Option
+	ToolRound int  // Tool rounds already executed in this run (0 = initial turn)
+	Stream    bool // True for streaming turns
+}
+
+// RunResult summarizes a finished agent run
+type RunResult struct {
+	Input     string
+	Output    string
+	ToolCalls int // Tool calls executed, including vetoed ones
+	Usage     llm.Usage
+	Duration  time.Duration
+	Err       error
+}
+
+// NoopInterceptor implements every hook as a no-op
+type NoopInterceptor struct{}
+
+func (NoopInterceptor) BeforeLLMCall(ctx context.Context, call *LLMCall) error { return nil }
+func (NoopInterceptor) AfterLLMCall(ctx context.Context, call *LLMCall, response *llm.Response, err error) {
+}
+func (NoopInterceptor) BeforeToolCall(ctx context.Context, toolCall *llm.ToolCall) error { return nil }
+func (NoopInterceptor) AfterToolCall(ctx context.Context, toolCall llm.ToolCall, result *llm.Message, duration time.Duration) {
+}
+func (NoopInterceptor) OnFinish(ctx context.Context, result RunResult) {}
+
+// WithInterceptors adds interceptors to the agent
+func WithInterceptors(interceptors ...Interceptor) AgentOption {
+	return func(a *Agent) {
+		a.interceptors = append(a.interceptors, interceptors...)
+	}
+}
+
+// ============================================================================
+// Chain execution
+// ============================================================================
+
+// runTracker accumulates the run summary passed to OnFinish
+type runTracker struct {
+	input     string
+	startedAt time.Time
+	toolCalls int
+	usage     llm.Usage
+}
+
+func newRunTracker(input string) *runTracker {
+	return &runTracker{input: input, startedAt: time.Now()}
+}
+
+func (t *runTracker) addUsage(usage llm.Usage) {
+	t.usage.PromptTokens += usage.PromptTokens
+	t.usage.CompletionTokens += usage.CompletionTokens
+	t.usage.TotalTokens += usage.TotalTokens
+}
+
+func (a *Agent) beforeLLMCall(ctx context.Context, call *LLMCall) error {
+	for _, interceptor := range a.interceptors {
+		if err := interceptor.BeforeLLMCall(ctx, call); err != nil {
+			logx.WithError(err).Warn("LLM call aborted by interceptor")
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *Agent) afterLLMCall(ctx context.Context, call *LLMCall, response *llm.Response, err error) {
+	for _, interceptor := range a.interceptors {
+		interceptor.AfterLLMCall(ctx, call, response, err)
+	}
+}
+
+func (a *Agent) beforeToolCall(ctx context.Context, toolCall *llm.ToolCall) error {
+	for _, interceptor := range a.interceptors {
+		if err := interceptor.BeforeToolCall(ctx, toolCall); err != nil {
+			logx.WithFields(logx.Fields{
+				"tool_name": toolCall.Function.Name,
+				"tool_id":   toolCall.ID,
+			}).WithError(err).Warn("Tool call vetoed by interceptor")
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *Agent) afterToolCall(ctx context.Context, toolCall llm.ToolCall, result *llm.Message, duration time.Duration) {
+	for _, interceptor := range a.interceptors {
+		interceptor.AfterToolCall(ctx, toolCall, result, duration)
+	}
+}
+
+// finish notifies interceptors that the run ended
+func (a *Agent) finish(ctx context.Context, tracker *runTracker, output string, err error) {
+	if tracker == nil || len(a.interceptors) == 0 {
+		return
+	}
+
+	result := RunResult{
+		Input:     tracker.input,
+		Output:    output,
+		ToolCalls: tracker.toolCalls,
+		Usage:     tracker.usage,
+		Duration:  time.Since(tracker.startedAt),
+		Err:       err,
+	}
+	for _, interceptor := range a.interceptors {
+		interceptor.OnFinish(ctx, result)
+	}
+}
+
+// ============================================================================
+// Built-in interceptors
+// ============================================================================
+
+// LoggingInterceptor logs model calls, tool calls and run summaries
+type LoggingInterceptor struct {
+	NoopInterceptor
+}
+
+// NewLoggingInterceptor creates an interceptor that logs the agent loop
+func NewLoggingInterceptor() *LoggingInterceptor {
+	return &LoggingInterceptor{}
+}
+
+func (l *LoggingInterceptor) AfterLLMCall(ctx context.Context, call *LLMCall, response *llm.Response, err error) {
+	fields := logx.Fields{
+		"tool_round":    call.ToolRound,
+		"stream":        call.Stream,
+		"message_count": len(call.Messages),
+	}
+	if err != nil {
+		logx.WithFields(fields).WithError(err).Info("LLM call failed")
+		return
+	}
+	fields["tool_calls"] = len(response.Message.ToolCalls)
+	fields["total_tokens"] = response.Usage.TotalTokens
+	logx.WithFields(fields).Info("LLM call completed")
+}
+
+func (l *LoggingInterceptor) AfterToolCall(ctx context.Context, toolCall llm.ToolCall, result *llm.Message, duration time.Duration) {
+	logx.WithFields(logx.Fields{
+		"tool_name":     toolCall.Function.Name,
+		"tool_id":       toolCall.ID,
+		"duration":      duration,
+		"result_length": len(result.Content),
+	}).Info("Tool call completed")
+}
+
+func (l *LoggingInterceptor) OnFinish(ctx context.Context, result RunResult) {
+	fields := logx.Fields{
+		"tool_calls":   result.ToolCalls,
+		"total_tokens": result.Usage.TotalTokens,
+		"duration":     result.Duration,
+	}
+	if result.Err != nil {
+		logx.WithFields(fields).WithError(result.Err).Info("Agent run finished with error")
+		return
+	}
+	logx.WithFields(fields).Info("Agent run finished")
+}
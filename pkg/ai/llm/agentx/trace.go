@@ -0,0 +1,182 @@
+package agentx
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/toolx"
+	"github.com/Abraxas-365/ams/pkg/shapex"
+)
+
+// MetadataTrace is the metadata key holding the trace on the final assistant message
+const MetadataTrace = "trace"
+
+// DefaultTraceResultBytes limits the tool result kept in each trace step
+const DefaultTraceResultBytes = 2048
+
+// TraceStepType identifies a step in an execution trace
+type TraceStepType string
+
+const (
+	TraceStepLLMCall  TraceStepType = "llm_call"
+	TraceStepToolCall TraceStepType = "tool_call"
+)
+
+// Trace is the ordered record of an agent run
+type Trace struct {
+	Steps      []TraceStep `json:"steps"`
+	Usage      llm.Usage   `json:"usage"`
+	DurationMs int64       `json:"duration_ms"`
+}
+
+// TraceStep is a single model or tool call
+type TraceStep struct {
+	Type       TraceStepType `json:"type"`
+	StartedAt  time.Time     `json:"started_at"`
+	DurationMs int64         `json:"duration_ms"`
+	Error      string        `json:"error,omitempty"`
+
+	// LLM calls
+	ToolRound int        `json:"tool_round,omitempty"`
+	Stream    bool       `json:"stream,omitempty"`
+	Usage     *llm.Usage `json:"usage,omitempty"`
+	ToolCalls []string   `json:"tool_calls,omitempty"` // Names of the tools requested
+
+	// Tool calls
+	ToolName   string `json:"tool_name,omitempty"`
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	Arguments  string `json:"arguments,omitempty"`
+	Result     string `json:"result,omitempty"` // Truncated
+}
+
+// Tracer is an interceptor that records an execution trace
+// The trace is attached to the final assistant message under MetadataTrace,
+// so it is persisted with the message when memory is session-backed
+type Tracer struct {
+	NoopInterceptor
+
+	mu             sync.Mutex
+	maxResultBytes int
+	redact         func(toolName, arguments string) string
+	startedAt      time.Time
+	llmStarts      []time.Time
+	trace          Trace
+}
+
+// TracerOption configures a tracer
+type TracerOption func(*Tracer)
+
+// WithArgumentRedactor masks tool arguments before they are recorded
+func WithArgumentRedactor(redact func(toolName, arguments string) string) TracerOption {
+	return func(t *Tracer) {
+		t.redact = redact
+	}
+}
+
+// NewTracer creates a tracer keeping at most maxResultBytes of each tool result
+func NewTracer(maxResultBytes int, opts ...TracerOption) *Tracer {
+	if maxResultBytes <= 0 {
+		maxResultBytes = DefaultTraceResultBytes
+	}
+	t := &Tracer{
+		maxResultBytes: maxResultBytes,
+		startedAt:      time.Now(),
+		trace:          Trace{Steps: []TraceStep{}},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Trace returns a snapshot of the recorded trace
+func (t *Tracer) Trace() *Trace {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.snapshot()
+}
+
+func (t *Tracer) BeforeLLMCall(ctx context.Context, call *LLMCall) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.llmStarts = append(t.llmStarts, time.Now())
+	return nil
+}
+
+func (t *Tracer) AfterLLMCall(ctx context.Context, call *LLMCall, response *llm.Response, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	startedAt := time.Now()
+	if n := len(t.llmStarts); n > 0 {
+		startedAt = t.llmStarts[n-1]
+		t.llmStarts = t.llmStarts[:n-1]
+	}
+
+	step := TraceStep{
+		Type:       TraceStepLLMCall,
+		StartedAt:  startedAt,
+		DurationMs: time.Since(startedAt).Milliseconds(),
+		ToolRound:  call.ToolRound,
+		Stream:     call.Stream,
+	}
+	if err != nil {
+		step.Error = err.Error()
+		t.trace.Steps = append(t.trace.Steps, step)
+		return
+	}
+
+	usage := response.Usage
+	step.Usage = &usage
+	for _, tc := range response.Message.ToolCalls {
+		step.ToolCalls = append(step.ToolCalls, tc.Function.Name)
+	}
+	t.trace.Steps = append(t.trace.Steps, step)
+	t.trace.Usage.PromptTokens += usage.PromptTokens
+	t.trace.Usage.CompletionTokens += usage.CompletionTokens
+	t.trace.Usage.TotalTokens += usage.TotalTokens
+
+	// The final answer carries the trace into memory
+	if len(response.Message.ToolCalls) == 0 {
+		if response.Message.Metadata == nil {
+			response.Message.Metadata = make(map[string]any)
+		}
+		response.Message.Metadata[MetadataTrace] = t.snapshot()
+	}
+}
+
+func (t *Tracer) AfterToolCall(ctx context.Context, toolCall llm.ToolCall, result *llm.Message, duration time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	arguments := toolCall.Function.Arguments
+	if t.redact != nil {
+		arguments = t.redact(toolCall.Function.Name, arguments)
+	}
+
+	step := TraceStep{
+		Type:       TraceStepToolCall,
+		StartedAt:  time.Now().Add(-duration),
+		DurationMs: duration.Milliseconds(),
+		ToolName:   toolCall.Function.Name,
+		ToolCallID: toolCall.ID,
+		Arguments:  arguments,
+		Result:     shapex.Truncate(result.Content, t.maxResultBytes).(string),
+	}
+	if toolErr, ok := result.Metadata[toolx.MetadataToolError].(string); ok {
+		step.Error = toolErr
+	}
+	t.trace.Steps = append(t.trace.Steps, step)
+}
+
+// snapshot copies the trace; callers must hold the lock
+func (t *Tracer) snapshot() *Trace {
+	trace := Trace{
+		Steps:      append([]TraceStep{}, t.trace.Steps...),
+		Usage:      t.trace.Usage,
+		DurationMs: time.Since(t.startedAt).Milliseconds(),
+	}
+	return &trace
+}
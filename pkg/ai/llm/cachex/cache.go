@@ -0,0 +1,259 @@
+// Package cachex provides an exact-match response cache for llm.LLM.
+package cachex
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/logx"
+)
+
+// MetadataCache is the response metadata key set to "hit" for cached answers
+const MetadataCache = "cache"
+
+// MetadataCachedUsage is the response metadata key holding the llm.Usage the
+// cached answer originally cost; hits themselves report zero usage
+const MetadataCachedUsage = "cached_usage"
+
+// DefaultTTL is used when Wrap is given no TTL
+const DefaultTTL = time.Hour
+
+// Store persists cached responses
+type Store interface {
+	// Get returns the value and whether it was found
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set stores the value for ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// Stats are the cache counters
+type Stats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+	Stores int64 `json:"stores"`
+	Errors int64 `json:"errors"`
+}
+
+// Cache shares a store and counters across wrapped LLMs
+type Cache struct {
+	store  Store
+	hits   atomic.Int64
+	misses atomic.Int64
+	stores atomic.Int64
+	errors atomic.Int64
+}
+
+// New creates a cache over a store
+func New(store Store) *Cache {
+	return &Cache{store: store}
+}
+
+// Stats returns a snapshot of the counters
+func (c *Cache) Stats() Stats {
+	return Stats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Stores: c.stores.Load(),
+		Errors: c.errors.Load(),
+	}
+}
+
+// Wrap returns an LLM that answers identical requests from the cache
+// The namespace (e.g. the provider name) keeps backends from sharing entries.
+// Responses containing tool calls are never cached
+func (c *Cache) Wrap(backend llm.LLM, namespace string, ttl time.Duration) llm.LLM {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &cachedLLM{cache: c, backend: backend, namespace: namespace, ttl: ttl}
+}
+
+type cachedLLM struct {
+	cache     *Cache
+	backend   llm.LLM
+	namespace string
+	ttl       time.Duration
+}
+
+// entry is the stored form of a response
+type entry struct {
+	Message llm.Message `json:"message"`
+	Usage   llm.Usage   `json:"usage"`
+}
+
+func (l *cachedLLM) Chat(ctx context.Context, messages []llm.Message, opts ...llm.Option) (llm.Response, error) {
+	key := l.key(messages, opts)
+	if cached, ok := l.lookup(ctx, key); ok {
+		return hitResponse(cached), nil
+	}
+
+	response, err := l.backend.Chat(ctx, messages, opts...)
+	if err != nil {
+		return response, err
+	}
+
+	l.save(ctx, key, entry{Message: response.Message, Usage: response.Usage})
+	return response, nil
+}
+
+func (l *cachedLLM) ChatStream(ctx context.Context, messages []llm.Message, opts ...llm.Option) (llm.Stream, error) {
+	key := l.key(messages, opts)
+	if cached, ok := l.lookup(ctx, key); ok {
+		return &replayStream{message: cached.Message}, nil
+	}
+
+	stream, err := l.backend.ChatStream(ctx, messages, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &recordingStream{Stream: stream, llm: l, ctx: ctx, key: key}, nil
+}
+
+func (l *cachedLLM) lookup(ctx context.Context, key string) (entry, bool) {
+	data, found, err := l.cache.store.Get(ctx, key)
+	if err != nil {
+		l.cache.errors.Add(1)
+		logx.WithError(err).Warn("LLM cache lookup failed")
+	}
+	if err != nil || !found {
+		l.cache.misses.Add(1)
+		return entry{}, false
+	}
+
+	var cached entry
+	if err := json.Unmarshal(data, &cached); err != nil {
+		l.cache.errors.Add(1)
+		l.cache.misses.Add(1)
+		return entry{}, false
+	}
+
+	l.cache.hits.Add(1)
+	return cached, true
+}
+
+func (l *cachedLLM) save(ctx context.Context, key string, e entry) {
+	if len(e.Message.ToolCalls) > 0 || e.Message.FunctionCall != nil || e.Message.Content == "" {
+		return
+	}
+
+	e.Message.Metadata = nil
+	data, err := json.Marshal(e)
+	if err == nil {
+		err = l.cache.store.Set(ctx, key, data, l.ttl)
+	}
+	if err != nil {
+		l.cache.errors.Add(1)
+		logx.WithError(err).Warn("LLM cache store failed")
+		return
+	}
+	l.cache.stores.Add(1)
+}
+
+// key hashes everything that influences the answer
+func (l *cachedLLM) key(messages []llm.Message, opts []llm.Option) string {
+	options := llm.DefaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	// Metadata (traces, run flags) doesn't reach the model
+	stripped := make([]llm.Message, len(messages))
+	for i, msg := range messages {
+		msg.Metadata = nil
+		stripped[i] = msg
+	}
+
+	// An unset temperature (provider default) differs from an explicit 0
+	var temperature *float32
+	if options.TemperatureSet {
+		temperature = &options.Temperature
+	}
+
+	data, _ := json.Marshal(struct {
+		Messages            []llm.Message       `json:"messages"`
+		Model               string              `json:"model"`
+		Temperature         *float32            `json:"temperature"`
+		TopP                float32             `json:"top_p"`
+		MaxTokens           int                 `json:"max_tokens"`
+		MaxCompletionTokens int                 `json:"max_completion_tokens"`
+		Stop                []string            `json:"stop"`
+		Tools               []llm.Tool          `json:"tools"`
+		Functions           []llm.Function      `json:"functions"`
+		ToolChoice          any                 `json:"tool_choice"`
+		ResponseFormat      *llm.ResponseFormat `json:"response_format"`
+		JSONMode            bool                `json:"json_mode"`
+		Seed                int64               `json:"seed"`
+		ReasoningEffort     string              `json:"reasoning_effort"`
+	}{
+		Messages:            stripped,
+		Model:               options.Model,
+		Temperature:         temperature,
+		TopP:                options.TopP,
+		MaxTokens:           options.MaxTokens,
+		MaxCompletionTokens: options.MaxCompletionTokens,
+		Stop:                options.Stop,
+		Tools:               options.Tools,
+		Functions:           options.Functions,
+		ToolChoice:          options.ToolChoice,
+		ResponseFormat:      options.ResponseFormat,
+		JSONMode:            options.JSONMode,
+		Seed:                options.Seed,
+		ReasoningEffort:     options.ReasoningEffort,
+	})
+
+	sum := sha256.Sum256(data)
+	return "llmcache:" + l.namespace + ":" + hex.EncodeToString(sum[:])
+}
+
+func hitResponse(cached entry) llm.Response {
+	return llm.Response{
+		Message:  cached.Message,
+		Metadata: map[string]any{MetadataCache: "hit", MetadataCachedUsage: cached.Usage},
+	}
+}
+
+// replayStream yields a cached message as one delta, then the message without usage
+type replayStream struct {
+	message llm.Message
+	sent    bool
+}
+
+func (s *replayStream) Next() (llm.Message, error) {
+	if !s.sent {
+		s.sent = true
+		return llm.Message{Role: llm.RoleAssistant, Content: s.message.Content}, nil
+	}
+	return s.message, io.EOF
+}
+
+func (s *replayStream) Close() error {
+	return nil
+}
+
+// recordingStream stores the assembled message, with the usage the stream
+// reported, once the stream completes
+type recordingStream struct {
+	llm.Stream
+	llm   *cachedLLM
+	ctx   context.Context
+	key   string
+	saved bool
+}
+
+func (s *recordingStream) Next() (llm.Message, error) {
+	msg, err := s.Stream.Next()
+	if err == io.EOF && !s.saved {
+		s.saved = true
+		msg.Role = llm.RoleAssistant
+		usage, _ := llm.StreamUsage(msg)
+		s.llm.save(s.ctx, s.key, entry{Message: msg, Usage: usage})
+	}
+	return msg, err
+}
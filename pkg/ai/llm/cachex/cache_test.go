@@ -0,0 +1,154 @@
+package cachex
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/llmtest"
+)
+
+func newTestCache(t *testing.T) (*Cache, *llmtest.Fake, llm.LLM) {
+	t.Helper()
+	cache := New(NewMemoryStore(100))
+	fake := llmtest.New()
+	return cache, fake, cache.Wrap(fake, "test", 0)
+}
+
+func TestKeyIsStable(t *testing.T) {
+	l := &cachedLLM{namespace: "test"}
+	messages := []llm.Message{llm.NewSystemMessage("Be brief"), llm.NewUserMessage("Hi")}
+	base := l.key(messages, []llm.Option{llm.WithModel("model-a"), llm.WithTemperature(0.2)})
+
+	withMetadata := []llm.Message{messages[0], llm.NewUserMessage("Hi")}
+	withMetadata[1].Metadata = map[string]any{"run_id": "run_1"}
+
+	tests := []struct {
+		name     string
+		messages []llm.Message
+		opts     []llm.Option
+		same     bool
+	}{
+		{"identical request", messages, []llm.Option{llm.WithModel("model-a"), llm.WithTemperature(0.2)}, true},
+		{"option order", messages, []llm.Option{llm.WithTemperature(0.2), llm.WithModel("model-a")}, true},
+		{"message metadata", withMetadata, []llm.Option{llm.WithModel("model-a"), llm.WithTemperature(0.2)}, true},
+		{"other model", messages, []llm.Option{llm.WithModel("model-b"), llm.WithTemperature(0.2)}, false},
+		{"other temperature", messages, []llm.Option{llm.WithModel("model-a"), llm.WithTemperature(0.3)}, false},
+		{"unset temperature", messages, []llm.Option{llm.WithModel("model-a")}, false},
+		{"other message", []llm.Message{messages[0], llm.NewUserMessage("Hello")}, []llm.Option{llm.WithModel("model-a"), llm.WithTemperature(0.2)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := l.key(tt.messages, tt.opts) == base; got != tt.same {
+				t.Errorf("same key = %v, want %v", got, tt.same)
+			}
+		})
+	}
+
+	if (&cachedLLM{namespace: "other"}).key(messages, []llm.Option{llm.WithModel("model-a"), llm.WithTemperature(0.2)}) == base {
+		t.Errorf("namespaces share keys")
+	}
+}
+
+func TestChatHitsAndMisses(t *testing.T) {
+	cache, fake, cached := newTestCache(t)
+	fake.On().Reply("Hello").WithUsage(llm.Usage{PromptTokens: 5, CompletionTokens: 2, TotalTokens: 7})
+	messages := []llm.Message{llm.NewUserMessage("Hi")}
+
+	first, err := cached.Chat(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	second, err := cached.Chat(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	if fake.CallCount() != 1 {
+		t.Errorf("backend calls = %d, want 1", fake.CallCount())
+	}
+	if first.Metadata[MetadataCache] == "hit" || second.Metadata[MetadataCache] != "hit" {
+		t.Errorf("cache metadata = %v then %v", first.Metadata, second.Metadata)
+	}
+	if second.Message.Content != "Hello" {
+		t.Errorf("cached response = %+v", second)
+	}
+	if first.Usage.TotalTokens != 7 || second.Usage != (llm.Usage{}) {
+		t.Errorf("usage = %+v then %+v, want zero on the hit", first.Usage, second.Usage)
+	}
+	if usage, _ := second.Metadata[MetadataCachedUsage].(llm.Usage); usage.TotalTokens != 7 {
+		t.Errorf("cached usage = %v, want 7 total tokens", second.Metadata[MetadataCachedUsage])
+	}
+	if stats := cache.Stats(); stats != (Stats{Hits: 1, Misses: 1, Stores: 1}) {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestChatSkipsToolCalls(t *testing.T) {
+	cache, fake, cached := newTestCache(t)
+	fake.On().ReplyToolCall("get_weather", map[string]any{"city": "Lima"})
+	messages := []llm.Message{llm.NewUserMessage("Weather?")}
+
+	for range 2 {
+		if _, err := cached.Chat(context.Background(), messages); err != nil {
+			t.Fatalf("Chat: %v", err)
+		}
+	}
+
+	if fake.CallCount() != 2 {
+		t.Errorf("backend calls = %d, want 2 (tool calls aren't cached)", fake.CallCount())
+	}
+	if stats := cache.Stats(); stats.Stores != 0 || stats.Hits != 0 || stats.Misses != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestChatStreamRecordsUsage(t *testing.T) {
+	cache, fake, cached := newTestCache(t)
+	fake.On().Reply("Hello there").WithUsage(llm.Usage{PromptTokens: 5, CompletionTokens: 3, TotalTokens: 8})
+	messages := []llm.Message{llm.NewUserMessage("Hi")}
+
+	for i := range 2 {
+		final := drain(t, cached, messages)
+		if final.Content != "Hello there" {
+			t.Errorf("stream %d content = %q", i, final.Content)
+		}
+		// Only the backend call costs tokens
+		wantTokens := 8
+		if i > 0 {
+			wantTokens = 0
+		}
+		if usage, _ := llm.StreamUsage(final); usage.TotalTokens != wantTokens {
+			t.Errorf("stream %d usage = %+v, want %d total tokens", i, usage, wantTokens)
+		}
+	}
+
+	if fake.CallCount() != 1 {
+		t.Errorf("backend calls = %d, want 1", fake.CallCount())
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Stores != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+// drain reads a stream to the end and returns the assembled message
+func drain(t *testing.T, l llm.LLM, messages []llm.Message) llm.Message {
+	t.Helper()
+	stream, err := l.ChatStream(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	defer stream.Close()
+
+	for {
+		msg, err := stream.Next()
+		if err == io.EOF {
+			return msg
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Capabilities describes the features a backend supports
+type Capabilities struct {
+	Tools      bool // Function calling
+	JSONSchema bool // Structured outputs constrained by a JSON schema
+	Streaming  bool // Token streaming
+}
+
+// AllCapabilities is the feature set of first-party APIs
+func AllCapabilities() Capabilities {
+	return Capabilities{Tools: true, JSONSchema: true, Streaming: true}
+}
+
+// adaptedLLM degrades requests to what the wrapped backend supports
+type adaptedLLM struct {
+	llm          LLM
+	capabilities Capabilities
+	defaults     []Option
+}
+
+// Adapt wraps an LLM so requests degrade gracefully on limited backends
+// Without tools, tool definitions are dropped and tool history is flattened to text;
+// without JSON schema support, the schema moves into the system prompt in JSON mode;
+// without streaming, ChatStream makes one Chat call and yields the whole reply.
+// Defaults are applied before request options (e.g. a provider's default model)
+func Adapt(backend LLM, capabilities Capabilities, defaults ...Option) LLM {
+	return &adaptedLLM{llm: backend, capabilities: capabilities, defaults: defaults}
+}
+
+func (a *adaptedLLM) Chat(ctx context.Context, messages []Message, opts ...Option) (Response, error) {
+	messages, opts = a.adapt(messages, opts)
+	return a.llm.Chat(ctx, messages, opts...)
+}
+
+func (a *adaptedLLM) ChatStream(ctx context.Context, messages []Message, opts ...Option) (Stream, error) {
+	messages, opts = a.adapt(messages, opts)
+	if a.capabilities.Streaming {
+		return a.llm.ChatStream(ctx, messages, opts...)
+	}
+
+	response, err := a.llm.Chat(ctx, messages, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &singleMessageStream{message: response.Message}, nil
+}
+
+// adapt applies defaults and rewrites the request for missing capabilities
+func (a *adaptedLLM) adapt(messages []Message, opts []Option) ([]Message, []Option) {
+	options := make([]Option, 0, len(a.defaults)+len(opts)+1)
+	options = append(options, a.defaults...)
+	options = append(options, opts...)
+
+	resolved := DefaultOptions()
+	for _, opt := range options {
+		opt(resolved)
+	}
+
+	if !a.capabilities.Tools {
+		messages = flattenToolHistory(messages)
+		options = append(options, func(o *ChatOptions) {
+			o.Tools = nil
+			o.Functions = nil
+			o.ToolChoice = nil
+		})
+	}
+
+	if !a.capabilities.JSONSchema && resolved.ResponseFormat != nil && resolved.ResponseFormat.Type == JSONSchema {
+		messages = withSchemaInstruction(messages, resolved.ResponseFormat.JSONSchema)
+		options = append(options, WithJSONResponseFormat())
+	}
+
+	return messages, options
+}
+
+// flattenToolHistory rewrites tool calls and results as plain text
+func flattenToolHistory(messages []Message) []Message {
+	flattened := make([]Message, 0, len(messages))
+	for _, msg := range messages {
+		switch {
+		case msg.Role == RoleAssistant && len(msg.ToolCalls) > 0:
+			parts := make([]string, 0, len(msg.ToolCalls)+1)
+			if msg.Content != "" {
+				parts = append(parts, msg.Content)
+			}
+			for _, tc := range msg.ToolCalls {
+				parts = append(parts, fmt.Sprintf("[Called %s with %s]", tc.Function.Name, tc.Function.Arguments))
+			}
+			flattened = append(flattened, Message{Role: RoleAssistant, Content: strings.Join(parts, "\n"), Metadata: msg.Metadata})
+		case msg.Role == RoleTool || msg.Role == RoleFunction:
+			flattened = append(flattened, Message{Role: RoleUser, Content: "[Tool result]\n" + msg.Content, Metadata: msg.Metadata})
+		default:
+			flattened = append(flattened, msg)
+		}
+	}
+	return flattened
+}
+
+// withSchemaInstruction appends the schema to the system prompt
+func withSchemaInstruction(messages []Message, schema any) []Message {
+	data, _ := json.Marshal(schema)
+	instruction := "Respond only with a JSON object that matches this JSON schema:\n" + string(data)
+
+	result := make([]Message, len(messages))
+	copy(result, messages)
+	for i, msg := range result {
+		if msg.Role == RoleSystem {
+			result[i].Content = msg.Content + "\n\n" + instruction
+			return result
+		}
+	}
+	return append([]Message{NewSystemMessage(instruction)}, result...)
+}
+
+// singleMessageStream yields a complete message as one delta
+type singleMessageStream struct {
+	message Message
+	sent    bool
+}
+
+func (s *singleMessageStream) Next() (Message, error) {
+	if !s.sent {
+		s.sent = true
+		if s.message.Content != "" {
+			return Message{Role: RoleAssistant, Content: s.message.Content}, nil
+		}
+	}
+	return s.message, io.EOF
+}
+
+func (s *singleMessageStream) Close() error {
+	return nil
+}
@@ -0,0 +1,49 @@
+package llm
+
+import (
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// Model catalog
+// Providers register the models they serve so configuration can be validated
+// before a request reaches the API
+
+var (
+	catalogMu sync.RWMutex
+	catalog   = make(map[string]bool)
+)
+
+// snapshotSuffix matches model snapshots such as "-2025-08-07", "-20250514" or "-latest"
+var snapshotSuffix = regexp.MustCompile(`-(\d{4}-\d{2}-\d{2}|\d{8}|latest)$`)
+
+// RegisterModels adds model identifiers to the catalog
+func RegisterModels(models ...string) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	for _, model := range models {
+		catalog[model] = true
+	}
+}
+
+// IsKnownModel reports whether a model is in the catalog
+// Snapshots of a registered model (e.g. "gpt-4o-2024-08-06") are accepted
+func IsKnownModel(model string) bool {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	return catalog[model] || catalog[snapshotSuffix.ReplaceAllString(model, "")]
+}
+
+// KnownModels returns the registered models in sorted order
+func KnownModels() []string {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	models := make([]string, 0, len(catalog))
+	for model := range catalog {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	return models
+}
@@ -0,0 +1,202 @@
+package llm
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/Abraxas-365/ams/pkg/fsx"
+)
+
+// Content part types
+const (
+	PartText     = "text"      // Text, sent after Message.Content
+	PartImageURL = "image_url" // Image fetched by the provider from URL
+	PartImage    = "image"     // Inline base64 image in Data
+	PartFile     = "file"      // Inline base64 file in Data, or an fsx Path resolved before sending
+)
+
+// ContentPart is a piece of multimodal message content
+// Message.Content stays the main text; parts carry everything else
+type ContentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Data     string `json:"data,omitempty"` // Base64 encoded
+	MIMEType string `json:"mime_type,omitempty"`
+	Path     string `json:"path,omitempty"` // fsx path, kept instead of the data when persisted
+	Filename string `json:"filename,omitempty"`
+	Detail   string `json:"detail,omitempty"` // Image detail: auto, low or high
+}
+
+// TextPart creates a text part
+func TextPart(text string) ContentPart {
+	return ContentPart{Type: PartText, Text: text}
+}
+
+// ImageURLPart creates an image part the provider fetches from url
+func ImageURLPart(url string) ContentPart {
+	return ContentPart{Type: PartImageURL, URL: url}
+}
+
+// ImageDataPart creates an inline image part
+func ImageDataPart(data []byte, mimeType string) ContentPart {
+	return ContentPart{Type: PartImage, Data: base64.StdEncoding.EncodeToString(data), MIMEType: mimeType}
+}
+
+// FilePart creates a part referencing a file in the configured file system
+func FilePart(path string) ContentPart {
+	return ContentPart{Type: PartFile, Path: path}
+}
+
+// NewUserMessageWithParts creates a user message with attachments
+func NewUserMessageWithParts(content string, parts ...ContentPart) Message {
+	return Message{
+		Role:    RoleUser,
+		Content: content,
+		Parts:   parts,
+	}
+}
+
+// Validate checks the part carries what its type needs
+func (p ContentPart) Validate() error {
+	switch p.Type {
+	case PartText:
+		if p.Text == "" {
+			return fmt.Errorf("text part requires text")
+		}
+	case PartImageURL:
+		if p.URL == "" {
+			return fmt.Errorf("image_url part requires url")
+		}
+	case PartImage:
+		if p.Data == "" {
+			return fmt.Errorf("image part requires data")
+		}
+	case PartFile:
+		if p.Data == "" && p.Path == "" {
+			return fmt.Errorf("file part requires data or path")
+		}
+	default:
+		return fmt.Errorf("unknown content part type %q", p.Type)
+	}
+
+	if p.Data != "" {
+		if _, err := base64.StdEncoding.DecodeString(p.Data); err != nil {
+			return fmt.Errorf("%s part data is not valid base64", p.Type)
+		}
+	}
+	return nil
+}
+
+// IsImage reports whether the part is an image
+func (p ContentPart) IsImage() bool {
+	return p.Type == PartImage || p.Type == PartImageURL || strings.HasPrefix(p.MIMEType, "image/")
+}
+
+// DataURI returns the inline data as a data: URI
+func (p ContentPart) DataURI() string {
+	mimeType := p.MIMEType
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	return "data:" + mimeType + ";base64," + p.Data
+}
+
+// fileResolvingLLM inlines file references before calling the backend
+type fileResolvingLLM struct {
+	llm    LLM
+	reader fsx.FileReader
+}
+
+// ResolveFiles wraps an LLM so file parts referencing a Path are read from
+// the file system and sent inline. Files with an image MIME type become image parts
+func ResolveFiles(backend LLM, reader fsx.FileReader) LLM {
+	return &fileResolvingLLM{llm: backend, reader: reader}
+}
+
+func (f *fileResolvingLLM) Chat(ctx context.Context, messages []Message, opts ...Option) (Response, error) {
+	resolved, err := f.resolve(ctx, messages)
+	if err != nil {
+		return Response{}, err
+	}
+	return f.llm.Chat(ctx, resolved, opts...)
+}
+
+func (f *fileResolvingLLM) ChatStream(ctx context.Context, messages []Message, opts ...Option) (Stream, error) {
+	resolved, err := f.resolve(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+	return f.llm.ChatStream(ctx, resolved, opts...)
+}
+
+// resolve returns messages with file references inlined, leaving the input untouched
+func (f *fileResolvingLLM) resolve(ctx context.Context, messages []Message) ([]Message, error) {
+	var result []Message
+	for i, msg := range messages {
+		if !hasFileReference(msg) {
+			continue
+		}
+		if result == nil {
+			result = make([]Message, len(messages))
+			copy(result, messages)
+		}
+
+		parts := make([]ContentPart, len(msg.Parts))
+		for j, part := range msg.Parts {
+			if part.Type == PartFile && part.Data == "" && part.Path != "" {
+				inlined, err := f.readPart(ctx, part)
+				if err != nil {
+					return nil, err
+				}
+				part = inlined
+			}
+			parts[j] = part
+		}
+		result[i].Parts = parts
+	}
+
+	if result == nil {
+		return messages, nil
+	}
+	return result, nil
+}
+
+func (f *fileResolvingLLM) readPart(ctx context.Context, part ContentPart) (ContentPart, error) {
+	data, err := f.reader.ReadFile(ctx, part.Path)
+	if err != nil {
+		return part, fmt.Errorf("read attachment %s: %w", part.Path, err)
+	}
+
+	if part.Filename == "" {
+		part.Filename = path.Base(part.Path)
+	}
+	if part.MIMEType == "" {
+		part.MIMEType = mime.TypeByExtension(path.Ext(part.Path))
+	}
+	if part.MIMEType == "" {
+		part.MIMEType = http.DetectContentType(data)
+	}
+	if mediaType, _, err := mime.ParseMediaType(part.MIMEType); err == nil {
+		part.MIMEType = mediaType // Drop parameters such as charset
+	}
+	if strings.HasPrefix(part.MIMEType, "image/") {
+		part.Type = PartImage
+	}
+	part.Data = base64.StdEncoding.EncodeToString(data)
+	return part, nil
+}
+
+func hasFileReference(msg Message) bool {
+	for _, part := range msg.Parts {
+		if part.Type == PartFile && part.Data == "" && part.Path != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,359 @@
+// Package limitx rate limits LLM and embedding calls with per-model
+// requests-per-minute and tokens-per-minute token buckets.
+package limitx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Abraxas-365/ams/pkg/ai/embedding"
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/tokenizer"
+	"github.com/Abraxas-365/ams/pkg/logx"
+)
+
+// DefaultMaxWait is how long a call may queue for capacity
+const DefaultMaxWait = 30 * time.Second
+
+// Limit is the per-minute budget of a model; zero means unlimited
+type Limit struct {
+	RequestsPerMinute int
+	TokensPerMinute   int
+}
+
+// Store keeps bucket state
+type Store interface {
+	// Reserve takes amount from a bucket holding at most perMinute units and
+	// refilling at perMinute per minute. It returns how long the caller must
+	// wait for the reservation; nothing is taken and ok is false when that
+	// exceeds maxWait. A negative amount returns units to the bucket
+	Reserve(ctx context.Context, key string, perMinute, amount int, maxWait time.Duration) (wait time.Duration, ok bool, err error)
+}
+
+// LimitError is returned when a call would queue past its deadline
+// It reports 429 so failover routers treat it like a provider rate limit
+type LimitError struct {
+	Model string
+	Limit string // rpm or tpm
+	Wait  time.Duration
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("limitx: %s limit for model %q reached (capacity in %s)", e.Limit, e.Model, e.Wait.Round(time.Millisecond))
+}
+
+// HTTPStatus implements llm.StatusError
+func (e *LimitError) HTTPStatus() int {
+	return http.StatusTooManyRequests
+}
+
+// Limiter reserves capacity before calls reach the provider
+type Limiter struct {
+	store     Store
+	namespace string
+	limits    map[string]Limit
+	fallback  Limit
+	maxWait   time.Duration
+	sleep     func(context.Context, time.Duration) error
+}
+
+// Option configures the limiter
+type Option func(*Limiter)
+
+// WithLimit sets the limit for models starting with model; the longest prefix wins
+// so "gpt-5-mini" also covers dated snapshots like "gpt-5-mini-2025-08-07"
+func WithLimit(model string, limit Limit) Option {
+	return func(l *Limiter) {
+		l.limits[model] = limit
+	}
+}
+
+// WithDefaultLimit sets the limit for models without their own
+func WithDefaultLimit(limit Limit) Option {
+	return func(l *Limiter) {
+		l.fallback = limit
+	}
+}
+
+// WithMaxWait sets how long calls queue before failing with a LimitError (default 30s)
+func WithMaxWait(maxWait time.Duration) Option {
+	return func(l *Limiter) {
+		l.maxWait = maxWait
+	}
+}
+
+// WithNamespace separates buckets of different providers (or API keys) in a shared store
+func WithNamespace(namespace string) Option {
+	return func(l *Limiter) {
+		l.namespace = namespace
+	}
+}
+
+// New creates a limiter over a store
+func New(store Store, opts ...Option) *Limiter {
+	limiter := &Limiter{
+		store:     store,
+		namespace: "default",
+		limits:    make(map[string]Limit),
+		maxWait:   DefaultMaxWait,
+		sleep:     sleepContext,
+	}
+	for _, opt := range opts {
+		opt(limiter)
+	}
+	return limiter
+}
+
+// Reservation is capacity taken for one call
+type Reservation struct {
+	limiter *Limiter
+	model   string
+	tokens  int
+}
+
+// Reserve waits until the model has capacity for one request of tokens
+// The wait is bounded by the max wait and the context deadline
+func (l *Limiter) Reserve(ctx context.Context, model string, tokens int) (*Reservation, error) {
+	limit := l.limitFor(model)
+	reservation := &Reservation{limiter: l, model: model}
+
+	maxWait := l.maxWait
+	if deadline, ok := ctx.Deadline(); ok {
+		maxWait = min(maxWait, time.Until(deadline))
+	}
+
+	requestWait, err := l.take(ctx, model, "rpm", limit.RequestsPerMinute, 1, maxWait)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenWait, err := l.take(ctx, model, "tpm", limit.TokensPerMinute, tokens, maxWait)
+	if err != nil {
+		l.give(model, "rpm", limit.RequestsPerMinute, 1)
+		return nil, err
+	}
+	reservation.tokens = tokens
+
+	if wait := max(requestWait, tokenWait); wait > 0 {
+		logx.WithFields(logx.Fields{
+			"model":  model,
+			"tokens": tokens,
+			"wait":   wait.String(),
+		}).Debug("Queueing LLM call for rate limit")
+
+		if err := l.sleep(ctx, wait); err != nil {
+			reservation.Cancel()
+			return nil, err
+		}
+	}
+	return reservation, nil
+}
+
+// Settle corrects the token reservation with the actual usage
+func (r *Reservation) Settle(actualTokens int) {
+	if r == nil || actualTokens <= 0 || actualTokens == r.tokens {
+		return
+	}
+	limit := r.limiter.limitFor(r.model)
+	r.limiter.give(r.model, "tpm", limit.TokensPerMinute, r.tokens-actualTokens)
+	r.tokens = actualTokens
+}
+
+// Cancel returns the reserved capacity, e.g. when the call never happened
+func (r *Reservation) Cancel() {
+	if r == nil {
+		return
+	}
+	limit := r.limiter.limitFor(r.model)
+	r.limiter.give(r.model, "rpm", limit.RequestsPerMinute, 1)
+	r.limiter.give(r.model, "tpm", limit.TokensPerMinute, r.tokens)
+	r.tokens = 0
+}
+
+func (l *Limiter) limitFor(model string) Limit {
+	if limit, ok := l.limits[model]; ok {
+		return limit
+	}
+
+	limit, bestPrefix := l.fallback, ""
+	for prefix, candidate := range l.limits {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(bestPrefix) {
+			limit, bestPrefix = candidate, prefix
+		}
+	}
+	return limit
+}
+
+// take reserves amount from a bucket; store failures let the call through
+func (l *Limiter) take(ctx context.Context, model, kind string, perMinute, amount int, maxWait time.Duration) (time.Duration, error) {
+	if perMinute <= 0 || amount <= 0 {
+		return 0, nil
+	}
+
+	wait, ok, err := l.store.Reserve(ctx, l.key(model, kind), perMinute, amount, maxWait)
+	if err != nil {
+		logx.WithField("model", model).WithError(err).Warn("Rate limit store unavailable, allowing call")
+		return 0, nil
+	}
+	if !ok {
+		return 0, &LimitError{Model: model, Limit: kind, Wait: wait}
+	}
+	return wait, nil
+}
+
+// give returns amount to a bucket (a negative amount takes without waiting)
+func (l *Limiter) give(model, kind string, perMinute, amount int) {
+	if perMinute <= 0 || amount == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, _, err := l.store.Reserve(ctx, l.key(model, kind), perMinute, -amount, time.Duration(1<<62)); err != nil {
+		logx.WithField("model", model).WithError(err).Warn("Failed to return rate limit capacity")
+	}
+}
+
+func (l *Limiter) key(model, kind string) string {
+	return "ratelimit:" + l.namespace + ":" + model + ":" + kind
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// ============================================================================
+// LLM decorator
+// ============================================================================
+
+// Wrap returns an LLM whose calls wait for rate limit capacity
+// Tokens are estimated from the prompt plus the completion budget and
+// corrected with the reported usage
+func (l *Limiter) Wrap(backend llm.LLM) llm.LLM {
+	return &limitedLLM{limiter: l, llm: backend}
+}
+
+type limitedLLM struct {
+	limiter *Limiter
+	llm     llm.LLM
+}
+
+func (m *limitedLLM) Chat(ctx context.Context, messages []llm.Message, opts ...llm.Option) (llm.Response, error) {
+	reservation, err := m.reserve(ctx, messages, opts)
+	if err != nil {
+		return llm.Response{}, err
+	}
+
+	response, err := m.llm.Chat(ctx, messages, opts...)
+	if err == nil {
+		reservation.Settle(response.Usage.TotalTokens)
+	}
+	return response, err
+}
+
+func (m *limitedLLM) ChatStream(ctx context.Context, messages []llm.Message, opts ...llm.Option) (llm.Stream, error) {
+	reservation, err := m.reserve(ctx, messages, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := m.llm.ChatStream(ctx, messages, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &settlingStream{Stream: stream, reservation: reservation}, nil
+}
+
+// settlingStream settles the reservation with the usage on the final message
+// Streams ending without usage (or with an error) keep the estimate
+type settlingStream struct {
+	llm.Stream
+	reservation *Reservation
+}
+
+func (s *settlingStream) Next() (llm.Message, error) {
+	msg, err := s.Stream.Next()
+	if err == io.EOF {
+		if usage, ok := llm.StreamUsage(msg); ok {
+			s.reservation.Settle(usage.TotalTokens)
+		}
+	}
+	return msg, err
+}
+
+func (m *limitedLLM) reserve(ctx context.Context, messages []llm.Message, opts []llm.Option) (*Reservation, error) {
+	options := llm.DefaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	// Providers count the completion budget against TPM up front
+	tokens := tokenizer.ForModel(options.Model).CountMessages(messages, options.Tools)
+	tokens += max(options.MaxCompletionTokens, options.MaxTokens)
+	return m.limiter.Reserve(ctx, options.Model, tokens)
+}
+
+// ============================================================================
+// Embedder decorator
+// ============================================================================
+
+// WrapEmbedder returns an embedder whose calls wait for rate limit capacity
+func (l *Limiter) WrapEmbedder(embedder embedding.Embedder) embedding.Embedder {
+	return &limitedEmbedder{limiter: l, embedder: embedder}
+}
+
+type limitedEmbedder struct {
+	limiter  *Limiter
+	embedder embedding.Embedder
+}
+
+func (e *limitedEmbedder) EmbedDocuments(ctx context.Context, documents []string, opts ...embedding.Option) ([]embedding.Embedding, error) {
+	reservation, err := e.reserve(ctx, documents, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	embeddings, err := e.embedder.EmbedDocuments(ctx, documents, opts...)
+	if err == nil && len(embeddings) > 0 {
+		reservation.Settle(embeddings[0].Usage.TotalTokens)
+	}
+	return embeddings, err
+}
+
+func (e *limitedEmbedder) EmbedQuery(ctx context.Context, text string, opts ...embedding.Option) (embedding.Embedding, error) {
+	reservation, err := e.reserve(ctx, []string{text}, opts)
+	if err != nil {
+		return embedding.Embedding{}, err
+	}
+
+	result, err := e.embedder.EmbedQuery(ctx, text, opts...)
+	if err == nil {
+		reservation.Settle(result.Usage.TotalTokens)
+	}
+	return result, err
+}
+
+func (e *limitedEmbedder) reserve(ctx context.Context, documents []string, opts []embedding.Option) (*Reservation, error) {
+	options := &embedding.EmbeddingOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	counter := tokenizer.ForModel(options.Model)
+	tokens := 0
+	for _, document := range documents {
+		tokens += counter.CountText(document)
+	}
+	return e.limiter.Reserve(ctx, options.Model, tokens)
+}
@@ -0,0 +1,90 @@
+package limitx
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/llmtest"
+)
+
+// recordingStore records reservations and always grants them
+type recordingStore struct {
+	mu      sync.Mutex
+	amounts map[string][]int
+}
+
+func newRecordingStore() *recordingStore {
+	return &recordingStore{amounts: make(map[string][]int)}
+}
+
+func (s *recordingStore) Reserve(_ context.Context, key string, _, amount int, _ time.Duration) (time.Duration, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.amounts[key] = append(s.amounts[key], amount)
+	return 0, true, nil
+}
+
+func (s *recordingStore) reserved(key string) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.amounts[key]
+}
+
+func TestLimitForPrefersLongestPrefix(t *testing.T) {
+	limiter := New(newRecordingStore(),
+		WithDefaultLimit(Limit{RequestsPerMinute: 1}),
+		WithLimit("gpt-5", Limit{RequestsPerMinute: 2}),
+		WithLimit("gpt-5-mini", Limit{RequestsPerMinute: 3}),
+	)
+
+	tests := []struct {
+		model string
+		want  int
+	}{
+		{"gpt-5-mini", 3},
+		{"gpt-5-mini-2025-08-07", 3},
+		{"gpt-5-nano", 2},
+		{"gpt-4o", 1},
+		{"", 1},
+	}
+	for _, tt := range tests {
+		if got := limiter.limitFor(tt.model).RequestsPerMinute; got != tt.want {
+			t.Errorf("limitFor(%q) = %d, want %d", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestStreamSettlesWithReportedUsage(t *testing.T) {
+	store := newRecordingStore()
+	limiter := New(store, WithLimit("gpt-5-mini", Limit{TokensPerMinute: 10_000}))
+
+	fake := llmtest.New()
+	fake.On().Reply("Hello there").WithUsage(llm.Usage{PromptTokens: 5, CompletionTokens: 2, TotalTokens: 7})
+	limited := llm.Adapt(limiter.Wrap(fake), llm.AllCapabilities(), llm.WithModel("gpt-5-mini-2025-08-07"))
+
+	stream, err := limited.ChatStream(context.Background(), []llm.Message{llm.NewUserMessage("Hi")}, llm.WithMaxCompletionTokens(100))
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	defer stream.Close()
+	for {
+		if _, err := stream.Next(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+	}
+
+	// The estimate is taken up front, then the difference to the usage is returned
+	amounts := store.reserved(limiter.key("gpt-5-mini-2025-08-07", "tpm"))
+	if len(amounts) != 2 {
+		t.Fatalf("tpm reservations = %v, want reserve and settle", amounts)
+	}
+	if amounts[0] <= 100 || amounts[0]+amounts[1] != 7 {
+		t.Errorf("tpm reservations = %v, want a net of 7 tokens", amounts)
+	}
+}
@@ -0,0 +1,113 @@
+package limitx
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ============================================================================
+// In-memory store
+// ============================================================================
+
+// MemoryStore keeps buckets in process; each replica gets its own budget
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens  float64 // Negative while callers wait for reserved capacity
+	updated time.Time
+}
+
+// NewMemoryStore creates an in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (s *MemoryStore) Reserve(ctx context.Context, key string, perMinute, amount int, maxWait time.Duration) (time.Duration, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	capacity := float64(perMinute)
+	perSecond := capacity / 60
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*perSecond)
+	b.updated = now
+
+	remaining := b.tokens - float64(amount)
+	wait := time.Duration(0)
+	if remaining < 0 {
+		wait = time.Duration(math.Ceil(-remaining / perSecond * float64(time.Second)))
+	}
+	if wait > maxWait {
+		return wait, false, nil
+	}
+
+	b.tokens = math.Min(capacity, remaining)
+	return wait, true, nil
+}
+
+// ============================================================================
+// Redis store
+// ============================================================================
+
+// reserveScript is MemoryStore.Reserve run atomically in Redis, on the Redis
+// clock so replicas with skewed clocks share one bucket
+var reserveScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local amount = tonumber(ARGV[2])
+local max_wait = tonumber(ARGV[3])
+local per_ms = capacity / 60000
+
+local clock = redis.call('TIME')
+local now = tonumber(clock[1]) * 1000 + math.floor(tonumber(clock[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(state[1]) or capacity
+local updated = tonumber(state[2]) or now
+tokens = math.min(capacity, tokens + (now - updated) * per_ms)
+
+local remaining = tokens - amount
+local wait = 0
+if remaining < 0 then
+	wait = math.ceil(-remaining / per_ms)
+end
+if wait > max_wait then
+	return {0, wait}
+end
+
+remaining = math.min(capacity, remaining)
+redis.call('HSET', KEYS[1], 'tokens', tostring(remaining), 'updated', now)
+redis.call('PEXPIRE', KEYS[1], 60000 + wait)
+return {1, wait}
+`)
+
+// RedisStore shares buckets across server replicas
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore creates a Redis-backed store
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) Reserve(ctx context.Context, key string, perMinute, amount int, maxWait time.Duration) (time.Duration, bool, error) {
+	result, err := reserveScript.Run(ctx, s.client, []string{key}, perMinute, amount, maxWait.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, false, err
+	}
+	return time.Duration(result[1]) * time.Millisecond, result[0] == 1, nil
+}
@@ -2,6 +2,8 @@ package llm
 
 import (
 	"context"
+	"errors"
+	"maps"
 )
 
 // LLM represents a generic large language model interface
@@ -15,20 +17,71 @@ type LLM interface {
 
 // Response contains the model's response and additional metadata
 type Response struct {
-	Message Message
-	Usage   Usage
+	Message  Message
+	Usage    Usage
+	Metadata map[string]any // Set by wrappers, e.g. which backend answered
+}
+
+// StatusError is implemented by provider errors that carry the HTTP status of the failed call
+type StatusError interface {
+	error
+	HTTPStatus() int
+}
+
+// HTTPStatus returns the HTTP status carried by err, or 0 when there is none
+func HTTPStatus(err error) int {
+	var statusErr StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.HTTPStatus()
+	}
+	return 0
 }
 
 // Stream represents a streaming response
 type Stream interface {
-	// Next returns the next chunk of the stream
-	// Returns io.EOF when the stream is complete
+	// Next returns the next chunk of the stream (a content delta)
+	// Returns io.EOF when the stream is complete, optionally together with
+	// the fully assembled message including tool calls and usage (see StreamUsage)
 	Next() (Message, error)
 
 	// Close closes the stream
 	Close() error
 }
 
+// MetadataUsage is the Metadata key streams use to report token usage on the
+// assembled message returned with io.EOF
+const MetadataUsage = "usage"
+
+// StreamUsage returns the token usage reported on an assembled stream message
+func StreamUsage(message Message) (Usage, bool) {
+	usage, ok := message.Metadata[MetadataUsage].(Usage)
+	return usage, ok
+}
+
+// WithStreamUsage returns a copy of message reporting usage
+func WithStreamUsage(message Message, usage Usage) Message {
+	metadata := make(map[string]any, len(message.Metadata)+1)
+	maps.Copy(metadata, message.Metadata)
+	metadata[MetadataUsage] = usage
+	message.Metadata = metadata
+	return message
+}
+
+// WithoutStreamUsage returns a copy of message without the reported usage,
+// before it is stored
+func WithoutStreamUsage(message Message) Message {
+	if _, ok := message.Metadata[MetadataUsage]; !ok {
+		return message
+	}
+	metadata := maps.Clone(message.Metadata)
+	delete(metadata, MetadataUsage)
+	if len(metadata) == 0 {
+		metadata = nil
+	}
+	message.Metadata = metadata
+	return message
+}
+
 // Client represents a configured LLM client
 type Client struct {
 	llm LLM
@@ -0,0 +1,403 @@
+// Package llmtest provides a scripted llm.LLM for offline tests.
+//
+// Rules are checked in the order they were added; the first matching rule
+// with uses left answers the request:
+//
+//	fake := llmtest.New()
+//	fake.On(llmtest.LastUserContains("weather")).Once().ReplyToolCall("get_weather", map[string]any{"city": "Lima"})
+//	fake.On(llmtest.LastMessageRole(llm.RoleTool)).Reply("It is sunny in Lima")
+//
+//	agent := agentx.New(*llm.NewClient(fake), memory, agentx.WithTools(tools))
+//	...
+//	fake.Requests() // every call, for assertions
+package llmtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+)
+
+// DefaultChunkSize is the number of runes per streamed delta
+const DefaultChunkSize = 4
+
+// Request is a recorded call
+type Request struct {
+	Messages []llm.Message
+	Options  *llm.ChatOptions // Resolved options, starting from llm.DefaultOptions
+	Stream   bool
+}
+
+// LastMessage returns the last message of the request
+func (r Request) LastMessage() llm.Message {
+	if len(r.Messages) == 0 {
+		return llm.Message{}
+	}
+	return r.Messages[len(r.Messages)-1]
+}
+
+// Fake is a scripted llm.LLM. It is safe for concurrent use
+type Fake struct {
+	mu        sync.Mutex
+	rules     []*Rule
+	requests  []Request
+	chunkSize int
+	callIDs   int
+}
+
+var _ llm.LLM = (*Fake)(nil)
+
+// Option configures the fake
+type Option func(*Fake)
+
+// WithChunkSize sets how many runes each streamed delta carries (default 4)
+func WithChunkSize(runes int) Option {
+	return func(f *Fake) {
+		f.chunkSize = runes
+	}
+}
+
+// New creates a fake without rules; unmatched requests fail with ErrNoMatch
+func New(opts ...Option) *Fake {
+	fake := &Fake{chunkSize: DefaultChunkSize}
+	for _, opt := range opts {
+		opt(fake)
+	}
+	return fake
+}
+
+// Client returns the fake wrapped in an llm.Client
+func (f *Fake) Client() llm.Client {
+	return *llm.NewClient(f)
+}
+
+// On adds a rule answering requests matched by every matcher
+func (f *Fake) On(matchers ...Matcher) *Rule {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	rule := &Rule{fake: f, matchers: matchers, times: -1}
+	f.rules = append(f.rules, rule)
+	return rule
+}
+
+// Requests returns every recorded call in order
+func (f *Fake) Requests() []Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Request(nil), f.requests...)
+}
+
+// LastRequest returns the most recent call
+func (f *Fake) LastRequest() (Request, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.requests) == 0 {
+		return Request{}, false
+	}
+	return f.requests[len(f.requests)-1], true
+}
+
+// CallCount returns the number of recorded calls
+func (f *Fake) CallCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.requests)
+}
+
+// Reset drops rules and recorded calls
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = nil
+	f.requests = nil
+	f.callIDs = 0
+}
+
+// Chat implements the LLM interface
+func (f *Fake) Chat(ctx context.Context, messages []llm.Message, opts ...llm.Option) (llm.Response, error) {
+	if err := ctx.Err(); err != nil {
+		return llm.Response{}, err
+	}
+
+	rule, request, err := f.match(messages, opts, false)
+	if err != nil {
+		return llm.Response{}, err
+	}
+
+	reply, err := rule.reply(request)
+	if err != nil {
+		return llm.Response{}, err
+	}
+	return llm.Response{Message: reply.Message, Usage: reply.Usage}, nil
+}
+
+// ChatStream implements the LLM interface
+// Content is split into chunks of the configured size (or the rule's chunks);
+// the assembled message, with tool calls, comes with io.EOF
+func (f *Fake) ChatStream(ctx context.Context, messages []llm.Message, opts ...llm.Option) (llm.Stream, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rule, request, err := f.match(messages, opts, true)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := rule.reply(request)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := reply.Chunks
+	if chunks == nil {
+		chunks = splitRunes(reply.Message.Content, f.chunkSize)
+	}
+	message := reply.Message
+	if reply.Usage != (llm.Usage{}) {
+		message = llm.WithStreamUsage(message, reply.Usage)
+	}
+	return &stream{ctx: ctx, message: message, chunks: chunks, failAfter: reply.failAfter, failErr: reply.failErr}, nil
+}
+
+// match records the request and returns the first matching rule
+func (f *Fake) match(messages []llm.Message, opts []llm.Option, streaming bool) (*Rule, Request, error) {
+	options := llm.DefaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	request := Request{
+		Messages: append([]llm.Message(nil), messages...),
+		Options:  options,
+		Stream:   streaming,
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, request)
+	for _, rule := range f.rules {
+		if rule.times == 0 || !rule.matches(request) {
+			continue
+		}
+		if rule.times > 0 {
+			rule.times--
+		}
+		return rule, request, nil
+	}
+
+	return nil, request, &NoMatchError{Request: request}
+}
+
+// nextCallID returns a deterministic tool call ID
+func (f *Fake) nextCallID() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.callIDs++
+	return fmt.Sprintf("call_%d", f.callIDs)
+}
+
+// ErrNoMatch is matched by errors.Is for requests no rule answers
+var ErrNoMatch = errors.New("llmtest: no rule matched the request")
+
+// NoMatchError is returned when no rule answers a request
+type NoMatchError struct {
+	Request Request
+}
+
+func (e *NoMatchError) Error() string {
+	last := e.Request.LastMessage()
+	return fmt.Sprintf("%s (last message %s: %q)", ErrNoMatch.Error(), last.Role, last.Content)
+}
+
+func (e *NoMatchError) Is(target error) bool {
+	return target == ErrNoMatch
+}
+
+// ============================================================================
+// Rules
+// ============================================================================
+
+// Reply is what a rule answers with
+type Reply struct {
+	Message llm.Message
+	Usage   llm.Usage
+	Chunks  []string // Streamed deltas; defaults to Content split by the chunk size
+
+	failAfter int
+	failErr   error
+}
+
+// ReplyFunc builds a reply from the request
+type ReplyFunc func(req Request) (Reply, error)
+
+// Rule answers matching requests
+type Rule struct {
+	fake      *Fake
+	matchers  []Matcher
+	times     int // Remaining uses, -1 for unlimited
+	respond   ReplyFunc
+	usage     *llm.Usage
+	chunks    []string
+	failAfter int
+	failErr   error
+}
+
+// Times limits how many requests the rule answers
+func (r *Rule) Times(n int) *Rule {
+	r.fake.mu.Lock()
+	defer r.fake.mu.Unlock()
+	r.times = n
+	return r
+}
+
+// Once limits the rule to a single request
+func (r *Rule) Once() *Rule {
+	return r.Times(1)
+}
+
+// WithUsage sets the reported token usage
+func (r *Rule) WithUsage(usage llm.Usage) *Rule {
+	r.usage = &usage
+	return r
+}
+
+// WithChunks streams the reply as exactly these deltas
+func (r *Rule) WithChunks(chunks ...string) *Rule {
+	r.chunks = chunks
+	return r
+}
+
+// FailStreamAfter makes the stream return err after n deltas
+func (r *Rule) FailStreamAfter(n int, err error) *Rule {
+	r.failAfter = n
+	r.failErr = err
+	return r
+}
+
+// Reply answers with an assistant message
+func (r *Rule) Reply(content string) *Rule {
+	return r.ReplyMessage(llm.NewAssistantMessage(content))
+}
+
+// ReplyJSON answers with v encoded as JSON, e.g. for structured responses
+func (r *Rule) ReplyJSON(v any) *Rule {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return r.ReplyError(err)
+	}
+	return r.Reply(string(data))
+}
+
+// ReplyMessage answers with msg
+func (r *Rule) ReplyMessage(msg llm.Message) *Rule {
+	return r.ReplyWith(func(Request) (Reply, error) {
+		return Reply{Message: msg}, nil
+	})
+}
+
+// ReplyToolCall answers with a call to a tool; args may be a JSON string or any
+// value encoded as JSON. Call IDs are call_1, call_2, ... in call order
+func (r *Rule) ReplyToolCall(name string, args any) *Rule {
+	return r.ReplyToolCalls(ToolCall(name, args))
+}
+
+// ReplyToolCalls answers with parallel tool calls
+func (r *Rule) ReplyToolCalls(calls ...Call) *Rule {
+	return r.ReplyWith(func(Request) (Reply, error) {
+		msg := llm.Message{Role: llm.RoleAssistant}
+		for _, call := range calls {
+			arguments, err := call.arguments()
+			if err != nil {
+				return Reply{}, err
+			}
+			msg.ToolCalls = append(msg.ToolCalls, llm.ToolCall{
+				ID:   r.fake.nextCallID(),
+				Type: "function",
+				Function: llm.FunctionCall{
+					Name:      call.Name,
+					Arguments: arguments,
+				},
+			})
+		}
+		return Reply{Message: msg}, nil
+	})
+}
+
+// ReplyError fails matching requests with err (e.g. a status error to test failover)
+func (r *Rule) ReplyError(err error) *Rule {
+	return r.ReplyWith(func(Request) (Reply, error) {
+		return Reply{}, err
+	})
+}
+
+// ReplyWith answers with a function of the request
+func (r *Rule) ReplyWith(fn ReplyFunc) *Rule {
+	r.respond = fn
+	return r
+}
+
+func (r *Rule) matches(req Request) bool {
+	for _, matcher := range r.matchers {
+		if !matcher(req) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Rule) reply(req Request) (Reply, error) {
+	if r.respond == nil {
+		return Reply{}, fmt.Errorf("llmtest: rule has no reply")
+	}
+
+	reply, err := r.respond(req)
+	if err != nil {
+		return Reply{}, err
+	}
+	if reply.Message.Role == "" {
+		reply.Message.Role = llm.RoleAssistant
+	}
+	if r.usage != nil {
+		reply.Usage = *r.usage
+	}
+	if r.chunks != nil {
+		reply.Chunks = r.chunks
+	}
+	reply.failAfter, reply.failErr = r.failAfter, r.failErr
+	return reply, nil
+}
+
+// Call is a scripted tool call
+type Call struct {
+	Name string
+	Args any
+}
+
+// ToolCall creates a scripted tool call
+func ToolCall(name string, args any) Call {
+	return Call{Name: name, Args: args}
+}
+
+func (c Call) arguments() (string, error) {
+	switch args := c.Args.(type) {
+	case nil:
+		return "{}", nil
+	case string:
+		return args, nil
+	case []byte:
+		return string(args), nil
+	}
+
+	data, err := json.Marshal(c.Args)
+	if err != nil {
+		return "", fmt.Errorf("llmtest: encode %s arguments: %w", c.Name, err)
+	}
+	return string(data), nil
+}
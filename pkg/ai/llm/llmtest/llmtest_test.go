@@ -0,0 +1,145 @@
+package llmtest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/agentx"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/llmtest"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/toolx"
+)
+
+type weatherTool struct {
+	inputs []string
+}
+
+func (t *weatherTool) Call(ctx context.Context, inputs string) (any, error) {
+	t.inputs = append(t.inputs, inputs)
+	return "sunny", nil
+}
+
+func (t *weatherTool) Name() string {
+	return "get_weather"
+}
+
+func (t *weatherTool) GetTool() llm.Tool {
+	return llm.Tool{Type: "function", Function: llm.Function{Name: "get_weather", Parameters: map[string]any{"type": "object"}}}
+}
+
+func TestAgentToolLoop(t *testing.T) {
+	fake := llmtest.New()
+	fake.On(llmtest.LastUserContains("weather"), llmtest.HasTool("get_weather")).Once().
+		ReplyToolCall("get_weather", map[string]any{"city": "Lima"})
+	fake.On(llmtest.AfterToolResult("get_weather")).Reply("It is sunny in Lima")
+
+	tool := &weatherTool{}
+	memory := memoryx.NewBufferMemory(llm.NewSystemMessage("You are helpful"))
+	agent := agentx.New(fake.Client(), memory, agentx.WithTools(toolx.FromToolx(tool)))
+
+	output, err := agent.Run(context.Background(), "What's the weather in Lima?")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if output != "It is sunny in Lima" {
+		t.Errorf("output = %q", output)
+	}
+	if len(tool.inputs) != 1 || tool.inputs[0] != `{"city":"Lima"}` {
+		t.Errorf("tool inputs = %v", tool.inputs)
+	}
+
+	requests := fake.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("requests = %d, want 2", len(requests))
+	}
+	last := requests[1].LastMessage()
+	if last.Role != llm.RoleTool || last.ToolCallID != "call_1" || last.Content != "sunny" {
+		t.Errorf("tool result = %+v", last)
+	}
+}
+
+func TestStreamChunking(t *testing.T) {
+	fake := llmtest.New(llmtest.WithChunkSize(3))
+	fake.On(llmtest.Streaming()).Reply("héllo world")
+
+	stream, err := fake.ChatStream(context.Background(), []llm.Message{llm.NewUserMessage("hi")})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	defer stream.Close()
+
+	var deltas []string
+	for {
+		msg, err := stream.Next()
+		if err == io.EOF {
+			if msg.Content != "héllo world" {
+				t.Errorf("assembled = %q", msg.Content)
+			}
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		deltas = append(deltas, msg.Content)
+	}
+
+	if got := strings.Join(deltas, "|"); got != "hél|lo |wor|ld" {
+		t.Errorf("deltas = %q", got)
+	}
+}
+
+func TestStreamFailure(t *testing.T) {
+	boom := errors.New("connection reset")
+	fake := llmtest.New()
+	fake.On(llmtest.Any()).WithChunks("a", "b", "c").FailStreamAfter(2, boom).Reply("abc")
+
+	stream, _ := fake.ChatStream(context.Background(), nil)
+	for i := 0; i < 2; i++ {
+		if _, err := stream.Next(); err != nil {
+			t.Fatalf("Next %d: %v", i, err)
+		}
+	}
+	if _, err := stream.Next(); !errors.Is(err, boom) {
+		t.Errorf("err = %v, want %v", err, boom)
+	}
+}
+
+func TestRuleOrderAndExhaustion(t *testing.T) {
+	fake := llmtest.New()
+	fake.On(llmtest.Any()).Times(2).Reply("first")
+	fake.On(llmtest.Any()).WithUsage(llm.Usage{TotalTokens: 7}).Reply("second")
+
+	ctx := context.Background()
+	want := []string{"first", "first", "second"}
+	for i, expected := range want {
+		response, err := fake.Chat(ctx, []llm.Message{llm.NewUserMessage("hi")})
+		if err != nil {
+			t.Fatalf("Chat %d: %v", i, err)
+		}
+		if response.Message.Content != expected {
+			t.Errorf("Chat %d = %q, want %q", i, response.Message.Content, expected)
+		}
+	}
+	if fake.CallCount() != 3 {
+		t.Errorf("CallCount = %d", fake.CallCount())
+	}
+}
+
+func TestNoMatch(t *testing.T) {
+	fake := llmtest.New()
+	fake.On(llmtest.LastUserContains("weather")).Reply("sunny")
+
+	_, err := fake.Chat(context.Background(), []llm.Message{llm.NewUserMessage("hello")}, llm.WithModel("gpt-4o"))
+	if !errors.Is(err, llmtest.ErrNoMatch) {
+		t.Fatalf("err = %v, want ErrNoMatch", err)
+	}
+
+	request, ok := fake.LastRequest()
+	if !ok || request.Options.Model != "gpt-4o" {
+		t.Errorf("recorded request = %+v", request)
+	}
+}
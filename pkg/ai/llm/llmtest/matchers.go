@@ -0,0 +1,126 @@
+package llmtest
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+)
+
+// Matcher reports whether a rule applies to a request
+type Matcher func(req Request) bool
+
+// Any matches every request
+func Any() Matcher {
+	return func(Request) bool { return true }
+}
+
+// Streaming matches ChatStream calls
+func Streaming() Matcher {
+	return func(req Request) bool { return req.Stream }
+}
+
+// LastMessageRole matches when the last message has role
+func LastMessageRole(role string) Matcher {
+	return func(req Request) bool {
+		return req.LastMessage().Role == role
+	}
+}
+
+// LastUserContains matches when the latest user message contains substr
+func LastUserContains(substr string) Matcher {
+	return func(req Request) bool {
+		msg, ok := lastWithRole(req.Messages, llm.RoleUser)
+		return ok && strings.Contains(msg.Content, substr)
+	}
+}
+
+// LastUserMatches matches when the latest user message matches the regular expression
+func LastUserMatches(pattern string) Matcher {
+	re := regexp.MustCompile(pattern)
+	return func(req Request) bool {
+		msg, ok := lastWithRole(req.Messages, llm.RoleUser)
+		return ok && re.MatchString(msg.Content)
+	}
+}
+
+// SystemContains matches when a system message contains substr
+func SystemContains(substr string) Matcher {
+	return func(req Request) bool {
+		for _, msg := range req.Messages {
+			if msg.Role == llm.RoleSystem && strings.Contains(msg.Content, substr) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AfterToolResult matches when the request ends with results of a call to the named tool
+func AfterToolResult(name string) Matcher {
+	return func(req Request) bool {
+		names := toolCallNames(req.Messages)
+		for i := len(req.Messages) - 1; i >= 0 && req.Messages[i].Role == llm.RoleTool; i-- {
+			if names[req.Messages[i].ToolCallID] == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// HasTool matches when the named tool is offered to the model
+func HasTool(name string) Matcher {
+	return func(req Request) bool {
+		for _, tool := range req.Options.Tools {
+			if tool.Function.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Model matches the requested model
+func Model(model string) Matcher {
+	return func(req Request) bool {
+		return req.Options.Model == model
+	}
+}
+
+// Not inverts a matcher
+func Not(matcher Matcher) Matcher {
+	return func(req Request) bool { return !matcher(req) }
+}
+
+// AnyOf matches when at least one matcher does
+func AnyOf(matchers ...Matcher) Matcher {
+	return func(req Request) bool {
+		for _, matcher := range matchers {
+			if matcher(req) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func lastWithRole(messages []llm.Message, role string) (llm.Message, bool) {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == role {
+			return messages[i], true
+		}
+	}
+	return llm.Message{}, false
+}
+
+// toolCallNames maps tool call IDs to the called tool
+func toolCallNames(messages []llm.Message) map[string]string {
+	names := make(map[string]string)
+	for _, msg := range messages {
+		for _, tc := range msg.ToolCalls {
+			names[tc.ID] = tc.Function.Name
+		}
+	}
+	return names
+}
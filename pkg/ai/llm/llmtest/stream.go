@@ -0,0 +1,61 @@
+package llmtest
+
+import (
+	"context"
+	"io"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+)
+
+// stream yields scripted deltas, then the assembled message (with the reply's
+// usage) with io.EOF
+type stream struct {
+	ctx       context.Context
+	message   llm.Message
+	chunks    []string
+	sent      int
+	failAfter int
+	failErr   error
+	closed    bool
+}
+
+func (s *stream) Next() (llm.Message, error) {
+	if s.closed {
+		return llm.Message{}, io.ErrClosedPipe
+	}
+	if err := s.ctx.Err(); err != nil {
+		return llm.Message{}, err
+	}
+	if s.failErr != nil && s.sent >= s.failAfter {
+		return llm.Message{}, s.failErr
+	}
+	if s.sent < len(s.chunks) {
+		chunk := s.chunks[s.sent]
+		s.sent++
+		return llm.Message{Role: llm.RoleAssistant, Content: chunk}, nil
+	}
+	return s.message, io.EOF
+}
+
+func (s *stream) Close() error {
+	s.closed = true
+	return nil
+}
+
+// splitRunes splits text into pieces of size runes
+func splitRunes(text string, size int) []string {
+	if text == "" {
+		return []string{}
+	}
+	if size <= 0 {
+		return []string{text}
+	}
+
+	runes := []rune(text)
+	chunks := make([]string, 0, len(runes)/size+1)
+	for start := 0; start < len(runes); start += size {
+		end := min(start+size, len(runes))
+		chunks = append(chunks, string(runes[start:end]))
+	}
+	return chunks
+}
@@ -69,6 +69,10 @@ func (m *BufferMemory) Add(message llm.Message) error {
 	if m.maxMessages > 0 && len(m.messages) > m.maxMessages {
 		// Keep the most recent messages
 		trimCount := len(m.messages) - m.maxMessages
+		// Tool results are useless (and rejected by providers) without their call
+		for trimCount < len(m.messages)-1 && m.messages[trimCount].Role == llm.RoleTool {
+			trimCount++
+		}
 		m.messages = m.messages[trimCount:]
 	}
 
@@ -1,8 +1,10 @@
 package memoryx
 
 import (
-	"github.com/Abraxas-365/ams/pkg/errx"
+	"errors"
 	"net/http"
+
+	"github.com/Abraxas-365/ams/pkg/errx"
 )
 
 var errRegistry = errx.NewRegistry("MEMORY")
@@ -22,6 +24,13 @@ var (
 		"Session is inactive",
 	)
 
+	ErrCodeSessionFlushConflict = errRegistry.Register(
+		"SESSION_FLUSH_CONFLICT",
+		errx.TypeConflict,
+		http.StatusConflict,
+		"Session kept changing while it was being flushed",
+	)
+
 	ErrCodeMessageSerializationFailed = errRegistry.Register(
 		"MESSAGE_SERIALIZATION_FAILED",
 		errx.TypeInternal,
@@ -38,6 +47,16 @@ func ErrSessionInactive() *errx.Error {
 	return errRegistry.New(ErrCodeSessionInactive)
 }
 
+func ErrSessionFlushConflict() *errx.Error {
+	return errRegistry.New(ErrCodeSessionFlushConflict)
+}
+
 func ErrMessageSerializationFailed(cause error) *errx.Error {
 	return errRegistry.NewWithCause(ErrCodeMessageSerializationFailed, cause)
 }
+
+// IsSessionNotFound reports whether err is a session not found error
+func IsSessionNotFound(err error) bool {
+	var e *errx.Error
+	return errors.As(err, &e) && e.Code == ErrCodeSessionNotFound.Code
+}
@@ -0,0 +1,183 @@
+package memoryinfra
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx"
+	"github.com/Abraxas-365/ams/pkg/logx"
+)
+
+// InMemorySessionRepository keeps sessions in process memory, for tests and
+// local runs; everything is lost on restart
+type InMemorySessionRepository struct {
+	mu            sync.RWMutex
+	sessions      map[memoryx.SessionID]memoryx.Session
+	messages      map[memoryx.SessionID][]memoryx.SessionMessage
+	nextMessageID int64
+}
+
+func NewInMemorySessionRepository() memoryx.SessionRepository {
+	logx.Info("In-memory session repository initialized")
+	return &InMemorySessionRepository{
+		sessions: make(map[memoryx.SessionID]memoryx.Session),
+		messages: make(map[memoryx.SessionID][]memoryx.SessionMessage),
+	}
+}
+
+// CreateSession creates a new session
+func (r *InMemorySessionRepository) CreateSession(ctx context.Context, session *memoryx.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+// GetSession retrieves a session by ID
+func (r *InMemorySessionRepository) GetSession(ctx context.Context, sessionID memoryx.SessionID) (*memoryx.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, ok := r.sessions[sessionID]
+	if !ok {
+		return nil, memoryx.ErrSessionNotFound()
+	}
+	return &session, nil
+}
+
+// GetSessionWithMessages retrieves session with all messages
+func (r *InMemorySessionRepository) GetSessionWithMessages(ctx context.Context, sessionID memoryx.SessionID) (*memoryx.SessionWithMessages, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, ok := r.sessions[sessionID]
+	if !ok {
+		return nil, memoryx.ErrSessionNotFound()
+	}
+	return &memoryx.SessionWithMessages{
+		Session:  session,
+		Messages: r.copyMessages(sessionID),
+	}, nil
+}
+
+// ListUserSessions lists active sessions for a user, most recently updated first
+func (r *InMemorySessionRepository) ListUserSessions(ctx context.Context, userID string, limit, offset int) ([]*memoryx.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sessions := make([]*memoryx.Session, 0)
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.IsActive {
+			session := session
+			sessions = append(sessions, &session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt)
+	})
+
+	if offset >= len(sessions) {
+		return []*memoryx.Session{}, nil
+	}
+	sessions = sessions[offset:]
+	if limit >= 0 && limit < len(sessions) {
+		sessions = sessions[:limit]
+	}
+	return sessions, nil
+}
+
+// UpdateSession updates session metadata
+func (r *InMemorySessionRepository) UpdateSession(ctx context.Context, session *memoryx.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.sessions[session.ID]
+	if !ok {
+		return memoryx.ErrSessionNotFound()
+	}
+
+	existing.Title = session.Title
+	existing.UpdatedAt = session.UpdatedAt
+	existing.IsActive = session.IsActive
+	existing.Summary = session.Summary
+	existing.SummaryCount = session.SummaryCount
+	r.sessions[session.ID] = existing
+	return nil
+}
+
+// DeleteSession soft deletes a session
+func (r *InMemorySessionRepository) DeleteSession(ctx context.Context, sessionID memoryx.SessionID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if session, ok := r.sessions[sessionID]; ok {
+		session.IsActive = false
+		r.sessions[sessionID] = session
+	}
+	return nil
+}
+
+// AddMessage adds a message to the session
+func (r *InMemorySessionRepository) AddMessage(ctx context.Context, message *memoryx.SessionMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[message.SessionID]
+	if !ok {
+		return memoryx.ErrSessionNotFound()
+	}
+
+	r.nextMessageID++
+	message.ID = r.nextMessageID
+	r.messages[message.SessionID] = append(r.messages[message.SessionID], *message)
+
+	session.UpdatedAt = message.CreatedAt
+	r.sessions[message.SessionID] = session
+	return nil
+}
+
+// GetMessages retrieves all messages for a session
+func (r *InMemorySessionRepository) GetMessages(ctx context.Context, sessionID memoryx.SessionID) ([]memoryx.SessionMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.copyMessages(sessionID), nil
+}
+
+// ClearMessages deletes all messages except system message
+func (r *InMemorySessionRepository) ClearMessages(ctx context.Context, sessionID memoryx.SessionID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := make([]memoryx.SessionMessage, 0)
+	for _, message := range r.messages[sessionID] {
+		if message.Role == "system" {
+			kept = append(kept, message)
+		}
+	}
+	r.messages[sessionID] = kept
+
+	// The summary covered the cleared messages
+	if session, ok := r.sessions[sessionID]; ok {
+		session.Summary = ""
+		session.SummaryCount = 0
+		r.sessions[sessionID] = session
+	}
+	return nil
+}
+
+// GetMessageCount returns the number of messages in a session
+func (r *InMemorySessionRepository) GetMessageCount(ctx context.Context, sessionID memoryx.SessionID) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.messages[sessionID]), nil
+}
+
+func (r *InMemorySessionRepository) copyMessages(sessionID memoryx.SessionID) []memoryx.SessionMessage {
+	messages := make([]memoryx.SessionMessage, len(r.messages[sessionID]))
+	copy(messages, r.messages[sessionID])
+	return messages
+}
@@ -0,0 +1,15 @@
+package memoryinfra_test
+
+import (
+	"testing"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx/memoryinfra"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx/memorytest"
+)
+
+func TestInMemorySessionRepository(t *testing.T) {
+	memorytest.RunSessionRepository(t, func(t *testing.T) memoryx.SessionRepository {
+		return memoryinfra.NewInMemorySessionRepository()
+	})
+}
@@ -151,16 +151,18 @@ func (r *PostgresSessionRepository) UpdateSession(ctx context.Context, session *
 
 	query := `
         UPDATE sessions 
-        SET title = $1, updated_at = $2, is_active = $3
-        WHERE id = $4
+        SET title = $1, updated_at = $2, is_active = $3, summary = $4, summary_count = $5
+        WHERE id = $6
     `
 
 	logx.WithField("session_id", session.ID).Debug("Updating session")
 
-	_, err := executor.ExecContext(ctx, query,
+	result, err := executor.ExecContext(ctx, query,
 		session.Title,
 		session.UpdatedAt,
 		session.IsActive,
+		session.Summary,
+		session.SummaryCount,
 		session.ID,
 	)
 
@@ -169,6 +171,9 @@ func (r *PostgresSessionRepository) UpdateSession(ctx context.Context, session *
 		return err
 	}
 
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return memoryx.ErrSessionNotFound()
+	}
 	return nil
 }
 
@@ -196,8 +201,8 @@ func (r *PostgresSessionRepository) AddMessage(ctx context.Context, message *mem
 	executor := r.getExecutor(ctx)
 
 	query := `
-        INSERT INTO session_messages (session_id, role, content, tool_calls, tool_call_id, created_at)
-        VALUES ($1, $2, $3, $4, $5, $6)
+        INSERT INTO session_messages (session_id, role, content, parts, tool_calls, tool_call_id, metadata, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
         RETURNING id
     `
 
@@ -210,8 +215,10 @@ func (r *PostgresSessionRepository) AddMessage(ctx context.Context, message *mem
 		message.SessionID,
 		message.Role,
 		message.Content,
+		message.Parts,
 		message.ToolCalls,
 		message.ToolCallID,
+		message.Metadata,
 		message.CreatedAt,
 	).Scan(&message.ID)
 
@@ -260,6 +267,13 @@ func (r *PostgresSessionRepository) ClearMessages(ctx context.Context, sessionID
 		return err
 	}
 
+	// The summary covered the cleared messages
+	summaryQuery := `UPDATE sessions SET summary = '', summary_count = 0 WHERE id = $1`
+	if _, err := executor.ExecContext(ctx, summaryQuery, sessionID); err != nil {
+		logx.WithError(err).Error("Failed to reset session summary")
+		return err
+	}
+
 	return nil
 }
 
@@ -0,0 +1,145 @@
+package memoryinfra
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx"
+	"github.com/Abraxas-365/ams/pkg/logx"
+	"github.com/jmoiron/sqlx"
+)
+
+type PostgresToolCallRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresToolCallRepository(db *sqlx.DB) memoryx.ToolCallRepository {
+	logx.Info("PostgreSQL tool call repository initialized")
+	return &PostgresToolCallRepository{db: db}
+}
+
+// RecordToolCall stores a tool execution record
+func (r *PostgresToolCallRepository) RecordToolCall(ctx context.Context, record *memoryx.ToolCallRecord) error {
+	executor := r.getExecutor(ctx)
+
+	query := `
+        INSERT INTO tool_calls (session_id, user_id, tenant_id, route, tool_name, arguments,
+                                http_status, latency_ms, result_size, error, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+        RETURNING id
+    `
+
+	logx.WithFields(logx.Fields{
+		"session_id": record.SessionID,
+		"tool_name":  record.ToolName,
+	}).Debug("Recording tool call")
+
+	err := executor.QueryRowxContext(ctx, query,
+		record.SessionID,
+		record.UserID,
+		record.TenantID,
+		record.Route,
+		record.ToolName,
+		record.Arguments,
+		record.HTTPStatus,
+		record.LatencyMs,
+		record.ResultSize,
+		record.Error,
+		record.CreatedAt,
+	).Scan(&record.ID)
+
+	if err != nil {
+		logx.WithError(err).Error("Failed to record tool call")
+		return err
+	}
+
+	return nil
+}
+
+// ListToolCalls returns tool call records matching the filter, newest first
+func (r *PostgresToolCallRepository) ListToolCalls(ctx context.Context, filter memoryx.ToolCallFilter) ([]*memoryx.ToolCallRecord, error) {
+	executor := r.getExecutor(ctx)
+
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+
+	addCondition := func(clause string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(clause, len(args)))
+	}
+
+	if filter.SessionID != "" {
+		addCondition("session_id = $%d", filter.SessionID)
+	}
+	if filter.UserID != "" {
+		addCondition("user_id = $%d", filter.UserID)
+	}
+	if filter.ToolName != "" {
+		addCondition("tool_name = $%d", filter.ToolName)
+	}
+	if filter.From != nil {
+		addCondition("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("created_at <= $%d", *filter.To)
+	}
+
+	query := `SELECT * FROM tool_calls`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC"
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	args = append(args, limit, filter.Offset)
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	logx.WithFields(logx.Fields{
+		"session_id": filter.SessionID,
+		"user_id":    filter.UserID,
+		"tool_name":  filter.ToolName,
+		"limit":      limit,
+	}).Debug("Listing tool calls")
+
+	var records []*memoryx.ToolCallRecord
+	if err := sqlx.SelectContext(ctx, executor, &records, query, args...); err != nil {
+		logx.WithError(err).Error("Failed to list tool calls")
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// DeleteToolCallsBefore prunes records created before the given time
+func (r *PostgresToolCallRepository) DeleteToolCallsBefore(ctx context.Context, before time.Time) (int64, error) {
+	executor := r.getExecutor(ctx)
+
+	query := `DELETE FROM tool_calls WHERE created_at < $1`
+
+	result, err := executor.ExecContext(ctx, query, before)
+	if err != nil {
+		logx.WithError(err).Error("Failed to prune tool calls")
+		return 0, err
+	}
+
+	deleted, _ := result.RowsAffected()
+	logx.WithFields(logx.Fields{
+		"before":  before,
+		"deleted": deleted,
+	}).Info("Tool calls pruned")
+
+	return deleted, nil
+}
+
+// getExecutor returns transaction if in context, otherwise db
+func (r *PostgresToolCallRepository) getExecutor(ctx context.Context) sqlx.ExtContext {
+	if tx, ok := ctx.Value("db_tx").(*sqlx.Tx); ok {
+		return tx
+	}
+	return r.db
+}
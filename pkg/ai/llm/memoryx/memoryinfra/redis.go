@@ -0,0 +1,836 @@
+package memoryinfra
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx"
+	"github.com/Abraxas-365/ams/pkg/logx"
+	"github.com/redis/go-redis/v9"
+)
+
+// Key layout (single Redis node; scripts build the user index key from the prefix):
+//
+//	{prefix}{id}           hash of session fields
+//	{prefix}{id}:messages  list of JSON messages
+//	{prefix}user:{userID}  sorted set of active session IDs by updated_at
+//	{prefix}pending        sorted set of sessions awaiting write-behind, by updated_at
+//
+// With write-behind, the session hash also tracks what the target repository has:
+// flushed_seq is the last message ID copied, and clear_seq counts clears so
+// flushed_clear_seq can tell whether the target still has to be cleared
+
+// addMessageScript appends a message, assigning its ID and touching the session
+var addMessageScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return -1
+end
+
+local id = redis.call('HINCRBY', KEYS[1], 'message_seq', 1)
+local message = cjson.decode(ARGV[1])
+message['id'] = id
+redis.call('RPUSH', KEYS[2], cjson.encode(message))
+redis.call('HSET', KEYS[1], 'updated_at', ARGV[2])
+
+local ttl = tonumber(ARGV[3])
+if ttl > 0 then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+	redis.call('PEXPIRE', KEYS[2], ttl)
+end
+
+local score = tonumber(ARGV[4])
+if redis.call('HGET', KEYS[1], 'is_active') == '1' then
+	local user_key = ARGV[5] .. 'user:' .. redis.call('HGET', KEYS[1], 'user_id')
+	redis.call('ZADD', user_key, score, ARGV[6])
+	if ttl > 0 then
+		redis.call('PEXPIRE', user_key, ttl)
+	end
+end
+if KEYS[3] then
+	redis.call('ZADD', KEYS[3], score, ARGV[6])
+end
+return id
+`)
+
+// clearMessagesScript keeps only system messages and resets the summary
+// It returns -1 when the session isn't in Redis
+var clearMessagesScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return -1
+end
+
+local kept = {}
+for _, raw in ipairs(redis.call('LRANGE', KEYS[2], 0, -1)) do
+	if cjson.decode(raw)['role'] == 'system' then
+		table.insert(kept, raw)
+	end
+end
+
+local ttl = redis.call('PTTL', KEYS[2])
+redis.call('DEL', KEYS[2])
+if #kept > 0 then
+	redis.call('RPUSH', KEYS[2], unpack(kept))
+	if ttl > 0 then
+		redis.call('PEXPIRE', KEYS[2], ttl)
+	end
+end
+
+redis.call('HSET', KEYS[1], 'summary', '', 'summary_count', 0)
+redis.call('HINCRBY', KEYS[1], 'clear_seq', 1)
+return #kept
+`)
+
+// updateSessionScript sets fields of an existing session and refreshes its TTL
+var updateSessionScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[1], unpack(ARGV, 2))
+local ttl = tonumber(ARGV[1])
+if ttl > 0 then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+	redis.call('PEXPIRE', KEYS[2], ttl)
+end
+return 1
+`)
+
+// rehydrateScript restores a flushed session unless it is already back in Redis
+// ARGV: ttl, score, session ID, JSON array of encoded messages, then hash fields
+var rehydrateScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+
+redis.call('HSET', KEYS[1], unpack(ARGV, 5))
+redis.call('DEL', KEYS[2])
+for _, raw in ipairs(cjson.decode(ARGV[4])) do
+	redis.call('RPUSH', KEYS[2], raw)
+end
+redis.call('ZADD', KEYS[3], tonumber(ARGV[2]), ARGV[3])
+
+local ttl = tonumber(ARGV[1])
+if ttl > 0 then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+	redis.call('PEXPIRE', KEYS[2], ttl)
+	redis.call('PEXPIRE', KEYS[3], ttl)
+end
+return 1
+`)
+
+// markFlushedScript records write-behind progress on a session still in Redis
+var markFlushedScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[1], unpack(ARGV))
+return 1
+`)
+
+// flushDeleteScript removes a flushed session from Redis unless it changed
+// since it was read. ARGV: message_seq, clear_seq, updated_at, session ID
+var flushDeleteScript = redis.NewScript(`
+local state = redis.call('HMGET', KEYS[1], 'message_seq', 'clear_seq', 'updated_at')
+if (state[1] or '0') ~= ARGV[1] or (state[2] or '0') ~= ARGV[2] or (state[3] or '') ~= ARGV[3] then
+	return 0
+end
+redis.call('DEL', KEYS[1], KEYS[2])
+redis.call('ZREM', KEYS[3], ARGV[4])
+redis.call('ZREM', KEYS[4], ARGV[4])
+return 1
+`)
+
+// maxFlushAttempts bounds the retries of a flush racing with new messages
+const maxFlushAttempts = 3
+
+// RedisSessionRepository keeps sessions in Redis with a TTL, for
+// high-traffic conversations that don't need to live in Postgres. With write
+// behind, ended sessions are copied to another repository
+type RedisSessionRepository struct {
+	client      *redis.Client
+	prefix      string
+	ttl         time.Duration
+	writeBehind memoryx.SessionRepository
+}
+
+// RedisSessionOption configures the Redis session repository
+type RedisSessionOption func(*RedisSessionRepository)
+
+// WithSessionTTL sets how long idle sessions are kept (default 24h, 0 = forever)
+func WithSessionTTL(ttl time.Duration) RedisSessionOption {
+	return func(r *RedisSessionRepository) {
+		r.ttl = ttl
+	}
+}
+
+// WithKeyPrefix sets the prefix of all keys (default "session:")
+func WithKeyPrefix(prefix string) RedisSessionOption {
+	return func(r *RedisSessionRepository) {
+		r.prefix = prefix
+	}
+}
+
+// WithWriteBehind copies sessions to target (e.g. Postgres) when they end:
+// on DeleteSession, FlushSession, or once idle (see StartWriteBehind).
+// Reads of flushed sessions fall through to target, and active sessions are
+// loaded back into Redis so the conversation can continue
+func WithWriteBehind(target memoryx.SessionRepository) RedisSessionOption {
+	return func(r *RedisSessionRepository) {
+		r.writeBehind = target
+	}
+}
+
+func NewRedisSessionRepository(client *redis.Client, opts ...RedisSessionOption) *RedisSessionRepository {
+	repo := &RedisSessionRepository{
+		client: client,
+		prefix: "session:",
+		ttl:    24 * time.Hour,
+	}
+	for _, opt := range opts {
+		opt(repo)
+	}
+
+	logx.WithFields(logx.Fields{
+		"ttl":          repo.ttl.String(),
+		"write_behind": repo.writeBehind != nil,
+	}).Info("Redis session repository initialized")
+	return repo
+}
+
+// CreateSession creates a new session
+func (r *RedisSessionRepository) CreateSession(ctx context.Context, session *memoryx.Session) error {
+	logx.WithFields(logx.Fields{
+		"session_id": session.ID,
+		"user_id":    session.UserID,
+	}).Debug("Creating session")
+
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, r.sessionKey(session.ID), encodeSession(session))
+		if r.ttl > 0 {
+			pipe.PExpire(ctx, r.sessionKey(session.ID), r.ttl)
+		}
+		if session.IsActive {
+			pipe.ZAdd(ctx, r.userKey(session.UserID), redis.Z{Score: score(session.UpdatedAt), Member: string(session.ID)})
+			if r.ttl > 0 {
+				pipe.PExpire(ctx, r.userKey(session.UserID), r.ttl)
+			}
+		}
+		if r.writeBehind != nil {
+			pipe.ZAdd(ctx, r.pendingKey(), redis.Z{Score: score(session.UpdatedAt), Member: string(session.ID)})
+		}
+		return nil
+	})
+	if err != nil {
+		logx.WithError(err).Error("Failed to create session")
+		return err
+	}
+
+	logx.WithField("session_id", session.ID).Info("Session created successfully")
+	return nil
+}
+
+// GetSession retrieves a session by ID
+func (r *RedisSessionRepository) GetSession(ctx context.Context, sessionID memoryx.SessionID) (*memoryx.Session, error) {
+	fields, err := r.client.HGetAll(ctx, r.sessionKey(sessionID)).Result()
+	if err != nil {
+		logx.WithError(err).Error("Failed to get session")
+		return nil, err
+	}
+	if len(fields) == 0 {
+		stored, err := r.readThrough(ctx, sessionID)
+		if err != nil {
+			return nil, err
+		}
+		return &stored.Session, nil
+	}
+	return decodeSession(fields), nil
+}
+
+// GetSessionWithMessages retrieves session with all messages
+func (r *RedisSessionRepository) GetSessionWithMessages(ctx context.Context, sessionID memoryx.SessionID) (*memoryx.SessionWithMessages, error) {
+	session, err := r.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := r.GetMessages(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &memoryx.SessionWithMessages{
+		Session:  *session,
+		Messages: messages,
+	}, nil
+}
+
+// ListUserSessions lists active sessions for a user, most recently updated first
+// With write-behind, flushed sessions are merged in from the target repository
+func (r *RedisSessionRepository) ListUserSessions(ctx context.Context, userID string, limit, offset int) ([]*memoryx.Session, error) {
+	logx.WithFields(logx.Fields{
+		"user_id": userID,
+		"limit":   limit,
+		"offset":  offset,
+	}).Debug("Listing user sessions")
+
+	if r.writeBehind == nil {
+		return r.listCachedSessions(ctx, userID, limit, offset)
+	}
+
+	// The page of the merged list is within the first offset+limit of each
+	cached, err := r.listCachedSessions(ctx, userID, offset+limit, 0)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := r.writeBehind.ListUserSessions(ctx, userID, offset+limit, 0)
+	if err != nil {
+		logx.WithError(err).Error("Failed to list flushed user sessions")
+		return nil, err
+	}
+
+	seen := make(map[memoryx.SessionID]bool, len(cached))
+	sessions := make([]*memoryx.Session, 0, len(cached)+len(stored))
+	for _, session := range cached {
+		seen[session.ID] = true
+		sessions = append(sessions, session)
+	}
+	for _, session := range stored {
+		if !seen[session.ID] {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt)
+	})
+
+	if offset >= len(sessions) {
+		return []*memoryx.Session{}, nil
+	}
+	return sessions[offset:min(offset+limit, len(sessions))], nil
+}
+
+// listCachedSessions lists the sessions in the Redis user index
+func (r *RedisSessionRepository) listCachedSessions(ctx context.Context, userID string, limit, offset int) ([]*memoryx.Session, error) {
+	ids, err := r.client.ZRevRange(ctx, r.userKey(userID), int64(offset), int64(offset+limit-1)).Result()
+	if err != nil {
+		logx.WithError(err).Error("Failed to list user sessions")
+		return nil, err
+	}
+
+	sessions := make([]*memoryx.Session, 0, len(ids))
+	for _, id := range ids {
+		fields, err := r.client.HGetAll(ctx, r.sessionKey(memoryx.SessionID(id))).Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) == 0 {
+			// Expired; drop it from the index
+			r.client.ZRem(ctx, r.userKey(userID), id)
+			continue
+		}
+		sessions = append(sessions, decodeSession(fields))
+	}
+
+	return sessions, nil
+}
+
+// UpdateSession updates session metadata
+func (r *RedisSessionRepository) UpdateSession(ctx context.Context, session *memoryx.Session) error {
+	logx.WithField("session_id", session.ID).Debug("Updating session")
+
+	args := []any{r.ttl.Milliseconds(),
+		"title", session.Title,
+		"updated_at", session.UpdatedAt.Format(time.RFC3339Nano),
+		"is_active", formatBool(session.IsActive),
+		"summary", session.Summary,
+		"summary_count", session.SummaryCount,
+	}
+	keys := []string{r.sessionKey(session.ID), r.messagesKey(session.ID)}
+	updated, err := updateSessionScript.Run(ctx, r.client, keys, args...).Int()
+	if err != nil {
+		logx.WithError(err).Error("Failed to update session")
+		return err
+	}
+	if updated == 0 {
+		// Flushed sessions are only in the write-behind repository
+		if r.writeBehind != nil {
+			return r.writeBehind.UpdateSession(ctx, session)
+		}
+		return memoryx.ErrSessionNotFound()
+	}
+
+	if session.IsActive {
+		err = r.client.ZAdd(ctx, r.userKey(session.UserID), redis.Z{Score: score(session.UpdatedAt), Member: string(session.ID)}).Err()
+	} else {
+		err = r.client.ZRem(ctx, r.userKey(session.UserID), string(session.ID)).Err()
+	}
+	if err != nil {
+		logx.WithError(err).Error("Failed to update user session index")
+		return err
+	}
+	return nil
+}
+
+// DeleteSession ends a session; with write-behind it is flushed and removed from Redis,
+// otherwise it is marked inactive until it expires
+func (r *RedisSessionRepository) DeleteSession(ctx context.Context, sessionID memoryx.SessionID) error {
+	logx.WithField("session_id", sessionID).Info("Deleting session")
+
+	session, err := r.GetSession(ctx, sessionID)
+	if memoryx.IsSessionNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	session.IsActive = false
+	if r.writeBehind != nil {
+		return r.flush(ctx, session)
+	}
+
+	if err := r.UpdateSession(ctx, session); err != nil {
+		logx.WithError(err).Error("Failed to delete session")
+		return err
+	}
+
+	logx.WithField("session_id", sessionID).Info("Session deleted successfully")
+	return nil
+}
+
+// AddMessage adds a message to the session
+func (r *RedisSessionRepository) AddMessage(ctx context.Context, message *memoryx.SessionMessage) error {
+	logx.WithFields(logx.Fields{
+		"session_id": message.SessionID,
+		"role":       message.Role,
+	}).Debug("Adding message to session")
+
+	if message.CreatedAt.IsZero() {
+		message.CreatedAt = time.Now()
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		return memoryx.ErrMessageSerializationFailed(err)
+	}
+
+	keys := []string{r.sessionKey(message.SessionID), r.messagesKey(message.SessionID)}
+	if r.writeBehind != nil {
+		keys = append(keys, r.pendingKey())
+	}
+	args := []any{
+		data,
+		message.CreatedAt.Format(time.RFC3339Nano),
+		r.ttl.Milliseconds(),
+		score(message.CreatedAt),
+		r.prefix,
+		string(message.SessionID),
+	}
+	id, err := addMessageScript.Run(ctx, r.client, keys, args...).Int64()
+	if err == nil && id < 0 && r.writeBehind != nil {
+		// Flushed while idle; load it back and retry
+		if _, err = r.readThrough(ctx, message.SessionID); err == nil {
+			id, err = addMessageScript.Run(ctx, r.client, keys, args...).Int64()
+		}
+	}
+	if err != nil {
+		if !memoryx.IsSessionNotFound(err) {
+			logx.WithError(err).Error("Failed to add message")
+		}
+		return err
+	}
+	if id < 0 {
+		return memoryx.ErrSessionNotFound()
+	}
+
+	message.ID = id
+	logx.WithField("message_id", message.ID).Debug("Message added successfully")
+	return nil
+}
+
+// GetMessages retrieves all messages for a session
+func (r *RedisSessionRepository) GetMessages(ctx context.Context, sessionID memoryx.SessionID) ([]memoryx.SessionMessage, error) {
+	raw, err := r.client.LRange(ctx, r.messagesKey(sessionID), 0, -1).Result()
+	if err != nil {
+		logx.WithError(err).Error("Failed to get messages")
+		return nil, err
+	}
+
+	if len(raw) == 0 && r.writeBehind != nil {
+		exists, err := r.client.Exists(ctx, r.sessionKey(sessionID)).Result()
+		if err != nil {
+			return nil, err
+		}
+		if exists == 0 {
+			stored, err := r.readThrough(ctx, sessionID)
+			if memoryx.IsSessionNotFound(err) {
+				return []memoryx.SessionMessage{}, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			return stored.Messages, nil
+		}
+	}
+
+	messages := make([]memoryx.SessionMessage, 0, len(raw))
+	for _, data := range raw {
+		var message memoryx.SessionMessage
+		if err := json.Unmarshal([]byte(data), &message); err != nil {
+			logx.WithError(err).Warn("Skipping undecodable session message")
+			continue
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// ClearMessages deletes all messages except system message
+func (r *RedisSessionRepository) ClearMessages(ctx context.Context, sessionID memoryx.SessionID) error {
+	logx.WithField("session_id", sessionID).Debug("Clearing session messages")
+
+	keys := []string{r.sessionKey(sessionID), r.messagesKey(sessionID)}
+	kept, err := clearMessagesScript.Run(ctx, r.client, keys).Int()
+	if err != nil {
+		logx.WithError(err).Error("Failed to clear messages")
+		return err
+	}
+
+	// Sessions in Redis are cleared in the write-behind repository on the next
+	// flush; flushed sessions are only there
+	if kept < 0 && r.writeBehind != nil {
+		return r.writeBehind.ClearMessages(ctx, sessionID)
+	}
+	return nil
+}
+
+// GetMessageCount returns the number of messages in a session
+func (r *RedisSessionRepository) GetMessageCount(ctx context.Context, sessionID memoryx.SessionID) (int, error) {
+	count, err := r.client.LLen(ctx, r.messagesKey(sessionID)).Result()
+	return int(count), err
+}
+
+// ============================================================================
+// Write-behind
+// ============================================================================
+
+// FlushSession copies a session to the write-behind repository and removes it from Redis
+func (r *RedisSessionRepository) FlushSession(ctx context.Context, sessionID memoryx.SessionID) error {
+	if r.writeBehind == nil {
+		return nil
+	}
+
+	session, err := r.GetSession(ctx, sessionID)
+	if memoryx.IsSessionNotFound(err) {
+		// Expired before it could be flushed
+		r.client.ZRem(ctx, r.pendingKey(), string(sessionID))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return r.flush(ctx, session)
+}
+
+// FlushIdle flushes sessions without activity for idle; it returns how many were flushed
+func (r *RedisSessionRepository) FlushIdle(ctx context.Context, idle time.Duration) (int, error) {
+	if r.writeBehind == nil {
+		return 0, nil
+	}
+
+	ids, err := r.client.ZRangeByScore(ctx, r.pendingKey(), &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(time.Now().Add(-idle).UnixMilli(), 10),
+		Count: 100,
+	}).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	flushed := 0
+	for _, id := range ids {
+		if err := r.FlushSession(ctx, memoryx.SessionID(id)); err != nil {
+			logx.WithField("session_id", id).WithError(err).Error("Failed to flush session")
+			continue
+		}
+		flushed++
+	}
+	return flushed, nil
+}
+
+// StartWriteBehind flushes idle sessions every interval until ctx is cancelled
+// It returns immediately when write-behind isn't configured; idle should be
+// shorter than the TTL or sessions expire before they are flushed
+func (r *RedisSessionRepository) StartWriteBehind(ctx context.Context, interval, idle time.Duration) {
+	if r.writeBehind == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logx.Info("Session write-behind stopped")
+			return
+		case <-ticker.C:
+			flushed, err := r.FlushIdle(ctx, idle)
+			if err != nil {
+				logx.WithError(err).Error("Failed to flush idle sessions")
+			} else if flushed > 0 {
+				logx.WithField("sessions", flushed).Info("Flushed idle sessions")
+			}
+		}
+	}
+}
+
+// readThrough loads a session missing from Redis from the write-behind
+// repository; active sessions are put back into Redis
+func (r *RedisSessionRepository) readThrough(ctx context.Context, sessionID memoryx.SessionID) (*memoryx.SessionWithMessages, error) {
+	if r.writeBehind == nil {
+		logx.WithField("session_id", sessionID).Warn("Session not found")
+		return nil, memoryx.ErrSessionNotFound()
+	}
+
+	stored, err := r.writeBehind.GetSessionWithMessages(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if stored.Session.IsActive {
+		if err := r.rehydrate(ctx, stored); err != nil {
+			// Still readable from the write-behind repository
+			logx.WithField("session_id", sessionID).WithError(err).Warn("Failed to load flushed session back into Redis")
+		}
+	}
+	return stored, nil
+}
+
+// rehydrate writes a flushed session back to Redis; it is not pending since
+// the write-behind repository already has it
+func (r *RedisSessionRepository) rehydrate(ctx context.Context, stored *memoryx.SessionWithMessages) error {
+	session := &stored.Session
+
+	var sequence int64
+	encoded := make([]string, 0, len(stored.Messages))
+	for _, message := range stored.Messages {
+		data, err := json.Marshal(message)
+		if err != nil {
+			return memoryx.ErrMessageSerializationFailed(err)
+		}
+		encoded = append(encoded, string(data))
+		sequence = max(sequence, message.ID)
+	}
+	messages, err := json.Marshal(encoded)
+	if err != nil {
+		return memoryx.ErrMessageSerializationFailed(err)
+	}
+
+	args := []any{r.ttl.Milliseconds(), score(session.UpdatedAt), string(session.ID), messages}
+	for field, value := range encodeSession(session) {
+		args = append(args, field, value)
+	}
+	// Everything loaded back is already in the write-behind repository
+	args = append(args, "message_seq", sequence, "flushed_seq", sequence)
+
+	keys := []string{r.sessionKey(session.ID), r.messagesKey(session.ID), r.userKey(session.UserID)}
+	if err := rehydrateScript.Run(ctx, r.client, keys, args...).Err(); err != nil {
+		return err
+	}
+
+	logx.WithFields(logx.Fields{
+		"session_id":    session.ID,
+		"message_count": len(stored.Messages),
+	}).Info("Flushed session loaded back into Redis")
+	return nil
+}
+
+// flush copies session and its messages to the write-behind repository, then
+// removes them from Redis. A flush interrupted halfway resumes where it left
+// off, and a flush racing with new messages or clears starts over
+func (r *RedisSessionRepository) flush(ctx context.Context, session *memoryx.Session) error {
+	// One replica flushes a session at a time
+	lockKey := r.sessionKey(session.ID) + ":flush"
+	locked, err := r.client.SetNX(ctx, lockKey, 1, 5*time.Minute).Result()
+	if err != nil {
+		return err
+	}
+	if !locked {
+		return nil
+	}
+	defer r.client.Del(context.WithoutCancel(ctx), lockKey)
+
+	for range maxFlushAttempts {
+		done, err := r.flushOnce(ctx, session)
+		if err != nil || done {
+			return err
+		}
+		logx.WithField("session_id", session.ID).Debug("Session changed during flush, retrying")
+	}
+	return memoryx.ErrSessionFlushConflict()
+}
+
+// flushOnce copies a snapshot of the session; it reports false when the
+// session changed before it could be removed from Redis
+func (r *RedisSessionRepository) flushOnce(ctx context.Context, session *memoryx.Session) (bool, error) {
+	var fieldsCmd *redis.MapStringStringCmd
+	var messagesCmd *redis.StringSliceCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		fieldsCmd = pipe.HGetAll(ctx, r.sessionKey(session.ID))
+		messagesCmd = pipe.LRange(ctx, r.messagesKey(session.ID), 0, -1)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	fields := fieldsCmd.Val()
+	snapshot := session
+	if len(fields) > 0 {
+		snapshot = decodeSession(fields)
+		snapshot.IsActive = snapshot.IsActive && session.IsActive
+	}
+
+	_, err = r.writeBehind.GetSession(ctx, session.ID)
+	if memoryx.IsSessionNotFound(err) {
+		err = r.writeBehind.CreateSession(ctx, snapshot)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if len(fields) == 0 {
+		// Already flushed, only the session fields change (e.g. DeleteSession)
+		if err := r.writeBehind.UpdateSession(ctx, snapshot); err != nil {
+			return false, err
+		}
+		return true, r.client.ZRem(ctx, r.pendingKey(), string(session.ID)).Err()
+	}
+
+	clearSeq, _ := strconv.ParseInt(fields["clear_seq"], 10, 64)
+	flushedClearSeq, _ := strconv.ParseInt(fields["flushed_clear_seq"], 10, 64)
+	if clearSeq > flushedClearSeq {
+		if err := r.writeBehind.ClearMessages(ctx, session.ID); err != nil {
+			return false, err
+		}
+		if err := r.markFlushed(ctx, session.ID, "flushed_clear_seq", clearSeq); err != nil {
+			return false, err
+		}
+	}
+
+	flushedSeq, _ := strconv.ParseInt(fields["flushed_seq"], 10, 64)
+	copied := 0
+	for _, data := range messagesCmd.Val() {
+		var message memoryx.SessionMessage
+		if err := json.Unmarshal([]byte(data), &message); err != nil {
+			logx.WithError(err).Warn("Skipping undecodable session message")
+			continue
+		}
+		if message.ID <= flushedSeq {
+			continue
+		}
+
+		// The target assigns its own ID; progress is tracked by the Redis ID
+		id := message.ID
+		if err := r.writeBehind.AddMessage(ctx, &message); err != nil {
+			return false, err
+		}
+		if err := r.markFlushed(ctx, session.ID, "flushed_seq", id); err != nil {
+			return false, err
+		}
+		copied++
+	}
+	if err := r.writeBehind.UpdateSession(ctx, snapshot); err != nil {
+		return false, err
+	}
+
+	keys := []string{r.sessionKey(session.ID), r.messagesKey(session.ID), r.userKey(snapshot.UserID), r.pendingKey()}
+	args := []any{fieldOr(fields, "message_seq", "0"), fieldOr(fields, "clear_seq", "0"), fields["updated_at"], string(session.ID)}
+	deleted, err := flushDeleteScript.Run(ctx, r.client, keys, args...).Int()
+	if err != nil || deleted == 0 {
+		return false, err
+	}
+
+	logx.WithFields(logx.Fields{
+		"session_id":    session.ID,
+		"message_count": copied,
+	}).Info("Session flushed to write-behind repository")
+	return true, nil
+}
+
+// markFlushed records write-behind progress in the session hash
+func (r *RedisSessionRepository) markFlushed(ctx context.Context, sessionID memoryx.SessionID, field string, value int64) error {
+	return markFlushedScript.Run(ctx, r.client, []string{r.sessionKey(sessionID)}, field, value).Err()
+}
+
+// ============================================================================
+// Encoding
+// ============================================================================
+
+func (r *RedisSessionRepository) sessionKey(sessionID memoryx.SessionID) string {
+	return r.prefix + string(sessionID)
+}
+
+func (r *RedisSessionRepository) messagesKey(sessionID memoryx.SessionID) string {
+	return r.prefix + string(sessionID) + ":messages"
+}
+
+func (r *RedisSessionRepository) userKey(userID string) string {
+	return r.prefix + "user:" + userID
+}
+
+func (r *RedisSessionRepository) pendingKey() string {
+	return r.prefix + "pending"
+}
+
+func encodeSession(session *memoryx.Session) map[string]any {
+	return map[string]any{
+		"id":             string(session.ID),
+		"user_id":        session.UserID,
+		"title":          session.Title,
+		"system_message": session.SystemMsg,
+		"created_at":     session.CreatedAt.Format(time.RFC3339Nano),
+		"updated_at":     session.UpdatedAt.Format(time.RFC3339Nano),
+		"is_active":      formatBool(session.IsActive),
+		"summary":        session.Summary,
+		"summary_count":  session.SummaryCount,
+	}
+}
+
+func decodeSession(fields map[string]string) *memoryx.Session {
+	createdAt, _ := time.Parse(time.RFC3339Nano, fields["created_at"])
+	updatedAt, _ := time.Parse(time.RFC3339Nano, fields["updated_at"])
+	summaryCount, _ := strconv.Atoi(fields["summary_count"])
+
+	return &memoryx.Session{
+		ID:           memoryx.SessionID(fields["id"]),
+		UserID:       fields["user_id"],
+		Title:        fields["title"],
+		SystemMsg:    fields["system_message"],
+		CreatedAt:    createdAt,
+		UpdatedAt:    updatedAt,
+		IsActive:     fields["is_active"] == "1",
+		Summary:      fields["summary"],
+		SummaryCount: summaryCount,
+	}
+}
+
+func fieldOr(fields map[string]string, key, fallback string) string {
+	if value, ok := fields[key]; ok {
+		return value
+	}
+	return fallback
+}
+
+func formatBool(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// score orders sorted sets by time
+func score(t time.Time) float64 {
+	return float64(t.UnixMilli())
+}
@@ -0,0 +1,202 @@
+package memoryinfra_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx/memoryinfra"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx/memorytest"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisClient connects to TEST_REDIS_ADDR, e.g. "localhost:6379", and
+// returns a key prefix cleaned up after the test
+func redisClient(t *testing.T) (*redis.Client, string) {
+	t.Helper()
+
+	addr := os.Getenv("TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("TEST_REDIS_ADDR not set")
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	prefix := fmt.Sprintf("sessions_test_%d:", time.Now().UnixNano())
+	t.Cleanup(func() {
+		ctx := context.Background()
+		keys, _ := client.Keys(ctx, prefix+"*").Result()
+		if len(keys) > 0 {
+			client.Del(ctx, keys...)
+		}
+		client.Close()
+	})
+	return client, prefix
+}
+
+func TestRedisSessionRepository(t *testing.T) {
+	memorytest.RunSessionRepository(t, func(t *testing.T) memoryx.SessionRepository {
+		client, prefix := redisClient(t)
+		return memoryinfra.NewRedisSessionRepository(client, memoryinfra.WithKeyPrefix(prefix))
+	})
+}
+
+func TestRedisSessionRepositoryWithWriteBehind(t *testing.T) {
+	memorytest.RunSessionRepository(t, func(t *testing.T) memoryx.SessionRepository {
+		client, prefix := redisClient(t)
+		return memoryinfra.NewRedisSessionRepository(client,
+			memoryinfra.WithKeyPrefix(prefix),
+			memoryinfra.WithWriteBehind(memoryinfra.NewInMemorySessionRepository()),
+		)
+	})
+}
+
+func TestRedisReadsThroughFlushedSessions(t *testing.T) {
+	client, prefix := redisClient(t)
+	ctx := context.Background()
+	target := memoryinfra.NewInMemorySessionRepository()
+	repo := memoryinfra.NewRedisSessionRepository(client,
+		memoryinfra.WithKeyPrefix(prefix),
+		memoryinfra.WithWriteBehind(target),
+	)
+
+	now := time.Now()
+	session := &memoryx.Session{
+		ID:        memoryx.NewSessionID(),
+		UserID:    "user-1",
+		Title:     "Chat",
+		CreatedAt: now,
+		UpdatedAt: now,
+		IsActive:  true,
+	}
+	if err := repo.CreateSession(ctx, session); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	addMessage := func(content string) {
+		t.Helper()
+		message, err := memoryx.FromLLMMessage(session.ID, llm.NewUserMessage(content))
+		if err != nil {
+			t.Fatalf("FromLLMMessage: %v", err)
+		}
+		if err := repo.AddMessage(ctx, &message); err != nil {
+			t.Fatalf("AddMessage(%q): %v", content, err)
+		}
+	}
+	addMessage("first")
+	addMessage("second")
+
+	// Idle flush removes the session from Redis
+	if flushed, err := repo.FlushIdle(ctx, 0); err != nil || flushed != 1 {
+		t.Fatalf("FlushIdle = %d, %v", flushed, err)
+	}
+	if n, _ := client.Exists(ctx, prefix+string(session.ID)).Result(); n != 0 {
+		t.Fatalf("session still in Redis after flush")
+	}
+
+	sessions, err := repo.ListUserSessions(ctx, "user-1", 10, 0)
+	if err != nil || len(sessions) != 1 || sessions[0].ID != session.ID {
+		t.Fatalf("ListUserSessions = %v, %v", sessions, err)
+	}
+
+	messages, err := repo.GetMessages(ctx, session.ID)
+	if err != nil || len(messages) != 2 || messages[1].Content != "second" {
+		t.Fatalf("GetMessages = %+v, %v", messages, err)
+	}
+	if _, err := repo.GetSession(ctx, session.ID); err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+
+	// The conversation continues in Redis and is flushed again without duplicates
+	addMessage("third")
+	messages, err = repo.GetMessages(ctx, session.ID)
+	if err != nil || len(messages) != 3 || messages[2].Content != "third" || messages[2].ID <= messages[1].ID {
+		t.Fatalf("GetMessages after continuing = %+v, %v", messages, err)
+	}
+	if err := repo.FlushSession(ctx, session.ID); err != nil {
+		t.Fatalf("FlushSession: %v", err)
+	}
+	if count, _ := target.GetMessageCount(ctx, session.ID); count != 3 {
+		t.Errorf("write-behind has %d messages, want 3", count)
+	}
+}
+
+func TestRedisUpdateRefreshesMessagesTTL(t *testing.T) {
+	client, prefix := redisClient(t)
+	ctx := context.Background()
+	repo := memoryinfra.NewRedisSessionRepository(client,
+		memoryinfra.WithKeyPrefix(prefix),
+		memoryinfra.WithSessionTTL(time.Hour),
+	)
+
+	now := time.Now()
+	session := &memoryx.Session{ID: memoryx.NewSessionID(), UserID: "user-1", CreatedAt: now, UpdatedAt: now, IsActive: true}
+	if err := repo.CreateSession(ctx, session); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	message, _ := memoryx.FromLLMMessage(session.ID, llm.NewUserMessage("hi"))
+	if err := repo.AddMessage(ctx, &message); err != nil {
+		t.Fatalf("AddMessage: %v", err)
+	}
+
+	messagesKey := prefix + string(session.ID) + ":messages"
+	client.PExpire(ctx, messagesKey, time.Minute)
+	if err := repo.UpdateSession(ctx, session); err != nil {
+		t.Fatalf("UpdateSession: %v", err)
+	}
+	if ttl, _ := client.PTTL(ctx, messagesKey).Result(); ttl < 59*time.Minute {
+		t.Errorf("messages TTL = %s, want it refreshed to the session TTL", ttl)
+	}
+}
+
+// racingRepository runs onAdd before its first AddMessage, while a flush is copying
+type racingRepository struct {
+	memoryx.SessionRepository
+	onAdd func()
+}
+
+func (r *racingRepository) AddMessage(ctx context.Context, message *memoryx.SessionMessage) error {
+	if onAdd := r.onAdd; onAdd != nil {
+		r.onAdd = nil
+		onAdd()
+	}
+	return r.SessionRepository.AddMessage(ctx, message)
+}
+
+func TestRedisFlushKeepsMessagesAddedDuringFlush(t *testing.T) {
+	client, prefix := redisClient(t)
+	ctx := context.Background()
+	target := &racingRepository{SessionRepository: memoryinfra.NewInMemorySessionRepository()}
+	repo := memoryinfra.NewRedisSessionRepository(client,
+		memoryinfra.WithKeyPrefix(prefix),
+		memoryinfra.WithWriteBehind(target),
+	)
+
+	now := time.Now()
+	session := &memoryx.Session{ID: memoryx.NewSessionID(), UserID: "user-1", CreatedAt: now, UpdatedAt: now, IsActive: true}
+	if err := repo.CreateSession(ctx, session); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	addMessage := func(content string) {
+		t.Helper()
+		message, _ := memoryx.FromLLMMessage(session.ID, llm.NewUserMessage(content))
+		if err := repo.AddMessage(ctx, &message); err != nil {
+			t.Fatalf("AddMessage(%q): %v", content, err)
+		}
+	}
+	addMessage("first")
+	target.onAdd = func() { addMessage("during flush") }
+
+	if err := repo.FlushSession(ctx, session.ID); err != nil {
+		t.Fatalf("FlushSession: %v", err)
+	}
+	if n, _ := client.Exists(ctx, prefix+string(session.ID)).Result(); n != 0 {
+		t.Fatalf("session still in Redis after flush")
+	}
+	messages, err := target.GetMessages(ctx, session.ID)
+	if err != nil || len(messages) != 2 || messages[1].Content != "during flush" {
+		t.Fatalf("write-behind messages = %+v, %v", messages, err)
+	}
+}
@@ -0,0 +1,296 @@
+package memoryinfra
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx"
+	"github.com/Abraxas-365/ams/pkg/logx"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteSchema mirrors the Postgres session migrations
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS sessions (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    system_message TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT 1,
+    summary TEXT NOT NULL DEFAULT '',
+    summary_count INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS session_messages (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('system', 'user', 'assistant', 'tool')),
+    content TEXT NOT NULL,
+    parts TEXT NOT NULL DEFAULT '',
+    tool_calls TEXT NOT NULL DEFAULT '',
+    tool_call_id TEXT NOT NULL DEFAULT '',
+    metadata TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_active ON sessions(user_id, is_active, updated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_session_messages_created_at ON session_messages(session_id, created_at);
+`
+
+// OpenSQLite opens (or creates) a SQLite database file and applies the session schema
+// Use ":memory:" for a throwaway database
+func OpenSQLite(path string) (*sqlx.DB, error) {
+	db, err := sqlx.Connect("sqlite3", path+"?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite serializes writers; one connection avoids "database is locked"
+	// and keeps ":memory:" databases alive
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// SQLiteSessionRepository stores sessions in an embedded SQLite database,
+// for single-binary local runs
+type SQLiteSessionRepository struct {
+	db *sqlx.DB
+}
+
+func NewSQLiteSessionRepository(db *sqlx.DB) memoryx.SessionRepository {
+	logx.Info("SQLite session repository initialized")
+	return &SQLiteSessionRepository{db: db}
+}
+
+// CreateSession creates a new session
+func (r *SQLiteSessionRepository) CreateSession(ctx context.Context, session *memoryx.Session) error {
+	executor := r.getExecutor(ctx)
+
+	query := `
+        INSERT INTO sessions (id, user_id, title, system_message, created_at, updated_at, is_active, summary, summary_count)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `
+
+	_, err := executor.ExecContext(ctx, query,
+		session.ID,
+		session.UserID,
+		session.Title,
+		session.SystemMsg,
+		session.CreatedAt,
+		session.UpdatedAt,
+		session.IsActive,
+		session.Summary,
+		session.SummaryCount,
+	)
+
+	if err != nil {
+		logx.WithError(err).Error("Failed to create session")
+		return err
+	}
+
+	logx.WithField("session_id", session.ID).Info("Session created successfully")
+	return nil
+}
+
+// GetSession retrieves a session by ID
+func (r *SQLiteSessionRepository) GetSession(ctx context.Context, sessionID memoryx.SessionID) (*memoryx.Session, error) {
+	executor := r.getExecutor(ctx)
+
+	var session memoryx.Session
+	err := sqlx.GetContext(ctx, executor, &session, `SELECT * FROM sessions WHERE id = ?`, sessionID)
+	if err == sql.ErrNoRows {
+		return nil, memoryx.ErrSessionNotFound()
+	}
+
+	if err != nil {
+		logx.WithError(err).Error("Failed to get session")
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// GetSessionWithMessages retrieves session with all messages
+func (r *SQLiteSessionRepository) GetSessionWithMessages(ctx context.Context, sessionID memoryx.SessionID) (*memoryx.SessionWithMessages, error) {
+	session, err := r.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := r.GetMessages(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &memoryx.SessionWithMessages{
+		Session:  *session,
+		Messages: messages,
+	}, nil
+}
+
+// ListUserSessions lists all sessions for a user
+func (r *SQLiteSessionRepository) ListUserSessions(ctx context.Context, userID string, limit, offset int) ([]*memoryx.Session, error) {
+	executor := r.getExecutor(ctx)
+
+	query := `
+        SELECT * FROM sessions
+        WHERE user_id = ? AND is_active = 1
+        ORDER BY updated_at DESC
+        LIMIT ? OFFSET ?
+    `
+
+	var sessions []*memoryx.Session
+	err := sqlx.SelectContext(ctx, executor, &sessions, query, userID, limit, offset)
+
+	if err != nil {
+		logx.WithError(err).Error("Failed to list user sessions")
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// UpdateSession updates session metadata
+func (r *SQLiteSessionRepository) UpdateSession(ctx context.Context, session *memoryx.Session) error {
+	executor := r.getExecutor(ctx)
+
+	query := `
+        UPDATE sessions
+        SET title = ?, updated_at = ?, is_active = ?, summary = ?, summary_count = ?
+        WHERE id = ?
+    `
+
+	result, err := executor.ExecContext(ctx, query,
+		session.Title,
+		session.UpdatedAt,
+		session.IsActive,
+		session.Summary,
+		session.SummaryCount,
+		session.ID,
+	)
+
+	if err != nil {
+		logx.WithError(err).Error("Failed to update session")
+		return err
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return memoryx.ErrSessionNotFound()
+	}
+	return nil
+}
+
+// DeleteSession soft deletes a session
+func (r *SQLiteSessionRepository) DeleteSession(ctx context.Context, sessionID memoryx.SessionID) error {
+	executor := r.getExecutor(ctx)
+
+	_, err := executor.ExecContext(ctx, `UPDATE sessions SET is_active = 0 WHERE id = ?`, sessionID)
+
+	if err != nil {
+		logx.WithError(err).Error("Failed to delete session")
+		return err
+	}
+
+	logx.WithField("session_id", sessionID).Info("Session deleted successfully")
+	return nil
+}
+
+// AddMessage adds a message to the session
+func (r *SQLiteSessionRepository) AddMessage(ctx context.Context, message *memoryx.SessionMessage) error {
+	executor := r.getExecutor(ctx)
+
+	query := `
+        INSERT INTO session_messages (session_id, role, content, parts, tool_calls, tool_call_id, metadata, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+    `
+
+	result, err := executor.ExecContext(ctx, query,
+		message.SessionID,
+		message.Role,
+		message.Content,
+		message.Parts,
+		message.ToolCalls,
+		message.ToolCallID,
+		message.Metadata,
+		message.CreatedAt,
+	)
+
+	if err != nil {
+		logx.WithError(err).Error("Failed to add message")
+		return err
+	}
+
+	message.ID, err = result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	// Update session's updated_at
+	_, _ = executor.ExecContext(ctx, `UPDATE sessions SET updated_at = ? WHERE id = ?`, message.CreatedAt, message.SessionID)
+
+	logx.WithField("message_id", message.ID).Debug("Message added successfully")
+	return nil
+}
+
+// GetMessages retrieves all messages for a session
+func (r *SQLiteSessionRepository) GetMessages(ctx context.Context, sessionID memoryx.SessionID) ([]memoryx.SessionMessage, error) {
+	executor := r.getExecutor(ctx)
+
+	query := `SELECT * FROM session_messages WHERE session_id = ? ORDER BY created_at ASC, id ASC`
+
+	var messages []memoryx.SessionMessage
+	err := sqlx.SelectContext(ctx, executor, &messages, query, sessionID)
+
+	if err != nil {
+		logx.WithError(err).Error("Failed to get messages")
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// ClearMessages deletes all messages except system message
+func (r *SQLiteSessionRepository) ClearMessages(ctx context.Context, sessionID memoryx.SessionID) error {
+	executor := r.getExecutor(ctx)
+
+	_, err := executor.ExecContext(ctx, `DELETE FROM session_messages WHERE session_id = ? AND role != 'system'`, sessionID)
+
+	if err != nil {
+		logx.WithError(err).Error("Failed to clear messages")
+		return err
+	}
+
+	// The summary covered the cleared messages
+	if _, err := executor.ExecContext(ctx, `UPDATE sessions SET summary = '', summary_count = 0 WHERE id = ?`, sessionID); err != nil {
+		logx.WithError(err).Error("Failed to reset session summary")
+		return err
+	}
+
+	return nil
+}
+
+// GetMessageCount returns the number of messages in a session
+func (r *SQLiteSessionRepository) GetMessageCount(ctx context.Context, sessionID memoryx.SessionID) (int, error) {
+	executor := r.getExecutor(ctx)
+
+	var count int
+	err := executor.QueryRowxContext(ctx, `SELECT COUNT(*) FROM session_messages WHERE session_id = ?`, sessionID).Scan(&count)
+
+	return count, err
+}
+
+// getExecutor returns transaction if in context, otherwise db
+func (r *SQLiteSessionRepository) getExecutor(ctx context.Context) sqlx.ExtContext {
+	if tx, ok := ctx.Value("db_tx").(*sqlx.Tx); ok {
+		return tx
+	}
+	return r.db
+}
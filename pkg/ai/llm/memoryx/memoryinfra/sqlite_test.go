@@ -0,0 +1,21 @@
+package memoryinfra_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx/memoryinfra"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx/memorytest"
+)
+
+func TestSQLiteSessionRepository(t *testing.T) {
+	memorytest.RunSessionRepository(t, func(t *testing.T) memoryx.SessionRepository {
+		db, err := memoryinfra.OpenSQLite(filepath.Join(t.TempDir(), "sessions.db"))
+		if err != nil {
+			t.Fatalf("OpenSQLite: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return memoryinfra.NewSQLiteSessionRepository(db)
+	})
+}
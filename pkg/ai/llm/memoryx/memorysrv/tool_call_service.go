@@ -0,0 +1,102 @@
+package memorysrv
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx"
+	"github.com/Abraxas-365/ams/pkg/logx"
+)
+
+// RetentionPolicy controls how long tool call records are kept
+type RetentionPolicy struct {
+	MaxAge   time.Duration // Records older than this are pruned (0 = keep forever)
+	Interval time.Duration // How often the pruning job runs
+}
+
+type ToolCallService struct {
+	repository memoryx.ToolCallRepository
+	retention  RetentionPolicy
+}
+
+// ToolCallServiceOption configures the tool call service
+type ToolCallServiceOption func(*ToolCallService)
+
+// WithRetention enables pruning of old tool call records
+func WithRetention(policy RetentionPolicy) ToolCallServiceOption {
+	return func(s *ToolCallService) {
+		s.retention = policy
+	}
+}
+
+func NewToolCallService(repo memoryx.ToolCallRepository, opts ...ToolCallServiceOption) *ToolCallService {
+	service := &ToolCallService{repository: repo}
+	for _, opt := range opts {
+		opt(service)
+	}
+
+	if service.retention.MaxAge > 0 && service.retention.Interval <= 0 {
+		service.retention.Interval = time.Hour
+	}
+
+	logx.WithFields(logx.Fields{
+		"retention": service.retention.MaxAge,
+	}).Info("Tool call service initialized")
+	return service
+}
+
+// RecordToolCall stores an audit record for a tool execution
+func (s *ToolCallService) RecordToolCall(ctx context.Context, record *memoryx.ToolCallRecord) error {
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now()
+	}
+	return s.repository.RecordToolCall(ctx, record)
+}
+
+// ListToolCalls queries the audit trail
+func (s *ToolCallService) ListToolCalls(ctx context.Context, filter memoryx.ToolCallFilter) ([]*memoryx.ToolCallRecord, error) {
+	logx.WithFields(logx.Fields{
+		"session_id": filter.SessionID,
+		"user_id":    filter.UserID,
+		"tool_name":  filter.ToolName,
+	}).Debug("Listing tool calls")
+
+	return s.repository.ListToolCalls(ctx, filter)
+}
+
+// Prune deletes records older than the retention policy allows
+func (s *ToolCallService) Prune(ctx context.Context) (int64, error) {
+	if s.retention.MaxAge <= 0 {
+		return 0, nil
+	}
+	return s.repository.DeleteToolCallsBefore(ctx, time.Now().Add(-s.retention.MaxAge))
+}
+
+// StartRetention runs the pruning job until ctx is cancelled
+// It returns immediately when no retention policy is configured
+func (s *ToolCallService) StartRetention(ctx context.Context) {
+	if s.retention.MaxAge <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.retention.Interval)
+	defer ticker.Stop()
+
+	s.runPrune(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			logx.Info("Tool call retention stopped")
+			return
+		case <-ticker.C:
+			s.runPrune(ctx)
+		}
+	}
+}
+
+func (s *ToolCallService) runPrune(ctx context.Context) {
+	if _, err := s.Prune(ctx); err != nil {
+		logx.WithError(err).Error("Failed to prune tool calls")
+	}
+}
@@ -0,0 +1,454 @@
+// Package memorytest is a conformance suite for memoryx.SessionRepository
+// implementations. Each implementation runs it from its own tests:
+//
+//	func TestSessionRepository(t *testing.T) {
+//		memorytest.RunSessionRepository(t, func(t *testing.T) memoryx.SessionRepository {
+//			return memoryinfra.NewInMemorySessionRepository()
+//		})
+//	}
+package memorytest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx"
+)
+
+// Factory returns an empty repository; it is called once per subtest
+type Factory func(t *testing.T) memoryx.SessionRepository
+
+// RunSessionRepository runs the conformance suite against repositories from newRepo
+func RunSessionRepository(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo memoryx.SessionRepository)
+	}{
+		{"CreateAndGetSession", testCreateAndGetSession},
+		{"GetMissingSession", testGetMissingSession},
+		{"UpdateSession", testUpdateSession},
+		{"UpdateMissingSession", testUpdateMissingSession},
+		{"ListUserSessions", testListUserSessions},
+		{"DeleteSession", testDeleteSession},
+		{"Messages", testMessages},
+		{"AddMessageToMissingSession", testAddMessageToMissingSession},
+		{"ClearMessages", testClearMessages},
+		{"ClearFlushedSession", testClearFlushedSession},
+		{"SessionMemory", testSessionMemory},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepo(t))
+		})
+	}
+}
+
+func testCreateAndGetSession(t *testing.T, repo memoryx.SessionRepository) {
+	ctx := context.Background()
+	session := createSession(t, repo, "user-1", time.Now())
+
+	got, err := repo.GetSession(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	assertSession(t, got, session)
+}
+
+func testGetMissingSession(t *testing.T, repo memoryx.SessionRepository) {
+	_, err := repo.GetSession(context.Background(), "missing")
+	if !memoryx.IsSessionNotFound(err) {
+		t.Fatalf("GetSession of a missing session: got %v, want session not found", err)
+	}
+
+	_, err = repo.GetSessionWithMessages(context.Background(), "missing")
+	if !memoryx.IsSessionNotFound(err) {
+		t.Fatalf("GetSessionWithMessages of a missing session: got %v, want session not found", err)
+	}
+}
+
+func testUpdateSession(t *testing.T, repo memoryx.SessionRepository) {
+	ctx := context.Background()
+	session := createSession(t, repo, "user-1", time.Now())
+
+	session.Title = "Renamed"
+	session.Summary = "- the user likes cats"
+	session.SummaryCount = 4
+	session.UpdatedAt = session.UpdatedAt.Add(time.Minute)
+	if err := repo.UpdateSession(ctx, session); err != nil {
+		t.Fatalf("UpdateSession: %v", err)
+	}
+
+	got, err := repo.GetSession(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	assertSession(t, got, session)
+}
+
+func testUpdateMissingSession(t *testing.T, repo memoryx.SessionRepository) {
+	now := time.Now()
+	session := &memoryx.Session{ID: "missing", UserID: "user-1", CreatedAt: now, UpdatedAt: now, IsActive: true}
+	if err := repo.UpdateSession(context.Background(), session); !memoryx.IsSessionNotFound(err) {
+		t.Fatalf("UpdateSession of a missing session: got %v, want session not found", err)
+	}
+}
+
+func testListUserSessions(t *testing.T, repo memoryx.SessionRepository) {
+	ctx := context.Background()
+	now := time.Now()
+
+	oldest := createSession(t, repo, "user-1", now.Add(-3*time.Minute))
+	middle := createSession(t, repo, "user-1", now.Add(-2*time.Minute))
+	newest := createSession(t, repo, "user-1", now.Add(-time.Minute))
+	createSession(t, repo, "user-2", now)
+
+	sessions, err := repo.ListUserSessions(ctx, "user-1", 10, 0)
+	if err != nil {
+		t.Fatalf("ListUserSessions: %v", err)
+	}
+	assertIDs(t, sessions, newest.ID, middle.ID, oldest.ID)
+
+	sessions, err = repo.ListUserSessions(ctx, "user-1", 1, 1)
+	if err != nil {
+		t.Fatalf("ListUserSessions with offset: %v", err)
+	}
+	assertIDs(t, sessions, middle.ID)
+
+	sessions, err = repo.ListUserSessions(ctx, "nobody", 10, 0)
+	if err != nil {
+		t.Fatalf("ListUserSessions for an unknown user: %v", err)
+	}
+	assertIDs(t, sessions)
+}
+
+func testDeleteSession(t *testing.T, repo memoryx.SessionRepository) {
+	ctx := context.Background()
+	kept := createSession(t, repo, "user-1", time.Now().Add(-time.Minute))
+	deleted := createSession(t, repo, "user-1", time.Now())
+
+	if err := repo.DeleteSession(ctx, deleted.ID); err != nil {
+		t.Fatalf("DeleteSession: %v", err)
+	}
+
+	// Soft deletes keep the session inactive; others remove it
+	got, err := repo.GetSession(ctx, deleted.ID)
+	switch {
+	case memoryx.IsSessionNotFound(err):
+	case err != nil:
+		t.Fatalf("GetSession after delete: %v", err)
+	case got.IsActive:
+		t.Fatal("deleted session is still active")
+	}
+
+	sessions, err := repo.ListUserSessions(ctx, "user-1", 10, 0)
+	if err != nil {
+		t.Fatalf("ListUserSessions: %v", err)
+	}
+	assertIDs(t, sessions, kept.ID)
+}
+
+func testMessages(t *testing.T, repo memoryx.SessionRepository) {
+	ctx := context.Background()
+	session := createSession(t, repo, "user-1", time.Now())
+
+	want := []llm.Message{
+		llm.NewSystemMessage("You are helpful"),
+		llm.NewUserMessageWithParts("What is in this image?", llm.ImageURLPart("https://example.com/cat.png")),
+		{
+			Role:      llm.RoleAssistant,
+			ToolCalls: []llm.ToolCall{{ID: "call_1", Type: "function", Function: llm.FunctionCall{Name: "lookup", Arguments: `{"q":"cat"}`}}},
+		},
+		{Role: llm.RoleTool, Content: `{"animal":"cat"}`, ToolCallID: "call_1"},
+		{Role: llm.RoleAssistant, Content: "A cat", Metadata: map[string]any{"interrupted": true}},
+	}
+	ids := addMessages(t, repo, session.ID, want)
+
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			t.Fatalf("message IDs are not increasing: %v", ids)
+		}
+	}
+
+	messages, err := repo.GetMessages(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	assertMessages(t, messages, want)
+
+	count, err := repo.GetMessageCount(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("GetMessageCount: %v", err)
+	}
+	if count != len(want) {
+		t.Fatalf("GetMessageCount = %d, want %d", count, len(want))
+	}
+
+	withMessages, err := repo.GetSessionWithMessages(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("GetSessionWithMessages: %v", err)
+	}
+	if withMessages.Session.ID != session.ID {
+		t.Fatalf("GetSessionWithMessages returned session %q, want %q", withMessages.Session.ID, session.ID)
+	}
+	assertMessages(t, withMessages.Messages, want)
+
+	// Messages of other sessions stay apart
+	other := createSession(t, repo, "user-1", time.Now())
+	messages, err = repo.GetMessages(ctx, other.ID)
+	if err != nil {
+		t.Fatalf("GetMessages of another session: %v", err)
+	}
+	if len(messages) != 0 {
+		t.Fatalf("another session has %d messages, want 0", len(messages))
+	}
+}
+
+func testAddMessageToMissingSession(t *testing.T, repo memoryx.SessionRepository) {
+	message, err := memoryx.FromLLMMessage("missing", llm.NewUserMessage("hello"))
+	if err != nil {
+		t.Fatalf("FromLLMMessage: %v", err)
+	}
+	if err := repo.AddMessage(context.Background(), &message); err == nil {
+		t.Fatal("AddMessage to a missing session succeeded")
+	}
+}
+
+func testClearMessages(t *testing.T, repo memoryx.SessionRepository) {
+	ctx := context.Background()
+	session := createSession(t, repo, "user-1", time.Now())
+	addMessages(t, repo, session.ID, []llm.Message{
+		llm.NewSystemMessage("You are helpful"),
+		llm.NewUserMessage("Hi"),
+		llm.NewAssistantMessage("Hello"),
+	})
+
+	session.Summary = "- greeted"
+	session.SummaryCount = 2
+	if err := repo.UpdateSession(ctx, session); err != nil {
+		t.Fatalf("UpdateSession: %v", err)
+	}
+
+	if err := repo.ClearMessages(ctx, session.ID); err != nil {
+		t.Fatalf("ClearMessages: %v", err)
+	}
+
+	messages, err := repo.GetMessages(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	assertMessages(t, messages, []llm.Message{llm.NewSystemMessage("You are helpful")})
+
+	got, err := repo.GetSession(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	if got.Summary != "" || got.SummaryCount != 0 {
+		t.Fatalf("summary after clear = %q (%d messages), want it reset", got.Summary, got.SummaryCount)
+	}
+}
+
+// flusher is implemented by repositories that move sessions to another store
+type flusher interface {
+	FlushSession(ctx context.Context, sessionID memoryx.SessionID) error
+}
+
+// testClearFlushedSession clears a session after it was flushed, and after it was
+// loaded back; for other repositories the flushes are no-ops
+func testClearFlushedSession(t *testing.T, repo memoryx.SessionRepository) {
+	ctx := context.Background()
+	session := createSession(t, repo, "user-1", time.Now())
+	flush := func() {
+		t.Helper()
+		if f, ok := repo.(flusher); ok {
+			if err := f.FlushSession(ctx, session.ID); err != nil {
+				t.Fatalf("FlushSession: %v", err)
+			}
+		}
+	}
+	assertHistory := func(want ...llm.Message) {
+		t.Helper()
+		messages, err := repo.GetMessages(ctx, session.ID)
+		if err != nil {
+			t.Fatalf("GetMessages: %v", err)
+		}
+		assertMessages(t, messages, want)
+	}
+	system := llm.NewSystemMessage("You are helpful")
+
+	addMessages(t, repo, session.ID, []llm.Message{system, llm.NewUserMessage("Hi"), llm.NewAssistantMessage("Hello")})
+	flush()
+	if err := repo.ClearMessages(ctx, session.ID); err != nil {
+		t.Fatalf("ClearMessages of a flushed session: %v", err)
+	}
+	assertHistory(system)
+
+	// The conversation continues and is flushed again
+	addMessages(t, repo, session.ID, []llm.Message{llm.NewUserMessage("Start over")})
+	flush()
+	assertHistory(system, llm.NewUserMessage("Start over"))
+
+	// Cleared after it was loaded back; the new history replaces the flushed one
+	if err := repo.ClearMessages(ctx, session.ID); err != nil {
+		t.Fatalf("ClearMessages: %v", err)
+	}
+	addMessages(t, repo, session.ID, []llm.Message{llm.NewUserMessage("Third try")})
+	flush()
+	assertHistory(system, llm.NewUserMessage("Third try"))
+}
+
+// testSessionMemory runs the repository behind memoryx.SessionMemory
+func testSessionMemory(t *testing.T, repo memoryx.SessionRepository) {
+	ctx := context.Background()
+	session := createSession(t, repo, "user-1", time.Now())
+	memory := memoryx.NewSessionMemory(ctx, session.ID, repo)
+
+	want := []llm.Message{
+		llm.NewSystemMessage("You are helpful"),
+		llm.NewUserMessage("Hi"),
+		llm.NewAssistantMessage("Hello"),
+	}
+	for _, msg := range want {
+		if err := memory.Add(msg); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	got, err := memory.Messages()
+	if err != nil {
+		t.Fatalf("Messages: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Messages returned %d messages, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Role != want[i].Role || got[i].Content != want[i].Content {
+			t.Fatalf("message %d = %s %q, want %s %q", i, got[i].Role, got[i].Content, want[i].Role, want[i].Content)
+		}
+	}
+
+	store := memory.(memoryx.SummaryStore)
+	if err := store.SaveSummary(memoryx.Summary{Content: "- greeted", Count: 2}); err != nil {
+		t.Fatalf("SaveSummary: %v", err)
+	}
+	summary, err := store.LoadSummary()
+	if err != nil {
+		t.Fatalf("LoadSummary: %v", err)
+	}
+	if summary.Content != "- greeted" || summary.Count != 2 {
+		t.Fatalf("LoadSummary = %+v, want the saved summary", summary)
+	}
+}
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+func createSession(t *testing.T, repo memoryx.SessionRepository, userID string, updatedAt time.Time) *memoryx.Session {
+	t.Helper()
+
+	session := &memoryx.Session{
+		ID:        memoryx.NewSessionID(),
+		UserID:    userID,
+		Title:     "Chat",
+		SystemMsg: "You are helpful",
+		CreatedAt: updatedAt,
+		UpdatedAt: updatedAt,
+		IsActive:  true,
+	}
+	if err := repo.CreateSession(context.Background(), session); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	return session
+}
+
+func addMessages(t *testing.T, repo memoryx.SessionRepository, sessionID memoryx.SessionID, messages []llm.Message) []int64 {
+	t.Helper()
+
+	ids := make([]int64, 0, len(messages))
+	for i, msg := range messages {
+		message, err := memoryx.FromLLMMessage(sessionID, msg)
+		if err != nil {
+			t.Fatalf("FromLLMMessage: %v", err)
+		}
+		// Distinct timestamps keep the order unambiguous for stores ordering by time
+		message.CreatedAt = message.CreatedAt.Add(time.Duration(i) * time.Millisecond)
+
+		if err := repo.AddMessage(context.Background(), &message); err != nil {
+			t.Fatalf("AddMessage: %v", err)
+		}
+		if message.ID == 0 {
+			t.Fatal("AddMessage did not assign an ID")
+		}
+		ids = append(ids, message.ID)
+	}
+	return ids
+}
+
+func assertSession(t *testing.T, got, want *memoryx.Session) {
+	t.Helper()
+
+	if got.ID != want.ID || got.UserID != want.UserID || got.Title != want.Title || got.SystemMsg != want.SystemMsg {
+		t.Fatalf("session = %+v, want %+v", got, want)
+	}
+	if got.IsActive != want.IsActive {
+		t.Fatalf("session active = %v, want %v", got.IsActive, want.IsActive)
+	}
+	if got.Summary != want.Summary || got.SummaryCount != want.SummaryCount {
+		t.Fatalf("session summary = %q (%d messages), want %q (%d messages)", got.Summary, got.SummaryCount, want.Summary, want.SummaryCount)
+	}
+	assertTime(t, "created_at", got.CreatedAt, want.CreatedAt)
+	assertTime(t, "updated_at", got.UpdatedAt, want.UpdatedAt)
+}
+
+// assertTime allows for stores that keep microseconds or drop the monotonic clock
+func assertTime(t *testing.T, field string, got, want time.Time) {
+	t.Helper()
+
+	if diff := got.Sub(want); diff > time.Millisecond || diff < -time.Millisecond {
+		t.Fatalf("session %s = %s, want %s", field, got, want)
+	}
+}
+
+func assertIDs(t *testing.T, sessions []*memoryx.Session, want ...memoryx.SessionID) {
+	t.Helper()
+
+	got := make([]memoryx.SessionID, 0, len(sessions))
+	for _, session := range sessions {
+		got = append(got, session.ID)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("sessions = %v, want %v", got, want)
+	}
+}
+
+func assertMessages(t *testing.T, got []memoryx.SessionMessage, want []llm.Message) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d messages, want %d", len(got), len(want))
+	}
+	for i := range want {
+		msg, err := got[i].ToLLMMessage()
+		if err != nil {
+			t.Fatalf("message %d: ToLLMMessage: %v", i, err)
+		}
+		if gotJSON, wantJSON := toJSON(t, msg), toJSON(t, want[i]); gotJSON != wantJSON {
+			t.Fatalf("message %d = %s, want %s", i, gotJSON, wantJSON)
+		}
+	}
+}
+
+func toJSON(t *testing.T, v any) string {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return string(data)
+}
@@ -1,6 +1,9 @@
 package memoryx
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // SessionRepository manages session persistence
 type SessionRepository interface {
@@ -18,3 +21,10 @@ type SessionRepository interface {
 	ClearMessages(ctx context.Context, sessionID SessionID) error
 	GetMessageCount(ctx context.Context, sessionID SessionID) (int, error)
 }
+
+// ToolCallRepository manages the tool execution audit trail
+type ToolCallRepository interface {
+	RecordToolCall(ctx context.Context, record *ToolCallRecord) error
+	ListToolCalls(ctx context.Context, filter ToolCallFilter) ([]*ToolCallRecord, error)
+	DeleteToolCallsBefore(ctx context.Context, before time.Time) (int64, error)
+}
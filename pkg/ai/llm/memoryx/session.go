@@ -20,6 +20,10 @@ type Session struct {
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 	IsActive  bool      `json:"is_active" db:"is_active"`
+
+	// Running summary of older messages (see SummaryMemory)
+	Summary      string `json:"summary,omitempty" db:"summary"`
+	SummaryCount int    `json:"summary_count,omitempty" db:"summary_count"` // Messages the summary covers
 }
 
 // SessionMessage represents a message in a session
@@ -28,8 +32,10 @@ type SessionMessage struct {
 	SessionID  SessionID `json:"session_id" db:"session_id"`
 	Role       string    `json:"role" db:"role"`
 	Content    string    `json:"content" db:"content"`
+	Parts      string    `json:"parts,omitempty" db:"parts"`           // JSON serialized content parts
 	ToolCalls  string    `json:"tool_calls,omitempty" db:"tool_calls"` // JSON serialized
 	ToolCallID string    `json:"tool_call_id,omitempty" db:"tool_call_id"`
+	Metadata   string    `json:"metadata,omitempty" db:"metadata"` // JSON serialized
 	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 }
 
@@ -61,6 +67,24 @@ func (sm *SessionMessage) ToLLMMessage() (llm.Message, error) {
 		msg.ToolCalls = toolCalls
 	}
 
+	// Deserialize content parts if present
+	if sm.Parts != "" {
+		var parts []llm.ContentPart
+		if err := json.Unmarshal([]byte(sm.Parts), &parts); err != nil {
+			return msg, err
+		}
+		msg.Parts = parts
+	}
+
+	// Deserialize metadata if present
+	if sm.Metadata != "" {
+		var metadata map[string]any
+		if err := json.Unmarshal([]byte(sm.Metadata), &metadata); err != nil {
+			return msg, err
+		}
+		msg.Metadata = metadata
+	}
+
 	return msg, nil
 }
 
@@ -83,6 +107,24 @@ func FromLLMMessage(sessionID SessionID, msg llm.Message) (SessionMessage, error
 		sm.ToolCalls = string(toolCallsJSON)
 	}
 
+	// Serialize content parts if present
+	if len(msg.Parts) > 0 {
+		partsJSON, err := json.Marshal(msg.Parts)
+		if err != nil {
+			return sm, err
+		}
+		sm.Parts = string(partsJSON)
+	}
+
+	// Serialize metadata if present
+	if len(msg.Metadata) > 0 {
+		metadataJSON, err := json.Marshal(msg.Metadata)
+		if err != nil {
+			return sm, err
+		}
+		sm.Metadata = string(metadataJSON)
+	}
+
 	return sm, nil
 }
 
@@ -3,6 +3,8 @@ package memoryx
 
 import (
 	"context"
+	"time"
+
 	"github.com/Abraxas-365/ams/pkg/ai/llm"
 	"github.com/Abraxas-365/ams/pkg/logx"
 )
@@ -92,3 +94,25 @@ func (m *SessionMemory) Clear() error {
 func (m *SessionMemory) GetSessionID() SessionID {
 	return m.sessionID
 }
+
+// LoadSummary returns the summary stored with the session
+func (m *SessionMemory) LoadSummary() (Summary, error) {
+	session, err := m.repository.GetSession(m.ctx, m.sessionID)
+	if err != nil {
+		return Summary{}, err
+	}
+	return Summary{Content: session.Summary, Count: session.SummaryCount}, nil
+}
+
+// SaveSummary stores the summary with the session
+func (m *SessionMemory) SaveSummary(summary Summary) error {
+	session, err := m.repository.GetSession(m.ctx, m.sessionID)
+	if err != nil {
+		return err
+	}
+
+	session.Summary = summary.Content
+	session.SummaryCount = summary.Count
+	session.UpdatedAt = time.Now()
+	return m.repository.UpdateSession(m.ctx, session)
+}
@@ -0,0 +1,303 @@
+package memoryx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/tokenizer"
+	"github.com/Abraxas-365/ams/pkg/logx"
+)
+
+// Summary is a running summary of the oldest conversation messages
+type Summary struct {
+	Content string
+	Count   int // Messages covered, counted after the leading system messages
+}
+
+// SummaryStore persists the summary of a memory (SessionMemory stores it with the session)
+type SummaryStore interface {
+	LoadSummary() (Summary, error)
+	SaveSummary(summary Summary) error
+}
+
+// summaryPrompt instructs the model that maintains the summary
+const summaryPrompt = `You maintain a running summary of a conversation between a user and an assistant.
+Merge the previous summary with the new messages into one updated summary.
+Keep facts, names, IDs, amounts, dates, decisions, tool results and open questions the conversation may rely on later.
+Drop greetings and small talk. Write concise bullet points and reply with the summary only.`
+
+// summaryHeader introduces the summary in the conversation sent to the model
+const summaryHeader = "Summary of the earlier conversation:\n"
+
+// SummaryMemory wraps a memory and, once the history exceeds a token
+// threshold, replaces older turns with an LLM-written running summary.
+// Recent turns stay verbatim; turns are never split, so tool calls stay
+// paired with their results. The wrapped memory keeps the full history
+type SummaryMemory struct {
+	mu            sync.Mutex
+	ctx           context.Context
+	memory        Memory
+	llm           llm.LLM
+	store         SummaryStore
+	counter       tokenizer.Counter
+	maxTokens     int
+	recentTokens  int
+	summaryOpts   []llm.Option
+	summary       Summary
+	summaryLoaded bool
+}
+
+// SummaryMemoryOption configures summary memory
+type SummaryMemoryOption func(*SummaryMemory)
+
+// WithSummaryThreshold sets the history size in tokens that triggers summarization (default 8000)
+func WithSummaryThreshold(tokens int) SummaryMemoryOption {
+	return func(m *SummaryMemory) {
+		m.maxTokens = tokens
+	}
+}
+
+// WithRecentTokens sets how many tokens of recent turns stay verbatim (default 2000)
+// The latest turn is always kept, even when larger
+func WithRecentTokens(tokens int) SummaryMemoryOption {
+	return func(m *SummaryMemory) {
+		m.recentTokens = tokens
+	}
+}
+
+// WithSummaryOptions sets the LLM options for summarization calls (e.g. a cheaper model)
+func WithSummaryOptions(opts ...llm.Option) SummaryMemoryOption {
+	return func(m *SummaryMemory) {
+		m.summaryOpts = opts
+	}
+}
+
+// WithTokenCounter sets the counter used for the threshold (default: for the summary model)
+func WithTokenCounter(counter tokenizer.Counter) SummaryMemoryOption {
+	return func(m *SummaryMemory) {
+		m.counter = counter
+	}
+}
+
+// WithSummaryStore persists the summary; memories implementing SummaryStore are used by default
+func WithSummaryStore(store SummaryStore) SummaryMemoryOption {
+	return func(m *SummaryMemory) {
+		m.store = store
+	}
+}
+
+// NewSummaryMemory wraps memory with summarization using client
+func NewSummaryMemory(ctx context.Context, memory Memory, client llm.LLM, opts ...SummaryMemoryOption) *SummaryMemory {
+	m := &SummaryMemory{
+		ctx:          ctx,
+		memory:       memory,
+		llm:          client,
+		maxTokens:    8000,
+		recentTokens: 2000,
+	}
+	if store, ok := memory.(SummaryStore); ok {
+		m.store = store
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if m.counter == nil {
+		options := llm.DefaultOptions()
+		for _, opt := range m.summaryOpts {
+			opt(options)
+		}
+		m.counter = tokenizer.ForModel(options.Model)
+	}
+	return m
+}
+
+// Messages returns the system prompt, the summary and the verbatim messages,
+// summarizing first when the history is over the threshold
+func (m *SummaryMemory) Messages() ([]llm.Message, error) {
+	all, err := m.memory.Messages()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.loadSummary()
+
+	head, rest := splitSystemPrompt(all)
+	if m.summary.Count > len(rest) {
+		// The wrapped memory was cleared or trimmed underneath us
+		m.summary = Summary{}
+	}
+	pending := rest[m.summary.Count:]
+
+	if m.counter.CountMessages(m.compose(head, pending), nil) > m.maxTokens {
+		if cut := m.cutPoint(pending); cut > 0 {
+			if err := m.summarize(pending[:cut]); err != nil {
+				// Better an expensive turn than a failed one
+				logx.WithError(err).Warn("Failed to summarize conversation, sending full history")
+			} else {
+				pending = pending[cut:]
+			}
+		}
+	}
+
+	return m.compose(head, pending), nil
+}
+
+// Add adds a message to the wrapped memory
+func (m *SummaryMemory) Add(message llm.Message) error {
+	return m.memory.Add(message)
+}
+
+// Clear clears the wrapped memory and the summary
+func (m *SummaryMemory) Clear() error {
+	if err := m.memory.Clear(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.summary = Summary{}
+	m.summaryLoaded = true
+	return nil
+}
+
+// Summary returns the current summary
+func (m *SummaryMemory) Summary() Summary {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.loadSummary()
+	return m.summary
+}
+
+func (m *SummaryMemory) loadSummary() {
+	if m.summaryLoaded {
+		return
+	}
+	m.summaryLoaded = true
+
+	if m.store == nil {
+		return
+	}
+	summary, err := m.store.LoadSummary()
+	if err != nil {
+		logx.WithError(err).Warn("Failed to load conversation summary")
+		return
+	}
+	m.summary = summary
+}
+
+// compose builds the conversation sent to the model
+func (m *SummaryMemory) compose(head, pending []llm.Message) []llm.Message {
+	messages := make([]llm.Message, 0, len(head)+len(pending)+1)
+	messages = append(messages, head...)
+	if m.summary.Content != "" {
+		messages = append(messages, llm.NewSystemMessage(summaryHeader+m.summary.Content))
+	}
+	return append(messages, pending...)
+}
+
+// cutPoint returns how many pending messages to summarize: everything before
+// the oldest user turn whose tail fits the recent budget, keeping at least
+// the latest turn
+func (m *SummaryMemory) cutPoint(pending []llm.Message) int {
+	cut := 0
+	for i := len(pending) - 1; i > 0; i-- {
+		if pending[i].Role != llm.RoleUser {
+			continue
+		}
+		if cut > 0 && m.counter.CountMessages(pending[i:], nil) > m.recentTokens {
+			break
+		}
+		cut = i
+	}
+	return cut
+}
+
+// summarize folds messages into the running summary and persists it
+func (m *SummaryMemory) summarize(messages []llm.Message) error {
+	var transcript strings.Builder
+	if m.summary.Content != "" {
+		transcript.WriteString("Previous summary:\n")
+		transcript.WriteString(m.summary.Content)
+		transcript.WriteString("\n\n")
+	}
+	transcript.WriteString("New messages:\n")
+	writeTranscript(&transcript, messages)
+
+	response, err := m.llm.Chat(m.ctx, []llm.Message{
+		llm.NewSystemMessage(summaryPrompt),
+		llm.NewUserMessage(transcript.String()),
+	}, m.summaryOpts...)
+	if err != nil {
+		return err
+	}
+
+	content := strings.TrimSpace(response.Message.Content)
+	if content == "" {
+		return fmt.Errorf("summary model returned no content")
+	}
+
+	m.summary = Summary{Content: content, Count: m.summary.Count + len(messages)}
+	logx.WithFields(logx.Fields{
+		"summarized": len(messages),
+		"covered":    m.summary.Count,
+	}).Info("Conversation summarized")
+
+	if m.store != nil {
+		if err := m.store.SaveSummary(m.summary); err != nil {
+			logx.WithError(err).Warn("Failed to save conversation summary")
+		}
+	}
+	return nil
+}
+
+// splitSystemPrompt separates the leading system messages from the conversation
+func splitSystemPrompt(messages []llm.Message) ([]llm.Message, []llm.Message) {
+	i := 0
+	for i < len(messages) && messages[i].Role == llm.RoleSystem {
+		i++
+	}
+	return messages[:i], messages[i:]
+}
+
+// writeTranscript renders messages as plain text for the summary model
+func writeTranscript(b *strings.Builder, messages []llm.Message) {
+	for _, msg := range messages {
+		switch {
+		case msg.Role == llm.RoleTool:
+			fmt.Fprintf(b, "tool result: %s\n", msg.Content)
+		case len(msg.ToolCalls) > 0:
+			if msg.Content != "" {
+				fmt.Fprintf(b, "%s: %s\n", msg.Role, msg.Content)
+			}
+			for _, tc := range msg.ToolCalls {
+				fmt.Fprintf(b, "%s called %s(%s)\n", msg.Role, tc.Function.Name, tc.Function.Arguments)
+			}
+		default:
+			fmt.Fprintf(b, "%s: %s\n", msg.Role, msg.Content)
+		}
+		for _, part := range msg.Parts {
+			if part.Filename != "" || part.Path != "" {
+				fmt.Fprintf(b, "%s attached %s\n", msg.Role, firstNonEmpty(part.Filename, part.Path))
+			} else if part.IsImage() {
+				fmt.Fprintf(b, "%s attached an image\n", msg.Role)
+			}
+		}
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,185 @@
+package memoryx
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/llmtest"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/tokenizer"
+)
+
+// wordCounter counts one token per word, without chat format overhead
+func wordCounter() tokenizer.Counter {
+	return tokenizer.NewCounter(func(text string) int { return len(strings.Fields(text)) }, tokenizer.Overhead{})
+}
+
+// stubSummaryStore keeps the summary in memory
+type stubSummaryStore struct {
+	summary Summary
+	saves   int
+}
+
+func (s *stubSummaryStore) LoadSummary() (Summary, error) {
+	return s.summary, nil
+}
+
+func (s *stubSummaryStore) SaveSummary(summary Summary) error {
+	s.summary = summary
+	s.saves++
+	return nil
+}
+
+func toolCall(id string) llm.Message {
+	return llm.Message{
+		Role:      llm.RoleAssistant,
+		ToolCalls: []llm.ToolCall{{ID: id, Type: "function", Function: llm.FunctionCall{Name: "lookup", Arguments: "{}"}}},
+	}
+}
+
+// threeTurns is three user/assistant turns of 3 tokens per message
+func threeTurns() []llm.Message {
+	return []llm.Message{
+		llm.NewUserMessage("first question"),
+		llm.NewAssistantMessage("first answer"),
+		llm.NewUserMessage("second question"),
+		llm.NewAssistantMessage("second answer"),
+		llm.NewUserMessage("third question"),
+		llm.NewAssistantMessage("third answer"),
+	}
+}
+
+func newTestSummaryMemory(t *testing.T, client llm.LLM, messages []llm.Message, opts ...SummaryMemoryOption) *SummaryMemory {
+	t.Helper()
+
+	buffer := NewBufferMemory(llm.NewSystemMessage("be brief"))
+	for _, msg := range messages {
+		if err := buffer.Add(msg); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+	return NewSummaryMemory(context.Background(), buffer, client, append([]SummaryMemoryOption{WithTokenCounter(wordCounter())}, opts...)...)
+}
+
+func TestCutPoint(t *testing.T) {
+	tests := []struct {
+		name         string
+		pending      []llm.Message
+		recentTokens int
+		want         int
+	}{
+		{"recent budget keeps last turn", threeTurns(), 10, 4},
+		{"recent budget keeps two turns", threeTurns(), 12, 2},
+		{"latest turn kept over budget", threeTurns(), 0, 4},
+		{"first turn is always summarized", threeTurns(), 100, 2},
+		{"single turn", threeTurns()[:2], 0, 0},
+		{
+			"tool results stay with their turn",
+			[]llm.Message{
+				llm.NewUserMessage("first question"),
+				llm.NewAssistantMessage("first answer"),
+				llm.NewUserMessage("look it up"),
+				toolCall("call_1"),
+				{Role: llm.RoleTool, Content: "found", ToolCallID: "call_1"},
+				llm.NewAssistantMessage("here it is"),
+			},
+			0, 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestSummaryMemory(t, llmtest.New(), nil, WithRecentTokens(tt.recentTokens))
+			if got := m.cutPoint(tt.pending); got != tt.want {
+				t.Errorf("cutPoint = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummaryMemoryBelowThreshold(t *testing.T) {
+	fake := llmtest.New()
+	m := newTestSummaryMemory(t, fake, threeTurns(), WithSummaryThreshold(100))
+
+	messages, err := m.Messages()
+	if err != nil {
+		t.Fatalf("Messages: %v", err)
+	}
+	if len(messages) != 7 {
+		t.Errorf("messages = %d, want the system prompt and all 6 turns", len(messages))
+	}
+	if fake.CallCount() != 0 {
+		t.Errorf("summary model called %d times below the threshold", fake.CallCount())
+	}
+}
+
+func TestSummaryMemorySummarizesOverThreshold(t *testing.T) {
+	fake := llmtest.New()
+	fake.On().Reply("- asked two questions")
+	store := &stubSummaryStore{}
+	m := newTestSummaryMemory(t, fake, threeTurns(),
+		WithSummaryThreshold(20), WithRecentTokens(6), WithSummaryStore(store))
+
+	messages, err := m.Messages()
+	if err != nil {
+		t.Fatalf("Messages: %v", err)
+	}
+
+	if fake.CallCount() != 1 {
+		t.Fatalf("summary model called %d times, want 1", fake.CallCount())
+	}
+	request, _ := fake.LastRequest()
+	if transcript := request.LastMessage().Content; !strings.Contains(transcript, "user: second question") ||
+		strings.Contains(transcript, "third question") {
+		t.Errorf("transcript = %q, want the first two turns only", transcript)
+	}
+
+	if len(messages) != 4 {
+		t.Fatalf("messages = %+v, want system prompt, summary and the last turn", messages)
+	}
+	if messages[1].Role != llm.RoleSystem || messages[1].Content != summaryHeader+"- asked two questions" {
+		t.Errorf("summary message = %+v", messages[1])
+	}
+	if messages[2].Content != "third question" {
+		t.Errorf("first verbatim message = %q", messages[2].Content)
+	}
+
+	if store.saves != 1 || store.summary != (Summary{Content: "- asked two questions", Count: 4}) {
+		t.Errorf("saved summary = %+v (%d saves)", store.summary, store.saves)
+	}
+}
+
+func TestSummaryMemoryLoadsPersistedSummary(t *testing.T) {
+	fake := llmtest.New()
+	store := &stubSummaryStore{summary: Summary{Content: "- asked two questions", Count: 4}}
+	m := newTestSummaryMemory(t, fake, threeTurns(), WithSummaryThreshold(20), WithSummaryStore(store))
+
+	messages, err := m.Messages()
+	if err != nil {
+		t.Fatalf("Messages: %v", err)
+	}
+	if fake.CallCount() != 0 {
+		t.Errorf("summary model called %d times with a persisted summary under the threshold", fake.CallCount())
+	}
+	if len(messages) != 4 || messages[1].Content != summaryHeader+"- asked two questions" || messages[2].Content != "third question" {
+		t.Errorf("messages = %+v", messages)
+	}
+}
+
+func TestSummaryMemoryFailedSummarySendsFullHistory(t *testing.T) {
+	fake := llmtest.New()
+	fake.On().Reply("")
+	store := &stubSummaryStore{}
+	m := newTestSummaryMemory(t, fake, threeTurns(), WithSummaryThreshold(20), WithSummaryStore(store))
+
+	messages, err := m.Messages()
+	if err != nil {
+		t.Fatalf("Messages: %v", err)
+	}
+	if len(messages) != 7 {
+		t.Errorf("messages = %d, want the full history", len(messages))
+	}
+	if store.saves != 0 {
+		t.Errorf("empty summary was saved")
+	}
+}
@@ -0,0 +1,35 @@
+package memoryx
+
+import "time"
+
+// ToolCallRecord is an audit entry for a single tool execution
+type ToolCallRecord struct {
+	ID         int64     `json:"id" db:"id"`
+	SessionID  SessionID `json:"session_id,omitempty" db:"session_id"`
+	UserID     string    `json:"user_id,omitempty" db:"user_id"`
+	TenantID   string    `json:"tenant_id,omitempty" db:"tenant_id"`
+	Route      string    `json:"route,omitempty" db:"route"`
+	ToolName   string    `json:"tool_name" db:"tool_name"`
+	Arguments  string    `json:"arguments,omitempty" db:"arguments"` // JSON serialized, secrets redacted
+	HTTPStatus int       `json:"http_status" db:"http_status"`
+	LatencyMs  int64     `json:"latency_ms" db:"latency_ms"`
+	ResultSize int       `json:"result_size" db:"result_size"` // Response body size in bytes
+	Error      string    `json:"error,omitempty" db:"error"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// ToolCallFilter narrows a tool call audit query
+type ToolCallFilter struct {
+	SessionID SessionID
+	UserID    string
+	ToolName  string
+	From      *time.Time
+	To        *time.Time
+	Limit     int
+	Offset    int
+}
+
+// Succeeded reports whether the tool call completed without error
+func (r *ToolCallRecord) Succeeded() bool {
+	return r.Error == ""
+}
@@ -0,0 +1,118 @@
+package memoryx
+
+import (
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/tokenizer"
+	"github.com/Abraxas-365/ams/pkg/logx"
+)
+
+// TokenWindowMemory wraps a memory and sends only the most recent messages
+// that fit a token budget. Leading system messages are always kept, and an
+// assistant tool-call message is kept or dropped together with its tool
+// results, so the window is always a valid conversation. The wrapped memory
+// keeps the full history
+type TokenWindowMemory struct {
+	memory    Memory
+	maxTokens int
+	counter   tokenizer.Counter
+}
+
+// TokenWindowOption configures token window memory
+type TokenWindowOption func(*TokenWindowMemory)
+
+// WithWindowCounter sets the counter used for the budget (default: for the default model)
+func WithWindowCounter(counter tokenizer.Counter) TokenWindowOption {
+	return func(m *TokenWindowMemory) {
+		m.counter = counter
+	}
+}
+
+// NewTokenWindowMemory wraps memory with a window of maxTokens
+func NewTokenWindowMemory(memory Memory, maxTokens int, opts ...TokenWindowOption) *TokenWindowMemory {
+	m := &TokenWindowMemory{
+		memory:    memory,
+		maxTokens: maxTokens,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if m.counter == nil {
+		m.counter = tokenizer.ForModel(llm.DefaultOptions().Model)
+	}
+	return m
+}
+
+// Messages returns the system prompt and the most recent messages within the budget
+// The newest message (with its tool results) is always kept, even when it alone exceeds the budget
+func (m *TokenWindowMemory) Messages() ([]llm.Message, error) {
+	all, err := m.memory.Messages()
+	if err != nil {
+		return nil, err
+	}
+
+	head, rest := splitSystemPrompt(all)
+	blocks := toolCallBlocks(rest)
+
+	// Walk back from the newest block; block counts include reply priming,
+	// so the window errs on the small side
+	budget := m.maxTokens - m.counter.CountMessages(head, nil)
+	start := len(blocks)
+	for start > 0 {
+		cost := m.counter.CountMessages(blocks[start-1], nil)
+		if start < len(blocks) && cost > budget {
+			break
+		}
+		budget -= cost
+		start--
+	}
+
+	messages := make([]llm.Message, 0, len(all))
+	messages = append(messages, head...)
+	for _, block := range blocks[start:] {
+		messages = append(messages, block...)
+	}
+
+	if dropped := len(all) - len(messages); dropped > 0 {
+		logx.WithFields(logx.Fields{
+			"dropped":    dropped,
+			"kept":       len(messages),
+			"max_tokens": m.maxTokens,
+		}).Debug("Trimmed conversation to token window")
+	}
+	return messages, nil
+}
+
+// Add adds a message to the wrapped memory
+func (m *TokenWindowMemory) Add(message llm.Message) error {
+	return m.memory.Add(message)
+}
+
+// Clear clears the wrapped memory
+func (m *TokenWindowMemory) Clear() error {
+	return m.memory.Clear()
+}
+
+// toolCallBlocks groups messages into units that can be dropped independently:
+// an assistant tool-call message with its tool results, or a single message
+// Tool results without their call (already trimmed upstream) are discarded
+func toolCallBlocks(messages []llm.Message) [][]llm.Message {
+	blocks := make([][]llm.Message, 0, len(messages))
+	for i := 0; i < len(messages); i++ {
+		msg := messages[i]
+		if msg.Role == llm.RoleTool {
+			continue
+		}
+
+		end := i + 1
+		if msg.Role == llm.RoleAssistant && len(msg.ToolCalls) > 0 {
+			for end < len(messages) && messages[end].Role == llm.RoleTool {
+				end++
+			}
+		}
+		blocks = append(blocks, messages[i:end])
+		i = end - 1
+	}
+	return blocks
+}
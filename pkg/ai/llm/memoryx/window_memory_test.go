@@ -0,0 +1,166 @@
+package memoryx
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+)
+
+func toolResult(id, content string) llm.Message {
+	return llm.Message{Role: llm.RoleTool, Content: content, ToolCallID: id}
+}
+
+func TestToolCallBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []llm.Message
+		want     [][]int // Indexes into messages
+	}{
+		{
+			"plain messages",
+			[]llm.Message{llm.NewUserMessage("q"), llm.NewAssistantMessage("a")},
+			[][]int{{0}, {1}},
+		},
+		{
+			"tool call with its results",
+			[]llm.Message{llm.NewUserMessage("q"), toolCall("call_1"), toolResult("call_1", "r1"), toolResult("call_1", "r2"), llm.NewAssistantMessage("a")},
+			[][]int{{0}, {1, 2, 3}, {4}},
+		},
+		{
+			"tool call without results",
+			[]llm.Message{toolCall("call_1"), llm.NewUserMessage("q")},
+			[][]int{{0}, {1}},
+		},
+		{
+			"orphaned leading tool results",
+			[]llm.Message{toolResult("call_1", "r1"), toolResult("call_1", "r2"), llm.NewUserMessage("q")},
+			[][]int{{2}},
+		},
+		{
+			"orphaned tool result after a plain reply",
+			[]llm.Message{llm.NewAssistantMessage("a"), toolResult("call_1", "r"), llm.NewUserMessage("q")},
+			[][]int{{0}, {2}},
+		},
+		{"empty", nil, [][]int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := make([][]llm.Message, 0, len(tt.want))
+			for _, indexes := range tt.want {
+				block := make([]llm.Message, 0, len(indexes))
+				for _, i := range indexes {
+					block = append(block, tt.messages[i])
+				}
+				want = append(want, block)
+			}
+
+			if got := toolCallBlocks(tt.messages); !reflect.DeepEqual(got, want) {
+				t.Errorf("toolCallBlocks = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestTokenWindowMessages(t *testing.T) {
+	// With the word counter: "be brief" system prompt = 3, toolCall = 3,
+	// every other message = 1 for the role plus its words
+	tests := []struct {
+		name      string
+		system    []llm.Message
+		messages  []llm.Message
+		maxTokens int
+		want      []string // Contents after the system prompt
+	}{
+		{
+			"everything fits",
+			nil,
+			[]llm.Message{llm.NewUserMessage("q1"), llm.NewAssistantMessage("a1")},
+			100,
+			[]string{"q1", "a1"},
+		},
+		{
+			"oldest messages dropped",
+			nil,
+			[]llm.Message{llm.NewUserMessage("q1"), llm.NewAssistantMessage("a1"), llm.NewUserMessage("q2"), llm.NewAssistantMessage("a2")},
+			3 + 4,
+			[]string{"q2", "a2"},
+		},
+		{
+			"newest message kept over budget",
+			nil,
+			[]llm.Message{llm.NewUserMessage("q1"), llm.NewUserMessage("one two three four five")},
+			3 + 2,
+			[]string{"one two three four five"},
+		},
+		{
+			"newest tool block kept over budget",
+			nil,
+			[]llm.Message{llm.NewUserMessage("q1"), toolCall("call_1"), toolResult("call_1", "a long tool result")},
+			3,
+			[]string{"", "a long tool result"},
+		},
+		{
+			"tool block dropped with its results",
+			nil,
+			[]llm.Message{llm.NewUserMessage("q1"), toolCall("call_1"), toolResult("call_1", "r"), llm.NewAssistantMessage("done")},
+			3 + 2 + 3,
+			[]string{"done"},
+		},
+		{
+			"tool block kept with its results",
+			nil,
+			[]llm.Message{llm.NewUserMessage("q1"), toolCall("call_1"), toolResult("call_1", "r"), llm.NewAssistantMessage("done")},
+			3 + 2 + 5,
+			[]string{"", "r", "done"},
+		},
+		{
+			"orphaned tool results dropped",
+			nil,
+			[]llm.Message{toolResult("call_0", "stale"), llm.NewUserMessage("q1"), llm.NewAssistantMessage("a1")},
+			100,
+			[]string{"q1", "a1"},
+		},
+		{
+			"system prompt kept over budget",
+			[]llm.Message{llm.NewSystemMessage("extra rules here")},
+			[]llm.Message{llm.NewUserMessage("q1"), llm.NewAssistantMessage("a1")},
+			1,
+			[]string{"a1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer := NewBufferMemory(llm.NewSystemMessage("be brief"))
+			for _, msg := range append(append([]llm.Message{}, tt.system...), tt.messages...) {
+				if err := buffer.Add(msg); err != nil {
+					t.Fatalf("Add: %v", err)
+				}
+			}
+			m := NewTokenWindowMemory(buffer, tt.maxTokens, WithWindowCounter(wordCounter()))
+
+			messages, err := m.Messages()
+			if err != nil {
+				t.Fatalf("Messages: %v", err)
+			}
+
+			head := 1 + len(tt.system)
+			if len(messages) < head || messages[0].Content != "be brief" {
+				t.Fatalf("messages = %+v, want the system prompt first", messages)
+			}
+			for i, msg := range tt.system {
+				if messages[1+i].Content != msg.Content {
+					t.Errorf("system message %d = %q, want %q", i, messages[1+i].Content, msg.Content)
+				}
+			}
+
+			got := make([]string, 0, len(messages)-head)
+			for _, msg := range messages[head:] {
+				got = append(got, msg.Content)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("window = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -13,6 +13,7 @@ const (
 type Message struct {
 	Role         string         `json:"role"`
 	Content      string         `json:"content,omitempty"`
+	Parts        []ContentPart  `json:"parts,omitempty"` // Images and files accompanying Content
 	Name         string         `json:"name,omitempty"`
 	FunctionCall *FunctionCall  `json:"function_call,omitempty"`
 	ToolCalls    []ToolCall     `json:"tool_calls,omitempty"`
@@ -45,6 +46,7 @@ type ToolCall struct {
 	ID       string       `json:"id"`
 	Type     string       `json:"type"`
 	Function FunctionCall `json:"function"`
+	Index    int          `json:"-"` // Position in the message, set on streamed fragments
 }
 
 // Tool represents a callable tool
@@ -111,6 +113,7 @@ const (
 type ResponseFormat struct {
 	Type       ResponseFormatType `json:"type"`
 	JSONSchema any                `json:"schema,omitempty"` // Optional JSON schema for JSONSchema type
+	Name       string             `json:"name,omitempty"`   // Schema name (providers default to "schema")
 }
 
 // WithResponseFormat specifies the output format
@@ -4,6 +4,7 @@ package llm
 type ChatOptions struct {
 	Model               string            // Model name/identifier
 	Temperature         float32           // Controls randomness (0.0 to 1.0)
+	TemperatureSet      bool              // Temperature was set; otherwise the provider default applies
 	TopP                float32           // Controls diversity (0.0 to 1.0)
 	MaxTokens           int               // Maximum number of tokens to generate (legacy)
 	MaxCompletionTokens int               // Maximum completion tokens (preferred for new models)
@@ -39,6 +40,7 @@ func WithModel(model string) Option {
 func WithTemperature(temp float32) Option {
 	return func(o *ChatOptions) {
 		o.Temperature = temp
+		o.TemperatureSet = true
 	}
 }
 
@@ -154,8 +156,7 @@ func WithReasoningEffort(effort string) Option {
 // DefaultOptions returns the default options
 func DefaultOptions() *ChatOptions {
 	return &ChatOptions{
-		Temperature: 0.7,
-		TopP:        1.0,
-		MaxTokens:   0, // No limit by default
+		TopP:      1.0,
+		MaxTokens: 0, // No limit by default
 	}
 }
@@ -0,0 +1,350 @@
+// Package routerx provides an llm.LLM that fails over across an ordered list of backends.
+package routerx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/logx"
+)
+
+// Response metadata keys
+const (
+	MetadataBackend  = "backend"  // Name of the backend that answered
+	MetadataAttempts = "attempts" // Total calls made, including retries and failovers
+)
+
+// Backend is a provider (and optionally a model) the router can send requests to
+type Backend struct {
+	Name  string
+	LLM   llm.LLM
+	Model string // Overrides the requested model when set (model names are provider specific)
+}
+
+// BackendHealth is the health state of a backend
+type BackendHealth struct {
+	Name                string    `json:"name"`
+	Healthy             bool      `json:"healthy"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastError           string    `json:"last_error,omitempty"`
+	LastFailure         time.Time `json:"last_failure,omitempty"`
+	UnhealthyUntil      time.Time `json:"unhealthy_until,omitempty"`
+}
+
+type backendState struct {
+	Backend
+	consecutiveFailures int
+	lastError           string
+	lastFailure         time.Time
+	unhealthyUntil      time.Time
+}
+
+// Router implements llm.LLM over an ordered list of backends
+// Each backend is retried with exponential backoff on retryable errors
+// (429, 408, 5xx, network failures) before failing over to the next one.
+// Backends failing repeatedly are skipped for a cooldown period, unless
+// every backend is unhealthy
+type Router struct {
+	mu               sync.Mutex
+	backends         []*backendState
+	maxRetries       int
+	initialBackoff   time.Duration
+	maxBackoff       time.Duration
+	failureThreshold int
+	cooldown         time.Duration
+	retryable        func(error) bool
+	sleep            func(context.Context, time.Duration) error
+}
+
+// Option configures the router
+type Option func(*Router)
+
+// WithMaxRetries sets how many times a backend is retried before failing over (default 2)
+func WithMaxRetries(retries int) Option {
+	return func(r *Router) {
+		r.maxRetries = retries
+	}
+}
+
+// WithBackoff sets the initial and maximum retry delay (default 250ms, 4s)
+func WithBackoff(initial, max time.Duration) Option {
+	return func(r *Router) {
+		r.initialBackoff = initial
+		r.maxBackoff = max
+	}
+}
+
+// WithHealthPolicy marks a backend unhealthy for cooldown after threshold
+// consecutive failed requests (default 3 failures, 30s)
+func WithHealthPolicy(threshold int, cooldown time.Duration) Option {
+	return func(r *Router) {
+		r.failureThreshold = threshold
+		r.cooldown = cooldown
+	}
+}
+
+// WithRetryable overrides which errors are retried and failed over
+func WithRetryable(retryable func(error) bool) Option {
+	return func(r *Router) {
+		r.retryable = retryable
+	}
+}
+
+// New creates a router over backends, tried in order
+func New(backends []Backend, opts ...Option) *Router {
+	router := &Router{
+		maxRetries:       2,
+		initialBackoff:   250 * time.Millisecond,
+		maxBackoff:       4 * time.Second,
+		failureThreshold: 3,
+		cooldown:         30 * time.Second,
+		retryable:        IsRetryable,
+		sleep:            sleepContext,
+	}
+	for _, backend := range backends {
+		router.backends = append(router.backends, &backendState{Backend: backend})
+	}
+	for _, opt := range opts {
+		opt(router)
+	}
+	return router
+}
+
+// IsRetryable reports whether an error is transient: rate limits, timeouts,
+// server errors and network failures. Cancellation is never retried
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	switch status := llm.HTTPStatus(err); {
+	case status == http.StatusTooManyRequests, status == http.StatusRequestTimeout:
+		return true
+	case status >= 500:
+		return true
+	case status > 0:
+		return false
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// Chat implements the LLM interface
+func (r *Router) Chat(ctx context.Context, messages []llm.Message, opts ...llm.Option) (llm.Response, error) {
+	var response llm.Response
+	backend, attempts, err := r.do(ctx, func(b *backendState) error {
+		var callErr error
+		response, callErr = b.LLM.Chat(ctx, messages, b.options(opts)...)
+		return callErr
+	})
+	if err != nil {
+		return llm.Response{}, err
+	}
+
+	if response.Metadata == nil {
+		response.Metadata = make(map[string]any)
+	}
+	response.Metadata[MetadataBackend] = backend.Name
+	response.Metadata[MetadataAttempts] = attempts
+	return response, nil
+}
+
+// ChatStream implements the LLM interface
+// A backend counts as answering once its first chunk arrives; failures before
+// that fail over, failures after the first token are returned to the caller.
+// The assembled message at io.EOF carries the backend under MetadataBackend
+func (r *Router) ChatStream(ctx context.Context, messages []llm.Message, opts ...llm.Option) (llm.Stream, error) {
+	var stream *bufferedStream
+	_, _, err := r.do(ctx, func(b *backendState) error {
+		inner, callErr := b.LLM.ChatStream(ctx, messages, b.options(opts)...)
+		if callErr != nil {
+			return callErr
+		}
+
+		// Providers may only report errors on the first read
+		first, callErr := inner.Next()
+		if callErr != nil && callErr != io.EOF {
+			inner.Close()
+			return callErr
+		}
+		stream = &bufferedStream{inner: inner, backend: b.Name, first: first, firstErr: callErr, hasFirst: true}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// Health returns the health state of every backend
+func (r *Router) Health() []BackendHealth {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	health := make([]BackendHealth, len(r.backends))
+	for i, b := range r.backends {
+		health[i] = BackendHealth{
+			Name:                b.Name,
+			Healthy:             !now.Before(b.unhealthyUntil),
+			ConsecutiveFailures: b.consecutiveFailures,
+			LastError:           b.lastError,
+			LastFailure:         b.lastFailure,
+			UnhealthyUntil:      b.unhealthyUntil,
+		}
+	}
+	return health
+}
+
+// do runs call against backends in order until one succeeds
+func (r *Router) do(ctx context.Context, call func(*backendState) error) (*backendState, int, error) {
+	if len(r.backends) == 0 {
+		return nil, 0, errors.New("routerx: no backends configured")
+	}
+
+	attempts := 0
+	var lastErr error
+	for _, backend := range r.order() {
+		for retry := 0; retry <= r.maxRetries; retry++ {
+			if retry > 0 {
+				if err := r.sleep(ctx, r.backoff(retry)); err != nil {
+					return nil, attempts, err
+				}
+			}
+
+			attempts++
+			err := call(backend)
+			if err == nil {
+				r.recordSuccess(backend)
+				return backend, attempts, nil
+			}
+			lastErr = err
+
+			if ctx.Err() != nil {
+				return nil, attempts, err
+			}
+			if !r.retryable(err) {
+				// The request itself was rejected; other backends won't do better
+				return nil, attempts, err
+			}
+
+			logx.WithFields(logx.Fields{
+				"backend": backend.Name,
+				"attempt": retry + 1,
+			}).WithError(err).Warn("LLM backend call failed")
+		}
+
+		r.recordFailure(backend, lastErr)
+		logx.WithField("backend", backend.Name).WithError(lastErr).Warn("⚠️ Failing over to next LLM backend")
+	}
+
+	return nil, attempts, fmt.Errorf("routerx: all backends failed: %w", lastErr)
+}
+
+// order returns healthy backends first, keeping configured order;
+// unhealthy ones are kept as a last resort
+func (r *Router) order() []*backendState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	healthy := make([]*backendState, 0, len(r.backends))
+	unhealthy := make([]*backendState, 0)
+	for _, b := range r.backends {
+		if now.Before(b.unhealthyUntil) {
+			unhealthy = append(unhealthy, b)
+		} else {
+			healthy = append(healthy, b)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+func (r *Router) recordSuccess(b *backendState) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b.consecutiveFailures = 0
+	b.unhealthyUntil = time.Time{}
+}
+
+func (r *Router) recordFailure(b *backendState, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b.consecutiveFailures++
+	b.lastError = err.Error()
+	b.lastFailure = time.Now()
+	if r.failureThreshold > 0 && b.consecutiveFailures >= r.failureThreshold {
+		b.unhealthyUntil = b.lastFailure.Add(r.cooldown)
+	}
+}
+
+// backoff returns the delay before a retry, with full jitter
+func (r *Router) backoff(retry int) time.Duration {
+	delay := r.initialBackoff << (retry - 1)
+	if delay <= 0 || delay > r.maxBackoff {
+		delay = r.maxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// options appends the backend's model so it wins over the requested one
+func (b *backendState) options(opts []llm.Option) []llm.Option {
+	if b.Model == "" {
+		return opts
+	}
+	return append(append([]llm.Option{}, opts...), llm.WithModel(b.Model))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// bufferedStream replays the first chunk read during failover
+type bufferedStream struct {
+	inner    llm.Stream
+	backend  string
+	first    llm.Message
+	firstErr error
+	hasFirst bool
+}
+
+func (s *bufferedStream) Next() (llm.Message, error) {
+	msg, err := s.first, s.firstErr
+	if s.hasFirst {
+		s.hasFirst = false
+	} else {
+		msg, err = s.inner.Next()
+	}
+
+	if err == io.EOF {
+		if msg.Metadata == nil {
+			msg.Metadata = make(map[string]any)
+		}
+		msg.Metadata[MetadataBackend] = s.backend
+	}
+	return msg, err
+}
+
+func (s *bufferedStream) Close() error {
+	return s.inner.Close()
+}
@@ -0,0 +1,176 @@
+package routerx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/llmtest"
+)
+
+type statusError int
+
+func (e statusError) Error() string   { return http.StatusText(int(e)) }
+func (e statusError) HTTPStatus() int { return int(e) }
+
+func newTestRouter(backends []Backend, opts ...Option) *Router {
+	router := New(backends, opts...)
+	router.sleep = func(context.Context, time.Duration) error { return nil }
+	return router
+}
+
+func TestChatRetriesTransientErrors(t *testing.T) {
+	primary := llmtest.New()
+	primary.On().Times(2).ReplyError(statusError(http.StatusServiceUnavailable))
+	primary.On().Reply("ok")
+
+	router := newTestRouter([]Backend{{Name: "primary", LLM: primary}})
+	response, err := router.Chat(context.Background(), []llm.Message{llm.NewUserMessage("Hi")})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	if response.Message.Content != "ok" {
+		t.Errorf("content = %q", response.Message.Content)
+	}
+	if response.Metadata[MetadataAttempts] != 3 {
+		t.Errorf("attempts = %v, want 3", response.Metadata[MetadataAttempts])
+	}
+}
+
+func TestChatFailsOverWithBackendModel(t *testing.T) {
+	primary := llmtest.New()
+	primary.On().ReplyError(statusError(http.StatusInternalServerError))
+	secondary := llmtest.New()
+	secondary.On().Reply("from secondary")
+
+	router := newTestRouter([]Backend{
+		{Name: "primary", LLM: primary},
+		{Name: "secondary", LLM: secondary, Model: "secondary-model"},
+	}, WithMaxRetries(1))
+
+	response, err := router.Chat(context.Background(), []llm.Message{llm.NewUserMessage("Hi")}, llm.WithModel("primary-model"))
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	if response.Metadata[MetadataBackend] != "secondary" {
+		t.Errorf("backend = %v, want secondary", response.Metadata[MetadataBackend])
+	}
+	if primary.CallCount() != 2 {
+		t.Errorf("primary calls = %d, want 2", primary.CallCount())
+	}
+	request, _ := secondary.LastRequest()
+	if request.Options.Model != "secondary-model" {
+		t.Errorf("secondary model = %q, want the backend's model", request.Options.Model)
+	}
+}
+
+func TestChatDoesNotFailOverRejectedRequests(t *testing.T) {
+	primary := llmtest.New()
+	primary.On().ReplyError(statusError(http.StatusBadRequest))
+	secondary := llmtest.New()
+	secondary.On().Reply("unused")
+
+	router := newTestRouter([]Backend{{Name: "primary", LLM: primary}, {Name: "secondary", LLM: secondary}})
+	_, err := router.Chat(context.Background(), []llm.Message{llm.NewUserMessage("Hi")})
+
+	if llm.HTTPStatus(err) != http.StatusBadRequest {
+		t.Errorf("err = %v, want the 400", err)
+	}
+	if primary.CallCount() != 1 || secondary.CallCount() != 0 {
+		t.Errorf("calls = %d/%d, want 1/0", primary.CallCount(), secondary.CallCount())
+	}
+}
+
+func TestChatStreamFailsOverBeforeFirstToken(t *testing.T) {
+	primary := llmtest.New()
+	primary.On().Reply("lost").FailStreamAfter(0, io.ErrUnexpectedEOF)
+	secondary := llmtest.New()
+	secondary.On().Reply("streamed")
+
+	router := newTestRouter([]Backend{{Name: "primary", LLM: primary}, {Name: "secondary", LLM: secondary}}, WithMaxRetries(0))
+	stream, err := router.ChatStream(context.Background(), []llm.Message{llm.NewUserMessage("Hi")})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	defer stream.Close()
+
+	content := ""
+	for {
+		chunk, err := stream.Next()
+		if err == io.EOF {
+			if chunk.Metadata[MetadataBackend] != "secondary" {
+				t.Errorf("backend = %v, want secondary", chunk.Metadata[MetadataBackend])
+			}
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		content += chunk.Content
+	}
+	if content != "streamed" {
+		t.Errorf("content = %q", content)
+	}
+}
+
+func TestChatStreamReturnsErrorsAfterFirstToken(t *testing.T) {
+	primary := llmtest.New()
+	primary.On().Reply("partial reply").FailStreamAfter(1, io.ErrUnexpectedEOF)
+	secondary := llmtest.New()
+	secondary.On().Reply("unused")
+
+	router := newTestRouter([]Backend{{Name: "primary", LLM: primary}, {Name: "secondary", LLM: secondary}})
+	stream, err := router.ChatStream(context.Background(), []llm.Message{llm.NewUserMessage("Hi")})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	defer stream.Close()
+
+	if _, err := stream.Next(); err != nil {
+		t.Fatalf("first Next: %v", err)
+	}
+	if _, err := stream.Next(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("second Next = %v, want io.ErrUnexpectedEOF", err)
+	}
+	if secondary.CallCount() != 0 {
+		t.Errorf("secondary called %d times after the first token", secondary.CallCount())
+	}
+}
+
+func TestHealthMarksFailingBackendsUnhealthy(t *testing.T) {
+	primary := llmtest.New()
+	primary.On().ReplyError(statusError(http.StatusServiceUnavailable))
+	secondary := llmtest.New()
+	secondary.On().Reply("ok")
+
+	router := newTestRouter([]Backend{{Name: "primary", LLM: primary}, {Name: "secondary", LLM: secondary}},
+		WithMaxRetries(0), WithHealthPolicy(2, time.Minute))
+
+	for range 2 {
+		if _, err := router.Chat(context.Background(), []llm.Message{llm.NewUserMessage("Hi")}); err != nil {
+			t.Fatalf("Chat: %v", err)
+		}
+	}
+
+	health := router.Health()
+	if health[0].Healthy || health[0].ConsecutiveFailures != 2 {
+		t.Errorf("primary health = %+v, want unhealthy after 2 failures", health[0])
+	}
+	if !health[1].Healthy {
+		t.Errorf("secondary health = %+v, want healthy", health[1])
+	}
+
+	// Unhealthy backends are skipped during the cooldown
+	if _, err := router.Chat(context.Background(), []llm.Message{llm.NewUserMessage("Hi")}); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if primary.CallCount() != 2 {
+		t.Errorf("primary calls = %d, want 2 (skipped while unhealthy)", primary.CallCount())
+	}
+}
@@ -0,0 +1,169 @@
+package tokenizer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Encoding is a byte-level BPE encoding in the tiktoken format
+type Encoding struct {
+	name    string
+	ranks   map[string]int // Token bytes → rank (the token ID)
+	decoder map[int]string
+	pattern *regexp.Regexp
+}
+
+// LoadEncoding parses a tiktoken vocab ("<base64 token> <rank>" per line)
+// The pattern splits text into pieces before merging; its last group must
+// be the trailing whitespace alternative (see splitPattern)
+func LoadEncoding(name string, vocab io.Reader, pattern string) (*Encoding, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("tokenizer: compile %s pattern: %w", name, err)
+	}
+
+	enc := &Encoding{
+		name:    name,
+		ranks:   make(map[string]int, 200_000),
+		decoder: make(map[int]string, 200_000),
+		pattern: re,
+	}
+
+	scanner := bufio.NewScanner(vocab)
+	for line := 1; scanner.Scan(); line++ {
+		fields := bytes.Fields(scanner.Bytes())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("tokenizer: %s vocab line %d: expected token and rank", name, line)
+		}
+
+		token, err := base64.StdEncoding.DecodeString(string(fields[0]))
+		if err != nil {
+			return nil, fmt.Errorf("tokenizer: %s vocab line %d: %w", name, line, err)
+		}
+		rank, err := strconv.Atoi(string(fields[1]))
+		if err != nil {
+			return nil, fmt.Errorf("tokenizer: %s vocab line %d: %w", name, line, err)
+		}
+
+		enc.ranks[string(token)] = rank
+		enc.decoder[rank] = string(token)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("tokenizer: read %s vocab: %w", name, err)
+	}
+	if len(enc.ranks) == 0 {
+		return nil, fmt.Errorf("tokenizer: %s vocab is empty", name)
+	}
+
+	return enc, nil
+}
+
+// Name returns the encoding name, e.g. cl100k_base
+func (e *Encoding) Name() string {
+	return e.name
+}
+
+// Encode returns the token IDs for text; special tokens are encoded as plain text
+func (e *Encoding) Encode(text string) []int {
+	tokens := make([]int, 0, len(text)/3)
+	for _, piece := range e.split(text) {
+		if rank, ok := e.ranks[piece]; ok {
+			tokens = append(tokens, rank)
+			continue
+		}
+		tokens = e.merge(piece, tokens)
+	}
+	return tokens
+}
+
+// Decode returns the text for token IDs; unknown IDs are skipped
+func (e *Encoding) Decode(tokens []int) string {
+	var buf bytes.Buffer
+	for _, token := range tokens {
+		buf.WriteString(e.decoder[token])
+	}
+	return buf.String()
+}
+
+// Count returns the number of tokens in text
+func (e *Encoding) Count(text string) int {
+	count := 0
+	for _, piece := range e.split(text) {
+		if _, ok := e.ranks[piece]; ok {
+			count++
+			continue
+		}
+		count += len(e.merge(piece, nil))
+	}
+	return count
+}
+
+// split applies the pre-tokenizer pattern
+// Go's regexp has no lookahead, so the tiktoken alternative `\s+(?!\S)` is
+// emulated: a whitespace run followed by text gives its last character back
+// to the next piece
+func (e *Encoding) split(text string) []string {
+	pieces := make([]string, 0, len(text)/4+1)
+	trailing := e.pattern.NumSubexp() // Index of the whitespace group
+
+	for start := 0; start < len(text); {
+		loc := e.pattern.FindStringSubmatchIndex(text[start:])
+		if loc == nil || loc[1] == 0 {
+			// Unreachable with the built-in patterns; keep going byte by byte
+			pieces = append(pieces, text[start:start+1])
+			start++
+			continue
+		}
+
+		end := start + loc[1]
+		if loc[2*trailing] >= 0 && end < len(text) {
+			match := text[start:end]
+			if _, size := utf8.DecodeLastRuneInString(match); size < len(match) {
+				end -= size
+			}
+		}
+
+		pieces = append(pieces, text[start:end])
+		start = end
+	}
+	return pieces
+}
+
+// merge applies byte pair merges to a piece, lowest rank first, and appends the tokens
+func (e *Encoding) merge(piece string, tokens []int) []int {
+	// parts holds the start offset of each current token, plus the end
+	parts := make([]int, len(piece)+1)
+	for i := range parts {
+		parts[i] = i
+	}
+
+	for len(parts) > 2 {
+		best, bestRank := -1, math.MaxInt
+		for i := 0; i+2 < len(parts); i++ {
+			if rank, ok := e.ranks[piece[parts[i]:parts[i+2]]]; ok && rank < bestRank {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+		parts = append(parts[:best+1], parts[best+2:]...)
+	}
+
+	for i := 0; i+1 < len(parts); i++ {
+		if rank, ok := e.ranks[piece[parts[i]:parts[i+1]]]; ok {
+			tokens = append(tokens, rank)
+		}
+	}
+	return tokens
+}
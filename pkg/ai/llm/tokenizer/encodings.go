@@ -0,0 +1,94 @@
+package tokenizer
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
+)
+
+// Encoding names
+const (
+	Cl100kBase = "cl100k_base" // GPT-4, GPT-3.5, text-embedding-3
+	O200kBase  = "o200k_base"  // GPT-4o, GPT-4.1, GPT-5, o-series
+)
+
+// ErrVocabNotFound is returned when an encoding's vocab file isn't embedded
+// Run `make tokenizer-vocab` to download the vocab files before building
+var ErrVocabNotFound = errors.New("tokenizer: vocab file not embedded")
+
+//go:embed vocab
+var vocabFS embed.FS
+
+// whitespace is Unicode White_Space as a character class body
+// (Go's \s only covers ASCII whitespace)
+const whitespace = `\t\n\v\f\r\x{85}\p{Z}`
+
+// contractions matches English contractions, case-insensitively
+const contractions = `(?i:'s|'t|'re|'ve|'m|'ll|'d)`
+
+// Pre-tokenizer patterns, equivalent to tiktoken's. The final capture group
+// replaces the `\s+(?!\S)|\s+` alternatives (see Encoding.split)
+var patterns = map[string]string{
+	Cl100kBase: strings.Join([]string{
+		contractions,
+		`[^\r\n\p{L}\p{N}]?\p{L}+`,
+		`\p{N}{1,3}`,
+		` ?[^` + whitespace + `\p{L}\p{N}]+[\r\n]*`,
+		`[` + whitespace + `]*[\r\n]+`,
+		`([` + whitespace + `]+)`,
+	}, "|"),
+	O200kBase: strings.Join([]string{
+		`[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+` + contractions + `?`,
+		`[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*` + contractions + `?`,
+		`\p{N}{1,3}`,
+		` ?[^` + whitespace + `\p{L}\p{N}]+[\r\n/]*`,
+		`[` + whitespace + `]*[\r\n]+`,
+		`([` + whitespace + `]+)`,
+	}, "|"),
+}
+
+type lazyEncoding struct {
+	once sync.Once
+	enc  *Encoding
+	err  error
+}
+
+var (
+	encodingsMu sync.Mutex
+	encodings   = make(map[string]*lazyEncoding)
+)
+
+// GetEncoding returns a built-in encoding, loading its embedded vocab on first use
+func GetEncoding(name string) (*Encoding, error) {
+	pattern, ok := patterns[name]
+	if !ok {
+		return nil, fmt.Errorf("tokenizer: unknown encoding %q", name)
+	}
+
+	encodingsMu.Lock()
+	lazy, ok := encodings[name]
+	if !ok {
+		lazy = &lazyEncoding{}
+		encodings[name] = lazy
+	}
+	encodingsMu.Unlock()
+
+	lazy.once.Do(func() {
+		file, err := vocabFS.Open("vocab/" + name + ".tiktoken")
+		if errors.Is(err, fs.ErrNotExist) {
+			lazy.err = fmt.Errorf("%w: %s", ErrVocabNotFound, name)
+			return
+		}
+		if err != nil {
+			lazy.err = err
+			return
+		}
+		defer file.Close()
+
+		lazy.enc, lazy.err = LoadEncoding(name, file, pattern)
+	})
+	return lazy.enc, lazy.err
+}
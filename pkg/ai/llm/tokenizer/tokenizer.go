@@ -0,0 +1,293 @@
+// Package tokenizer counts tokens offline for budgeting, trimming and cost estimation.
+//
+// The BPE encodings used by OpenAI models (cl100k_base, o200k_base) are
+// implemented natively from embedded vocab files. Other providers plug their
+// own counters with Register:
+//
+//	tokenizer.Register("claude-", myClaudeCounter)
+//	n := tokenizer.ForModel("gpt-4o").CountMessages(messages, tools)
+package tokenizer
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/logx"
+)
+
+// Counter counts tokens for a model family
+type Counter interface {
+	// CountText returns the number of tokens in text
+	CountText(text string) int
+
+	// CountMessages returns the prompt tokens for a request, including
+	// per-message formatting and tool definitions
+	CountMessages(messages []llm.Message, tools []llm.Tool) int
+}
+
+// Overhead are the formatting tokens a chat format adds around content
+type Overhead struct {
+	PerMessage   int // Role and message delimiters
+	PerName      int // Added when a message has a name
+	PerToolCall  int // Delimiters around each tool call in an assistant message
+	ReplyPriming int // Every reply is primed with the assistant header
+	ImageLow     int // Image sent with detail "low"
+	ImageHigh    int // Any other image (a typical 512px-tiled image)
+
+	// Tool definitions are rendered as a typed namespace
+	FunctionInit int
+	PropertyInit int
+	PropertyKey  int
+	EnumInit     int
+	EnumItem     int
+	FunctionsEnd int
+}
+
+// DefaultOverhead is the OpenAI chat format
+func DefaultOverhead() Overhead {
+	return Overhead{
+		PerMessage:   3,
+		PerName:      1,
+		PerToolCall:  3,
+		ReplyPriming: 3,
+		ImageLow:     85,
+		ImageHigh:    765,
+		FunctionInit: 7,
+		PropertyInit: 3,
+		PropertyKey:  3,
+		EnumInit:     -3,
+		EnumItem:     3,
+		FunctionsEnd: 12,
+	}
+}
+
+// textCounter counts messages with a text counter and chat format overhead
+type textCounter struct {
+	count    func(string) int
+	overhead Overhead
+}
+
+// NewCounter builds a Counter from a text counter and chat format overhead
+func NewCounter(countText func(text string) int, overhead Overhead) Counter {
+	return &textCounter{count: countText, overhead: overhead}
+}
+
+// NewEncodingCounter counts with a BPE encoding in the OpenAI chat format
+func NewEncodingCounter(enc *Encoding) Counter {
+	return NewCounter(enc.Count, DefaultOverhead())
+}
+
+// Estimator approximates 4 characters per token; used when no vocab is available
+func Estimator() Counter {
+	return NewCounter(estimate, DefaultOverhead())
+}
+
+func estimate(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+func (c *textCounter) CountText(text string) int {
+	return c.count(text)
+}
+
+func (c *textCounter) CountMessages(messages []llm.Message, tools []llm.Tool) int {
+	total := 0
+	for _, msg := range messages {
+		total += c.overhead.PerMessage + c.count(msg.Role) + c.count(msg.Content)
+
+		if msg.Name != "" {
+			total += c.count(msg.Name) + c.overhead.PerName
+		}
+		for _, part := range msg.Parts {
+			total += c.countPart(part)
+		}
+		for _, tc := range msg.ToolCalls {
+			total += c.overhead.PerToolCall + c.count(tc.Function.Name) + c.count(tc.Function.Arguments)
+		}
+		if msg.FunctionCall != nil {
+			total += c.overhead.PerToolCall + c.count(msg.FunctionCall.Name) + c.count(msg.FunctionCall.Arguments)
+		}
+	}
+
+	if len(messages) > 0 {
+		total += c.overhead.ReplyPriming
+	}
+	return total + c.countTools(tools)
+}
+
+func (c *textCounter) countPart(part llm.ContentPart) int {
+	switch {
+	case part.Type == llm.PartText:
+		return c.count(part.Text)
+	case part.IsImage() && part.Detail == "low":
+		return c.overhead.ImageLow
+	case part.IsImage():
+		return c.overhead.ImageHigh
+	default:
+		// File contents are extracted by the provider; count what we know
+		return c.count(part.Filename)
+	}
+}
+
+// countTools approximates how function definitions are rendered in the prompt
+func (c *textCounter) countTools(tools []llm.Tool) int {
+	if len(tools) == 0 {
+		return 0
+	}
+
+	total := 0
+	for _, tool := range tools {
+		fn := tool.Function
+		total += c.overhead.FunctionInit + c.count(fn.Name+":"+strings.TrimSuffix(fn.Description, "."))
+
+		properties := schemaProperties(fn.Parameters)
+		if len(properties) > 0 {
+			total += c.overhead.PropertyInit
+		}
+		for _, key := range sortedKeys(properties) {
+			property := properties[key]
+			total += c.overhead.PropertyKey
+
+			if enum, ok := property["enum"].([]any); ok {
+				total += c.overhead.EnumInit
+				for _, item := range enum {
+					total += c.overhead.EnumItem + c.count(toString(item))
+				}
+			}
+
+			description := strings.TrimSuffix(toString(property["description"]), ".")
+			total += c.count(key + ":" + toString(property["type"]) + ":" + description)
+		}
+	}
+	return total + c.overhead.FunctionsEnd
+}
+
+// schemaProperties returns the top-level properties of a JSON schema of any Go shape
+func schemaProperties(schema any) map[string]map[string]any {
+	var object map[string]any
+	switch s := schema.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		object = s
+	default:
+		data, err := json.Marshal(schema)
+		if err != nil || json.Unmarshal(data, &object) != nil {
+			return nil
+		}
+	}
+
+	raw, _ := object["properties"].(map[string]any)
+	properties := make(map[string]map[string]any, len(raw))
+	for key, value := range raw {
+		if property, ok := value.(map[string]any); ok {
+			properties[key] = property
+		}
+	}
+	return properties
+}
+
+func sortedKeys(m map[string]map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func toString(v any) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// ============================================================================
+// Model registry
+// ============================================================================
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Counter) // Model prefix → counter
+)
+
+// Built-in model prefixes and their encodings
+var modelEncodings = map[string]string{
+	"gpt-4o":                 O200kBase,
+	"chatgpt-4o":             O200kBase,
+	"gpt-4.1":                O200kBase,
+	"gpt-4.5":                O200kBase,
+	"gpt-5":                  O200kBase,
+	"gpt-oss":                O200kBase,
+	"o1":                     O200kBase,
+	"o3":                     O200kBase,
+	"o4":                     O200kBase,
+	"gpt-4":                  Cl100kBase,
+	"gpt-3.5":                Cl100kBase,
+	"text-embedding-3":       Cl100kBase,
+	"text-embedding-ada-002": Cl100kBase,
+}
+
+// DefaultEncoding approximates models without a registered counter
+const DefaultEncoding = O200kBase
+
+// Register sets the counter for models starting with prefix; the longest prefix wins
+func Register(prefix string, counter Counter) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[prefix] = counter
+}
+
+// ForModel returns the counter for a model
+// Registered counters win over built-in encodings; unknown models are
+// approximated with DefaultEncoding, and the estimator is used when the
+// vocab file isn't embedded
+func ForModel(model string) Counter {
+	registryMu.RLock()
+	counter, prefix := longestPrefix(registry, model)
+	registryMu.RUnlock()
+
+	encodingName, encodingPrefix := longestPrefix(modelEncodings, model)
+	if counter != nil && len(prefix) >= len(encodingPrefix) {
+		return counter
+	}
+	if encodingName == "" {
+		encodingName = DefaultEncoding
+	}
+
+	enc, err := GetEncoding(encodingName)
+	if err != nil {
+		warnEstimating(encodingName, err)
+		return Estimator()
+	}
+	return NewEncodingCounter(enc)
+}
+
+func longestPrefix[V any](m map[string]V, model string) (V, string) {
+	var best V
+	bestPrefix := ""
+	for prefix, value := range m {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(bestPrefix) {
+			best, bestPrefix = value, prefix
+		}
+	}
+	return best, bestPrefix
+}
+
+var warned sync.Map
+
+// warnEstimating logs once per encoding that counts are estimated
+func warnEstimating(encoding string, err error) {
+	if _, loaded := warned.LoadOrStore(encoding, true); !loaded {
+		logx.WithField("encoding", encoding).WithError(err).Warn("⚠️ Token counts are estimated, run `make tokenizer-vocab` for exact counts")
+	}
+}
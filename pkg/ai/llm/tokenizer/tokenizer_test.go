@@ -0,0 +1,178 @@
+package tokenizer
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+)
+
+// testEncoding builds a tiny byte-level vocab: every byte, then a few merges
+func testEncoding(t *testing.T) *Encoding {
+	t.Helper()
+
+	var vocab strings.Builder
+	for b := range 256 {
+		fmt.Fprintf(&vocab, "%s %d\n", base64.StdEncoding.EncodeToString([]byte{byte(b)}), b)
+	}
+	for i, token := range []string{"he", "ll", "hell", "hello"} {
+		fmt.Fprintf(&vocab, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(token)), 256+i)
+	}
+
+	enc, err := LoadEncoding("test", strings.NewReader(vocab.String()), patterns[Cl100kBase])
+	if err != nil {
+		t.Fatalf("LoadEncoding: %v", err)
+	}
+	return enc
+}
+
+// vocabEncoding returns a built-in encoding, skipping when its vocab isn't
+// embedded unless TOKENIZER_VOCAB_REQUIRED is set (`make test-ci`)
+func vocabEncoding(t *testing.T, name string) *Encoding {
+	t.Helper()
+
+	enc, err := GetEncoding(name)
+	if errors.Is(err, ErrVocabNotFound) {
+		if os.Getenv("TOKENIZER_VOCAB_REQUIRED") != "" {
+			t.Fatalf("%s vocab not embedded, run `make tokenizer-vocab`", name)
+		}
+		t.Skipf("%s vocab not embedded, run `make tokenizer-vocab`", name)
+	}
+	if err != nil {
+		t.Fatalf("GetEncoding(%s): %v", name, err)
+	}
+	return enc
+}
+
+func TestEncodeMergesLowestRankFirst(t *testing.T) {
+	enc := testEncoding(t)
+
+	tokens := enc.Encode("hello world")
+	want := []int{259, ' ', 'w', 'o', 'r', 'l', 'd'}
+	if !reflect.DeepEqual(tokens, want) {
+		t.Errorf("Encode = %v, want %v", tokens, want)
+	}
+	if got := enc.Decode(tokens); got != "hello world" {
+		t.Errorf("Decode = %q", got)
+	}
+	if got := enc.Count("hello world"); got != len(want) {
+		t.Errorf("Count = %d, want %d", got, len(want))
+	}
+	// "he" and "ll" merge first, then "hell"; "s" has no merge
+	if got := enc.Encode("shell"); !reflect.DeepEqual(got, []int{'s', 258}) {
+		t.Errorf("Encode(shell) = %v", got)
+	}
+}
+
+func TestSplitGivesLastWhitespaceToNextPiece(t *testing.T) {
+	enc := testEncoding(t)
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"hello world", []string{"hello", " world"}},
+		{"hello   world", []string{"hello", "  ", " world"}},
+		{"trailing  ", []string{"trailing", "  "}},
+		{"it's 12345", []string{"it", "'s", " ", "123", "45"}},
+		{"a\n\nb", []string{"a", "\n\n", "b"}},
+	}
+	for _, tt := range tests {
+		if got := enc.split(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("split(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLoadEncodingRejectsMalformedVocab(t *testing.T) {
+	for _, vocab := range []string{"", "aGk=\n", "aGk= x\n", "!!! 1\n"} {
+		if _, err := LoadEncoding("bad", strings.NewReader(vocab), patterns[Cl100kBase]); err == nil {
+			t.Errorf("LoadEncoding(%q) should fail", vocab)
+		}
+	}
+}
+
+func TestCl100kKnownTokens(t *testing.T) {
+	enc := vocabEncoding(t, Cl100kBase)
+
+	tests := []struct {
+		text string
+		want []int
+	}{
+		{"hello world", []int{15339, 1917}},
+		{"tiktoken is great!", []int{83, 1609, 5963, 374, 2294, 0}},
+	}
+	for _, tt := range tests {
+		if got := enc.Encode(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Encode(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+		if got := enc.Decode(enc.Encode(tt.text)); got != tt.text {
+			t.Errorf("Decode(Encode(%q)) = %q", tt.text, got)
+		}
+	}
+}
+
+func TestO200kKnownCounts(t *testing.T) {
+	enc := vocabEncoding(t, O200kBase)
+
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"hello world", 2},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := enc.Count(tt.text); got != tt.want {
+			t.Errorf("Count(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestForModelFallsBackToEstimator(t *testing.T) {
+	if _, err := GetEncoding(O200kBase); !errors.Is(err, ErrVocabNotFound) {
+		t.Skip("o200k vocab is embedded")
+	}
+
+	if got := ForModel("gpt-4o").CountText("abcdefgh"); got != 2 {
+		t.Errorf("estimated count = %d, want 2", got)
+	}
+}
+
+func TestForModelPrefersLongestRegisteredPrefix(t *testing.T) {
+	custom := NewCounter(func(string) int { return 42 }, Overhead{})
+	Register("gpt-4o-test", custom)
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "gpt-4o-test")
+		registryMu.Unlock()
+	})
+
+	if got := ForModel("gpt-4o-test-1").CountText("hi"); got != 42 {
+		t.Errorf("registered counter not used, got %d", got)
+	}
+	if got := ForModel("gpt-4o").CountText("hi"); got == 42 {
+		t.Errorf("registered counter used for a shorter model name")
+	}
+}
+
+func TestCountMessagesAddsOverhead(t *testing.T) {
+	counter := NewCounter(func(text string) int { return len(strings.Fields(text)) }, DefaultOverhead())
+
+	messages := []llm.Message{
+		llm.NewSystemMessage("be brief"),
+		llm.NewUserMessage("hi there"),
+	}
+	// Per message: 3 + role (1) + content (2); then reply priming
+	if got := counter.CountMessages(messages, nil); got != 2*(3+1+2)+3 {
+		t.Errorf("CountMessages = %d", got)
+	}
+	if got := counter.CountMessages(nil, nil); got != 0 {
+		t.Errorf("CountMessages(nil) = %d", got)
+	}
+}
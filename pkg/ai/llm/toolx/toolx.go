@@ -9,6 +9,9 @@ import (
 	"github.com/Abraxas-365/ams/pkg/ai/llm"
 )
 
+// MetadataToolError is the tool message metadata key set when a tool call failed
+const MetadataToolError = "tool_error"
+
 type Toolx interface {
 	Call(ctx context.Context, inputs string) (any, error)
 	GetTool() llm.Tool
@@ -43,7 +46,10 @@ func (t *ToolxClient) Call(ctx context.Context, tc llm.ToolCall) (llm.Message, e
 
 	result, err := tool.Call(ctx, tc.Function.Arguments)
 	if err != nil {
-		return llm.NewToolMessage(tc.ID, "Error calling tool: "+err.Error()), nil //create a custom error for this
+		// The model sees the error as the tool result; metadata flags it for callers
+		msg := llm.NewToolMessage(tc.ID, "Error calling tool: "+err.Error()) //create a custom error for this
+		msg.Metadata = map[string]any{MetadataToolError: err.Error()}
+		return msg, nil
 	}
 
 	var resultStr string
@@ -0,0 +1,499 @@
+// Package aianthropic implements llm.LLM against the Anthropic Messages API.
+package aianthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/toolx"
+)
+
+const (
+	DefaultBaseURL    = "https://api.anthropic.com"
+	DefaultAPIVersion = "2023-06-01"
+	DefaultModel      = "claude-sonnet-4-5"
+	DefaultMaxTokens  = 4096
+)
+
+// Models served by the Messages API, registered for manifest validation
+var supportedModels = []string{
+	"claude-opus-4-1", "claude-opus-4", "claude-sonnet-4-5", "claude-sonnet-4",
+	"claude-haiku-4-5", "claude-3-7-sonnet", "claude-3-5-sonnet", "claude-3-5-haiku",
+	"claude-3-opus", "claude-3-haiku",
+}
+
+func init() {
+	llm.RegisterModels(supportedModels...)
+}
+
+// AnthropicProvider implements the LLM interface for Anthropic
+type AnthropicProvider struct {
+	apiKey       string
+	baseURL      string
+	apiVersion   string
+	defaultModel string
+	maxTokens    int
+	httpClient   *http.Client
+}
+
+// Option configures the provider
+type Option func(*AnthropicProvider)
+
+// WithBaseURL overrides the API base URL (useful for proxies and tests)
+func WithBaseURL(baseURL string) Option {
+	return func(p *AnthropicProvider) {
+		p.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for requests
+func WithHTTPClient(client *http.Client) Option {
+	return func(p *AnthropicProvider) {
+		p.httpClient = client
+	}
+}
+
+// WithAPIVersion sets the anthropic-version header
+func WithAPIVersion(version string) Option {
+	return func(p *AnthropicProvider) {
+		p.apiVersion = version
+	}
+}
+
+// WithDefaultModel sets the model used when a request doesn't choose one
+func WithDefaultModel(model string) Option {
+	return func(p *AnthropicProvider) {
+		p.defaultModel = model
+	}
+}
+
+// WithDefaultMaxTokens sets max_tokens when a request doesn't choose one
+// The Messages API requires it on every request
+func WithDefaultMaxTokens(tokens int) Option {
+	return func(p *AnthropicProvider) {
+		p.maxTokens = tokens
+	}
+}
+
+// NewAnthropicProvider creates a new Anthropic provider
+// An empty apiKey falls back to ANTHROPIC_API_KEY
+func NewAnthropicProvider(apiKey string, opts ...Option) *AnthropicProvider {
+	if apiKey == "" {
+		apiKey = os.Getenv("ANTHROPIC_API_KEY")
+	}
+
+	provider := &AnthropicProvider{
+		apiKey:       apiKey,
+		baseURL:      DefaultBaseURL,
+		apiVersion:   DefaultAPIVersion,
+		defaultModel: DefaultModel,
+		maxTokens:    DefaultMaxTokens,
+		httpClient:   &http.Client{Timeout: 5 * time.Minute},
+	}
+	for _, opt := range opts {
+		opt(provider)
+	}
+	return provider
+}
+
+// APIError is an error response from the Messages API
+type APIError struct {
+	StatusCode int
+	Type       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("anthropic: %d %s: %s", e.StatusCode, e.Type, e.Message)
+}
+
+// HTTPStatus implements llm.StatusError
+func (e *APIError) HTTPStatus() int {
+	return e.StatusCode
+}
+
+// Chat implements the LLM interface
+func (p *AnthropicProvider) Chat(ctx context.Context, messages []llm.Message, opts ...llm.Option) (llm.Response, error) {
+	request, options, err := p.buildRequest(messages, opts, false)
+	if err != nil {
+		return llm.Response{}, err
+	}
+
+	resp, err := p.send(ctx, request, options.Headers)
+	if err != nil {
+		return llm.Response{}, err
+	}
+	defer resp.Body.Close()
+
+	var result messagesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return llm.Response{}, fmt.Errorf("anthropic: failed to decode response: %w", err)
+	}
+
+	return convertFromResponse(result), nil
+}
+
+// ChatStream implements the LLM interface
+func (p *AnthropicProvider) ChatStream(ctx context.Context, messages []llm.Message, opts ...llm.Option) (llm.Stream, error) {
+	request, options, err := p.buildRequest(messages, opts, true)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.send(ctx, request, options.Headers)
+	if err != nil {
+		return nil, err
+	}
+
+	return newAnthropicStream(resp.Body), nil
+}
+
+// send posts a Messages API request and returns the successful response
+func (p *AnthropicProvider) send(ctx context.Context, request messagesRequest, headers map[string]string) (*http.Response, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic: failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", p.apiKey)
+	req.Header.Set("anthropic-version", p.apiVersion)
+	if request.Stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, decodeAPIError(resp)
+	}
+	return resp, nil
+}
+
+func decodeAPIError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	var payload errorResponse
+	if err := json.Unmarshal(data, &payload); err != nil || payload.Error.Message == "" {
+		return &APIError{StatusCode: resp.StatusCode, Type: "http_error", Message: strings.TrimSpace(string(data))}
+	}
+	return &APIError{StatusCode: resp.StatusCode, Type: payload.Error.Type, Message: payload.Error.Message}
+}
+
+// ============================================================================
+// Wire types
+// ============================================================================
+
+type messagesRequest struct {
+	Model         string         `json:"model"`
+	MaxTokens     int            `json:"max_tokens"`
+	System        string         `json:"system,omitempty"`
+	Messages      []apiMessage   `json:"messages"`
+	Temperature   *float32       `json:"temperature,omitempty"`
+	TopP          *float32       `json:"top_p,omitempty"`
+	StopSequences []string       `json:"stop_sequences,omitempty"`
+	Tools         []apiTool      `json:"tools,omitempty"`
+	ToolChoice    map[string]any `json:"tool_choice,omitempty"`
+	Metadata      map[string]any `json:"metadata,omitempty"`
+	Stream        bool           `json:"stream,omitempty"`
+}
+
+type apiMessage struct {
+	Role    string         `json:"role"`
+	Content []contentBlock `json:"content"`
+}
+
+type contentBlock struct {
+	Type string `json:"type"`
+
+	// text
+	Text string `json:"text,omitempty"`
+
+	// tool_use
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+
+	// tool_result
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+	IsError   bool   `json:"is_error,omitempty"`
+}
+
+type apiTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	InputSchema any    `json:"input_schema"`
+}
+
+type messagesResponse struct {
+	ID         string         `json:"id"`
+	Model      string         `json:"model"`
+	Role       string         `json:"role"`
+	Content    []contentBlock `json:"content"`
+	StopReason string         `json:"stop_reason"`
+	Usage      apiUsage       `json:"usage"`
+}
+
+type apiUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+type errorResponse struct {
+	Type  string `json:"type"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// ============================================================================
+// Conversion
+// ============================================================================
+
+func (p *AnthropicProvider) buildRequest(messages []llm.Message, opts []llm.Option, stream bool) (messagesRequest, *llm.ChatOptions, error) {
+	options := llm.DefaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	request := messagesRequest{
+		Model:         options.Model,
+		MaxTokens:     options.MaxCompletionTokens,
+		StopSequences: options.Stop,
+		Stream:        stream,
+	}
+	if request.Model == "" {
+		request.Model = p.defaultModel
+	}
+	if request.MaxTokens == 0 {
+		request.MaxTokens = options.MaxTokens
+	}
+	if request.MaxTokens == 0 {
+		request.MaxTokens = p.maxTokens
+	}
+
+	// The Messages API accepts temperatures up to 1
+	if options.TemperatureSet {
+		temperature := min(options.Temperature, 1)
+		request.Temperature = &temperature
+	}
+	if options.TopP > 0 && options.TopP < 1 {
+		topP := options.TopP
+		request.TopP = &topP
+	}
+	if options.User != "" {
+		request.Metadata = map[string]any{"user_id": options.User}
+	}
+
+	system, converted, err := convertMessages(messages)
+	if err != nil {
+		return messagesRequest{}, nil, err
+	}
+	request.Messages = converted
+	request.System = appendFormatInstructions(system, options)
+
+	request.Tools = convertTools(options.Tools, options.Functions)
+	if len(request.Tools) > 0 && options.ToolChoice != nil {
+		request.ToolChoice = convertToolChoice(options.ToolChoice)
+	}
+
+	return request, options, nil
+}
+
+// convertMessages splits out system messages and maps the rest to Messages API turns
+// Tool results become tool_result blocks in a user turn, and consecutive turns
+// with the same role are merged since the API expects alternating roles
+func convertMessages(messages []llm.Message) (string, []apiMessage, error) {
+	var system []string
+	converted := make([]apiMessage, 0, len(messages))
+
+	appendTurn := func(role string, blocks ...contentBlock) {
+		if len(blocks) == 0 {
+			return
+		}
+		if n := len(converted); n > 0 && converted[n-1].Role == role {
+			converted[n-1].Content = append(converted[n-1].Content, blocks...)
+			return
+		}
+		converted = append(converted, apiMessage{Role: role, Content: blocks})
+	}
+
+	for _, msg := range messages {
+		switch msg.Role {
+		case llm.RoleSystem:
+			if msg.Content != "" {
+				system = append(system, msg.Content)
+			}
+
+		case llm.RoleUser, llm.RoleFunction:
+			if msg.Content != "" {
+				appendTurn("user", contentBlock{Type: "text", Text: msg.Content})
+			}
+
+		case llm.RoleAssistant:
+			blocks := make([]contentBlock, 0, len(msg.ToolCalls)+1)
+			if msg.Content != "" {
+				blocks = append(blocks, contentBlock{Type: "text", Text: msg.Content})
+			}
+			for _, tc := range msg.ToolCalls {
+				input := strings.TrimSpace(tc.Function.Arguments)
+				if input == "" {
+					input = "{}"
+				}
+				if !json.Valid([]byte(input)) {
+					return "", nil, fmt.Errorf("anthropic: tool call %s has invalid JSON arguments", tc.ID)
+				}
+				blocks = append(blocks, contentBlock{
+					Type:  "tool_use",
+					ID:    tc.ID,
+					Name:  tc.Function.Name,
+					Input: json.RawMessage(input),
+				})
+			}
+			appendTurn("assistant", blocks...)
+
+		case llm.RoleTool:
+			_, isError := msg.Metadata[toolx.MetadataToolError]
+			appendTurn("user", contentBlock{
+				Type:      "tool_result",
+				ToolUseID: msg.ToolCallID,
+				Content:   msg.Content,
+				IsError:   isError,
+			})
+
+		default:
+			return "", nil, fmt.Errorf("anthropic: unsupported message role %q", msg.Role)
+		}
+	}
+
+	return strings.Join(system, "\n\n"), converted, nil
+}
+
+// appendFormatInstructions maps ResponseFormat and JSON mode to system instructions
+// The Messages API has no response_format parameter
+func appendFormatInstructions(system string, options *llm.ChatOptions) string {
+	var instruction string
+	switch {
+	case options.ResponseFormat != nil && options.ResponseFormat.Type == llm.JSONSchema:
+		schema, _ := json.Marshal(options.ResponseFormat.JSONSchema)
+		instruction = "Respond only with a JSON object that matches this JSON schema, with no prose or code fences:\n" + string(schema)
+	case options.JSONMode || (options.ResponseFormat != nil && options.ResponseFormat.Type == llm.JSONObject):
+		instruction = "Respond only with a valid JSON object, with no prose or code fences."
+	default:
+		return system
+	}
+
+	if system == "" {
+		return instruction
+	}
+	return system + "\n\n" + instruction
+}
+
+func convertTools(tools []llm.Tool, functions []llm.Function) []apiTool {
+	result := make([]apiTool, 0, len(tools)+len(functions))
+	for _, tool := range tools {
+		result = append(result, convertFunction(tool.Function))
+	}
+	for _, function := range functions {
+		result = append(result, convertFunction(function))
+	}
+	return result
+}
+
+func convertFunction(function llm.Function) apiTool {
+	schema := function.Parameters
+	if schema == nil {
+		schema = map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	return apiTool{
+		Name:        function.Name,
+		Description: function.Description,
+		InputSchema: schema,
+	}
+}
+
+// convertToolChoice maps OpenAI-style tool choices to the Messages API
+func convertToolChoice(toolChoice any) map[string]any {
+	switch choice := toolChoice.(type) {
+	case string:
+		switch choice {
+		case "none":
+			return map[string]any{"type": "none"}
+		case "required", "any":
+			return map[string]any{"type": "any"}
+		case "auto", "":
+			return map[string]any{"type": "auto"}
+		default:
+			// A bare tool name
+			return map[string]any{"type": "tool", "name": choice}
+		}
+	case map[string]any:
+		// {"type": "function", "function": {"name": "..."}}
+		if function, ok := choice["function"].(map[string]any); ok {
+			if name, ok := function["name"].(string); ok {
+				return map[string]any{"type": "tool", "name": name}
+			}
+		}
+	}
+	return map[string]any{"type": "auto"}
+}
+
+func convertFromResponse(result messagesResponse) llm.Response {
+	message := llm.Message{Role: llm.RoleAssistant}
+
+	var text strings.Builder
+	for _, block := range result.Content {
+		switch block.Type {
+		case "text":
+			text.WriteString(block.Text)
+		case "tool_use":
+			message.ToolCalls = append(message.ToolCalls, llm.ToolCall{
+				ID:   block.ID,
+				Type: "function",
+				Function: llm.FunctionCall{
+					Name:      block.Name,
+					Arguments: string(block.Input),
+				},
+			})
+		}
+	}
+	message.Content = text.String()
+
+	return llm.Response{
+		Message: message,
+		Usage:   convertUsage(result.Usage),
+	}
+}
+
+// convertUsage counts cached input tokens as prompt tokens
+func convertUsage(usage apiUsage) llm.Usage {
+	prompt := usage.InputTokens + usage.CacheCreationInputTokens + usage.CacheReadInputTokens
+	return llm.Usage{
+		PromptTokens:     prompt,
+		CompletionTokens: usage.OutputTokens,
+		TotalTokens:      prompt + usage.OutputTokens,
+	}
+}
@@ -0,0 +1,303 @@
+package aianthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/toolx"
+)
+
+// recordedServer replays a recorded response and captures the request it received
+func recordedServer(t *testing.T, status int, contentType, fixture string, captured *map[string]any) *httptest.Server {
+	t.Helper()
+
+	body, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("x-api-key"); got != "test-key" {
+			t.Errorf("x-api-key = %q", got)
+		}
+		if got := r.Header.Get("anthropic-version"); got != DefaultAPIVersion {
+			t.Errorf("anthropic-version = %q", got)
+		}
+		if captured != nil {
+			if err := json.NewDecoder(r.Body).Decode(captured); err != nil {
+				t.Errorf("decode request: %v", err)
+			}
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestChatText(t *testing.T) {
+	var request map[string]any
+	server := recordedServer(t, http.StatusOK, "application/json", "messages_text.json", &request)
+	provider := NewAnthropicProvider("test-key", WithBaseURL(server.URL))
+
+	resp, err := provider.Chat(context.Background(), []llm.Message{
+		llm.NewSystemMessage("You are helpful."),
+		llm.NewUserMessage("Hi"),
+	}, llm.WithTemperature(1.5), llm.WithMaxCompletionTokens(256), llm.WithUser("user-1"))
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	if resp.Message.Content != "Hello! How can I help you today?" {
+		t.Errorf("content = %q", resp.Message.Content)
+	}
+	if resp.Usage != (llm.Usage{PromptTokens: 12, CompletionTokens: 9, TotalTokens: 21}) {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+
+	if request["system"] != "You are helpful." {
+		t.Errorf("system = %v", request["system"])
+	}
+	if request["model"] != DefaultModel {
+		t.Errorf("model = %v", request["model"])
+	}
+	if request["max_tokens"] != float64(256) {
+		t.Errorf("max_tokens = %v", request["max_tokens"])
+	}
+	if request["temperature"] != float64(1) {
+		t.Errorf("temperature should be clamped to 1, got %v", request["temperature"])
+	}
+	if _, ok := request["top_p"]; ok {
+		t.Errorf("top_p should be omitted at 1")
+	}
+	if metadata, _ := request["metadata"].(map[string]any); metadata["user_id"] != "user-1" {
+		t.Errorf("metadata = %v", request["metadata"])
+	}
+	messages, _ := request["messages"].([]any)
+	if len(messages) != 1 {
+		t.Fatalf("messages = %v", request["messages"])
+	}
+}
+
+func TestChatSendsZeroTemperature(t *testing.T) {
+	var request map[string]any
+	server := recordedServer(t, http.StatusOK, "application/json", "messages_text.json", &request)
+	provider := NewAnthropicProvider("test-key", WithBaseURL(server.URL))
+
+	if _, err := provider.Chat(context.Background(), []llm.Message{llm.NewUserMessage("Hi")}, llm.WithTemperature(0)); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if temperature, ok := request["temperature"]; !ok || temperature != float64(0) {
+		t.Errorf("temperature = %v, want an explicit 0", request["temperature"])
+	}
+
+	request = nil
+	if _, err := provider.Chat(context.Background(), []llm.Message{llm.NewUserMessage("Hi")}); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if _, ok := request["temperature"]; ok {
+		t.Errorf("temperature should be omitted when unset, got %v", request["temperature"])
+	}
+}
+
+func TestChatToolUse(t *testing.T) {
+	var request map[string]any
+	server := recordedServer(t, http.StatusOK, "application/json", "messages_tool_use.json", &request)
+	provider := NewAnthropicProvider("test-key", WithBaseURL(server.URL))
+
+	history := []llm.Message{
+		llm.NewUserMessage("Weather in Cusco and Lima?"),
+		{
+			Role: llm.RoleAssistant,
+			ToolCalls: []llm.ToolCall{{
+				ID:       "toolu_1",
+				Type:     "function",
+				Function: llm.FunctionCall{Name: "get_weather", Arguments: `{"location":"Cusco"}`},
+			}},
+		},
+		{
+			Role:       llm.RoleTool,
+			Content:    "service unavailable",
+			ToolCallID: "toolu_1",
+			Metadata:   map[string]any{toolx.MetadataToolError: "service unavailable"},
+		},
+	}
+	tools := []llm.Tool{{
+		Type: "function",
+		Function: llm.Function{
+			Name:        "get_weather",
+			Description: "Get the weather",
+			Parameters: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"location": map[string]any{"type": "string"}},
+			},
+		},
+	}}
+
+	resp, err := provider.Chat(context.Background(), history, llm.WithTools(tools), llm.WithToolChoice("required"))
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	if resp.Message.Content != "Let me check the weather." {
+		t.Errorf("content = %q", resp.Message.Content)
+	}
+	if len(resp.Message.ToolCalls) != 1 {
+		t.Fatalf("tool calls = %+v", resp.Message.ToolCalls)
+	}
+	call := resp.Message.ToolCalls[0]
+	if call.ID != "toolu_01A09q90qw90lq917835lq9" || call.Function.Name != "get_weather" || call.Function.Arguments != `{"location": "Lima"}` {
+		t.Errorf("tool call = %+v", call)
+	}
+	if resp.Usage.PromptTokens != 440 || resp.Usage.TotalTokens != 481 {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+
+	// Request mapping: assistant tool_use, then the tool result as a user turn
+	messages, _ := request["messages"].([]any)
+	if len(messages) != 3 {
+		t.Fatalf("messages = %v", messages)
+	}
+	assistant := messages[1].(map[string]any)
+	toolUse := assistant["content"].([]any)[0].(map[string]any)
+	if assistant["role"] != "assistant" || toolUse["type"] != "tool_use" || toolUse["id"] != "toolu_1" {
+		t.Errorf("assistant turn = %v", assistant)
+	}
+	if input, _ := toolUse["input"].(map[string]any); input["location"] != "Cusco" {
+		t.Errorf("tool_use input = %v", toolUse["input"])
+	}
+	result := messages[2].(map[string]any)
+	toolResult := result["content"].([]any)[0].(map[string]any)
+	if result["role"] != "user" || toolResult["type"] != "tool_result" || toolResult["tool_use_id"] != "toolu_1" || toolResult["is_error"] != true {
+		t.Errorf("tool result turn = %v", result)
+	}
+
+	if choice, _ := request["tool_choice"].(map[string]any); choice["type"] != "any" {
+		t.Errorf("tool_choice = %v", request["tool_choice"])
+	}
+	apiTools, _ := request["tools"].([]any)
+	if len(apiTools) != 1 || apiTools[0].(map[string]any)["input_schema"] == nil {
+		t.Errorf("tools = %v", request["tools"])
+	}
+}
+
+func TestChatResponseFormat(t *testing.T) {
+	var request map[string]any
+	server := recordedServer(t, http.StatusOK, "application/json", "messages_text.json", &request)
+	provider := NewAnthropicProvider("test-key", WithBaseURL(server.URL))
+
+	format := &llm.ResponseFormat{
+		Type:       llm.JSONSchema,
+		JSONSchema: map[string]any{"type": "object", "required": []string{"answer"}},
+	}
+	_, err := provider.Chat(context.Background(), []llm.Message{
+		llm.NewSystemMessage("Base prompt."),
+		llm.NewUserMessage("Hi"),
+	}, llm.WithResponseFormat(format))
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	system, _ := request["system"].(string)
+	if !strings.HasPrefix(system, "Base prompt.") || !strings.Contains(system, `"required":["answer"]`) {
+		t.Errorf("system = %q", system)
+	}
+}
+
+func TestChatAPIError(t *testing.T) {
+	server := recordedServer(t, http.StatusBadRequest, "application/json", "messages_error.json", nil)
+	provider := NewAnthropicProvider("test-key", WithBaseURL(server.URL))
+
+	_, err := provider.Chat(context.Background(), []llm.Message{llm.NewUserMessage("Hi")})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || apiErr.Type != "invalid_request_error" {
+		t.Errorf("error = %+v", apiErr)
+	}
+}
+
+func TestChatStream(t *testing.T) {
+	var request map[string]any
+	server := recordedServer(t, http.StatusOK, "text/event-stream", "messages_stream.txt", &request)
+	provider := NewAnthropicProvider("test-key", WithBaseURL(server.URL))
+
+	stream, err := provider.ChatStream(context.Background(), []llm.Message{llm.NewUserMessage("Weather in Lima?")})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	defer stream.Close()
+
+	if request["stream"] != true {
+		t.Errorf("stream flag not sent: %v", request["stream"])
+	}
+
+	var deltas []string
+	var final llm.Message
+	for {
+		msg, err := stream.Next()
+		if err == io.EOF {
+			final = msg
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		deltas = append(deltas, msg.Content)
+	}
+
+	if strings.Join(deltas, "|") != "Okay, let me |check." {
+		t.Errorf("deltas = %q", deltas)
+	}
+	if final.Content != "Okay, let me check." {
+		t.Errorf("final content = %q", final.Content)
+	}
+	if len(final.ToolCalls) != 1 || final.ToolCalls[0].Function.Name != "get_weather" ||
+		final.ToolCalls[0].Function.Arguments != `{"location": "Lima"}` {
+		t.Errorf("final tool calls = %+v", final.ToolCalls)
+	}
+	if usage, _ := llm.StreamUsage(final); usage != (llm.Usage{PromptTokens: 472, CompletionTokens: 89, TotalTokens: 561}) {
+		t.Errorf("final usage = %+v", usage)
+	}
+
+	if _, err := stream.Next(); err != io.EOF {
+		t.Errorf("Next after end = %v, want io.EOF", err)
+	}
+}
+
+func TestChatStreamTruncated(t *testing.T) {
+	server := recordedServer(t, http.StatusOK, "text/event-stream", "messages_stream_truncated.txt", nil)
+	provider := NewAnthropicProvider("test-key", WithBaseURL(server.URL))
+
+	stream, err := provider.ChatStream(context.Background(), []llm.Message{llm.NewUserMessage("Weather in Lima?")})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	defer stream.Close()
+
+	for {
+		_, err = stream.Next()
+		if err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Next = %v, want io.ErrUnexpectedEOF before message_stop", err)
+	}
+}
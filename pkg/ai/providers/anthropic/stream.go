@@ -0,0 +1,171 @@
+package aianthropic
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+)
+
+// anthropicStream adapts Messages API server-sent events to our Stream interface
+// Next returns text deltas; tool_use blocks are assembled from input_json_delta
+// fragments and the complete message, with usage, is returned together with io.EOF
+type anthropicStream struct {
+	body      io.ReadCloser
+	scanner   *bufio.Scanner
+	lastError error
+	current   llm.Message
+	blocks    map[int]int // content block index -> position in current.ToolCalls
+	arguments map[int]*strings.Builder
+	usage     apiUsage
+}
+
+type streamEvent struct {
+	Type         string        `json:"type"`
+	Index        int           `json:"index"`
+	ContentBlock *contentBlock `json:"content_block,omitempty"`
+	Delta        *streamDelta  `json:"delta,omitempty"`
+	Message      *struct {
+		Usage apiUsage `json:"usage"`
+	} `json:"message,omitempty"`
+	Usage *apiUsage `json:"usage,omitempty"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+type streamDelta struct {
+	Type        string `json:"type"`
+	Text        string `json:"text,omitempty"`
+	PartialJSON string `json:"partial_json,omitempty"`
+}
+
+func newAnthropicStream(body io.ReadCloser) *anthropicStream {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	return &anthropicStream{
+		body:      body,
+		scanner:   scanner,
+		current:   llm.Message{Role: llm.RoleAssistant},
+		blocks:    make(map[int]int),
+		arguments: make(map[int]*strings.Builder),
+	}
+}
+
+func (s *anthropicStream) Next() (llm.Message, error) {
+	if s.lastError != nil {
+		return llm.Message{}, s.lastError
+	}
+
+	for {
+		event, err := s.nextEvent()
+		if err != nil {
+			// The body ending before message_stop means a truncated stream
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			s.lastError = err
+			return llm.Message{}, err
+		}
+
+		switch event.Type {
+		case "message_start":
+			// Input tokens are reported up front, output tokens in message_delta
+			if event.Message != nil {
+				s.usage = event.Message.Usage
+			}
+
+		case "message_delta":
+			if event.Usage != nil {
+				s.usage.OutputTokens = event.Usage.OutputTokens
+			}
+
+		case "content_block_start":
+			if event.ContentBlock != nil && event.ContentBlock.Type == "tool_use" {
+				s.blocks[event.Index] = len(s.current.ToolCalls)
+				s.arguments[event.Index] = &strings.Builder{}
+				s.current.ToolCalls = append(s.current.ToolCalls, llm.ToolCall{
+					ID:       event.ContentBlock.ID,
+					Type:     "function",
+					Function: llm.FunctionCall{Name: event.ContentBlock.Name},
+				})
+			}
+
+		case "content_block_delta":
+			if event.Delta == nil {
+				continue
+			}
+			switch event.Delta.Type {
+			case "text_delta":
+				s.current.Content += event.Delta.Text
+				return llm.Message{Role: llm.RoleAssistant, Content: event.Delta.Text}, nil
+			case "input_json_delta":
+				if builder, ok := s.arguments[event.Index]; ok {
+					builder.WriteString(event.Delta.PartialJSON)
+				}
+			}
+
+		case "content_block_stop":
+			if position, ok := s.blocks[event.Index]; ok {
+				arguments := s.arguments[event.Index].String()
+				if arguments == "" {
+					arguments = "{}"
+				}
+				s.current.ToolCalls[position].Function.Arguments = arguments
+			}
+
+		case "message_stop":
+			return s.finish()
+
+		case "error":
+			message := "stream error"
+			errorType := "stream_error"
+			if event.Error != nil {
+				message = event.Error.Message
+				errorType = event.Error.Type
+			}
+			s.lastError = &APIError{Type: errorType, Message: message}
+			return llm.Message{}, s.lastError
+		}
+	}
+}
+
+// finish ends the stream, returning the assembled message with io.EOF
+func (s *anthropicStream) finish() (llm.Message, error) {
+	s.lastError = io.EOF
+	if usage := convertUsage(s.usage); usage.TotalTokens > 0 {
+		s.current = llm.WithStreamUsage(s.current, usage)
+	}
+	return s.current, io.EOF
+}
+
+// nextEvent reads the next "data:" payload
+func (s *anthropicStream) nextEvent() (streamEvent, error) {
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		var event streamEvent
+		payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if err := json.Unmarshal([]byte(payload), &event); err != nil {
+			return streamEvent{}, fmt.Errorf("anthropic: invalid stream event: %w", err)
+		}
+		return event, nil
+	}
+
+	if err := s.scanner.Err(); err != nil {
+		return streamEvent{}, err
+	}
+	return streamEvent{}, io.EOF
+}
+
+func (s *anthropicStream) Close() error {
+	return s.body.Close()
+}
@@ -0,0 +1,85 @@
+package aigemini
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Abraxas-365/ams/pkg/ai/embedding"
+)
+
+type batchEmbedRequest struct {
+	Requests []embedRequest `json:"requests"`
+}
+
+type embedRequest struct {
+	Model                string  `json:"model"`
+	Content              content `json:"content"`
+	OutputDimensionality int     `json:"outputDimensionality,omitempty"`
+}
+
+type batchEmbedResponse struct {
+	Embeddings []struct {
+		Values []float32 `json:"values"`
+	} `json:"embeddings"`
+}
+
+// EmbedDocuments implements the Embedder interface
+// The Gemini API doesn't report token usage for embeddings, so Usage is zero
+func (p *GeminiProvider) EmbedDocuments(ctx context.Context, documents []string, opts ...embedding.Option) ([]embedding.Embedding, error) {
+	options := embedding.DefaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	model := options.Model
+	if model == "" {
+		model = p.embeddingModel
+	}
+	model = strings.TrimPrefix(model, "models/")
+
+	request := batchEmbedRequest{Requests: make([]embedRequest, len(documents))}
+	for i, document := range documents {
+		request.Requests[i] = embedRequest{
+			Model:                "models/" + model,
+			Content:              content{Parts: []part{{Text: document}}},
+			OutputDimensionality: options.Dimensions,
+		}
+	}
+
+	resp, err := p.post(ctx, model, "batchEmbedContents", nil, request, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result batchEmbedResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("gemini: failed to decode embeddings: %w", err)
+	}
+	if len(result.Embeddings) != len(documents) {
+		return nil, fmt.Errorf("gemini: expected %d embeddings, got %d", len(documents), len(result.Embeddings))
+	}
+
+	embeddings := make([]embedding.Embedding, len(result.Embeddings))
+	for i, data := range result.Embeddings {
+		embeddings[i] = embedding.Embedding{Vector: data.Values}
+	}
+
+	return embeddings, nil
+}
+
+func (p *GeminiProvider) EmbedQuery(ctx context.Context, text string, opts ...embedding.Option) (embedding.Embedding, error) {
+	embeddings, err := p.EmbedDocuments(ctx, []string{text}, opts...)
+	if err != nil {
+		return embedding.Embedding{}, err
+	}
+
+	if len(embeddings) == 0 {
+		return embedding.Embedding{}, errors.New("no embedding returned")
+	}
+
+	return embeddings[0], nil
+}
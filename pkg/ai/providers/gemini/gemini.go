@@ -0,0 +1,571 @@
+// Package aigemini implements llm.LLM and embedding.Embedder against the Gemini API.
+package aigemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/toolx"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultBaseURL        = "https://generativelanguage.googleapis.com"
+	DefaultAPIVersion     = "v1beta"
+	DefaultModel          = "gemini-2.5-flash"
+	DefaultEmbeddingModel = "gemini-embedding-001"
+)
+
+// Models served by the Gemini API, registered for manifest validation
+var supportedModels = []string{
+	"gemini-2.5-pro", "gemini-2.5-flash", "gemini-2.5-flash-lite",
+	"gemini-2.0-flash", "gemini-2.0-flash-lite",
+}
+
+func init() {
+	llm.RegisterModels(supportedModels...)
+}
+
+// GeminiProvider implements the LLM and Embedder interfaces for Gemini
+type GeminiProvider struct {
+	apiKey         string
+	baseURL        string
+	apiVersion     string
+	defaultModel   string
+	embeddingModel string
+	httpClient     *http.Client
+}
+
+// Option configures the provider
+type Option func(*GeminiProvider)
+
+// WithBaseURL overrides the API base URL (useful for proxies and tests)
+func WithBaseURL(baseURL string) Option {
+	return func(p *GeminiProvider) {
+		p.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for requests
+func WithHTTPClient(client *http.Client) Option {
+	return func(p *GeminiProvider) {
+		p.httpClient = client
+	}
+}
+
+// WithAPIVersion sets the API version path segment (v1beta by default)
+func WithAPIVersion(version string) Option {
+	return func(p *GeminiProvider) {
+		p.apiVersion = version
+	}
+}
+
+// WithDefaultModel sets the chat model used when a request doesn't choose one
+func WithDefaultModel(model string) Option {
+	return func(p *GeminiProvider) {
+		p.defaultModel = model
+	}
+}
+
+// WithEmbeddingModel sets the embedding model used when a request doesn't choose one
+func WithEmbeddingModel(model string) Option {
+	return func(p *GeminiProvider) {
+		p.embeddingModel = model
+	}
+}
+
+// NewGeminiProvider creates a new Gemini provider
+// An empty apiKey falls back to GEMINI_API_KEY, then GOOGLE_API_KEY
+func NewGeminiProvider(apiKey string, opts ...Option) *GeminiProvider {
+	if apiKey == "" {
+		apiKey = os.Getenv("GEMINI_API_KEY")
+	}
+	if apiKey == "" {
+		apiKey = os.Getenv("GOOGLE_API_KEY")
+	}
+
+	provider := &GeminiProvider{
+		apiKey:         apiKey,
+		baseURL:        DefaultBaseURL,
+		apiVersion:     DefaultAPIVersion,
+		defaultModel:   DefaultModel,
+		embeddingModel: DefaultEmbeddingModel,
+		httpClient:     &http.Client{Timeout: 5 * time.Minute},
+	}
+	for _, opt := range opts {
+		opt(provider)
+	}
+	return provider
+}
+
+// APIError is an error response from the Gemini API
+type APIError struct {
+	StatusCode int
+	Status     string // e.g. INVALID_ARGUMENT, RESOURCE_EXHAUSTED
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("gemini: %d %s: %s", e.StatusCode, e.Status, e.Message)
+}
+
+// HTTPStatus implements llm.StatusError
+func (e *APIError) HTTPStatus() int {
+	return e.StatusCode
+}
+
+// Chat implements the LLM interface
+func (p *GeminiProvider) Chat(ctx context.Context, messages []llm.Message, opts ...llm.Option) (llm.Response, error) {
+	model, request, options, err := p.buildRequest(messages, opts)
+	if err != nil {
+		return llm.Response{}, err
+	}
+
+	resp, err := p.post(ctx, model, "generateContent", nil, request, options.Headers)
+	if err != nil {
+		return llm.Response{}, err
+	}
+	defer resp.Body.Close()
+
+	var result generateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return llm.Response{}, fmt.Errorf("gemini: failed to decode response: %w", err)
+	}
+	if len(result.Candidates) == 0 {
+		return llm.Response{}, blockedError(result)
+	}
+
+	message := llm.Message{Role: llm.RoleAssistant}
+	appendParts(&message, result.Candidates[0].Content.Parts)
+
+	return llm.Response{
+		Message: message,
+		Usage:   result.UsageMetadata.toUsage(),
+	}, nil
+}
+
+// ChatStream implements the LLM interface
+func (p *GeminiProvider) ChatStream(ctx context.Context, messages []llm.Message, opts ...llm.Option) (llm.Stream, error) {
+	model, request, options, err := p.buildRequest(messages, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{"alt": {"sse"}}
+	resp, err := p.post(ctx, model, "streamGenerateContent", query, request, options.Headers)
+	if err != nil {
+		return nil, err
+	}
+
+	return newGeminiStream(resp.Body), nil
+}
+
+// post calls a model method (e.g. models/{model}:generateContent) and returns the successful response
+func (p *GeminiProvider) post(ctx context.Context, model, method string, query url.Values, payload any, headers map[string]string) (*http.Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("gemini: failed to encode request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/%s/models/%s:%s", p.baseURL, p.apiVersion, strings.TrimPrefix(model, "models/"), method)
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", p.apiKey)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, decodeAPIError(resp)
+	}
+	return resp, nil
+}
+
+func decodeAPIError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	var payload errorResponse
+	if err := json.Unmarshal(data, &payload); err != nil || payload.Error.Message == "" {
+		return &APIError{StatusCode: resp.StatusCode, Status: http.StatusText(resp.StatusCode), Message: strings.TrimSpace(string(data))}
+	}
+	return &APIError{StatusCode: resp.StatusCode, Status: payload.Error.Status, Message: payload.Error.Message}
+}
+
+// blockedError explains an empty candidate list (usually a safety block)
+func blockedError(result generateResponse) error {
+	if result.PromptFeedback != nil && result.PromptFeedback.BlockReason != "" {
+		return fmt.Errorf("gemini: prompt blocked: %s", result.PromptFeedback.BlockReason)
+	}
+	return fmt.Errorf("gemini: response contained no candidates")
+}
+
+// ============================================================================
+// Wire types
+// ============================================================================
+
+type generateRequest struct {
+	SystemInstruction *content          `json:"systemInstruction,omitempty"`
+	Contents          []content         `json:"contents"`
+	Tools             []toolDeclaration `json:"tools,omitempty"`
+	ToolConfig        *toolConfig       `json:"toolConfig,omitempty"`
+	GenerationConfig  *generationConfig `json:"generationConfig,omitempty"`
+}
+
+type content struct {
+	Role  string `json:"role,omitempty"`
+	Parts []part `json:"parts"`
+}
+
+type part struct {
+	Text             string            `json:"text,omitempty"`
+	FunctionCall     *functionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *functionResponse `json:"functionResponse,omitempty"`
+}
+
+type functionCall struct {
+	ID   string          `json:"id,omitempty"`
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+type functionResponse struct {
+	ID       string         `json:"id,omitempty"`
+	Name     string         `json:"name"`
+	Response map[string]any `json:"response"`
+}
+
+type toolDeclaration struct {
+	FunctionDeclarations []functionDeclaration `json:"functionDeclarations"`
+}
+
+type functionDeclaration struct {
+	Name                 string `json:"name"`
+	Description          string `json:"description,omitempty"`
+	ParametersJSONSchema any    `json:"parametersJsonSchema,omitempty"`
+}
+
+type toolConfig struct {
+	FunctionCallingConfig functionCallingConfig `json:"functionCallingConfig"`
+}
+
+type functionCallingConfig struct {
+	Mode                 string   `json:"mode"` // AUTO, ANY, NONE
+	AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
+}
+
+type generationConfig struct {
+	Temperature        *float32 `json:"temperature,omitempty"`
+	TopP               *float32 `json:"topP,omitempty"`
+	MaxOutputTokens    int      `json:"maxOutputTokens,omitempty"`
+	StopSequences      []string `json:"stopSequences,omitempty"`
+	Seed               *int64   `json:"seed,omitempty"`
+	PresencePenalty    *float32 `json:"presencePenalty,omitempty"`
+	FrequencyPenalty   *float32 `json:"frequencyPenalty,omitempty"`
+	ResponseMimeType   string   `json:"responseMimeType,omitempty"`
+	ResponseJSONSchema any      `json:"responseJsonSchema,omitempty"`
+}
+
+type generateResponse struct {
+	Candidates []struct {
+		Content      content `json:"content"`
+		FinishReason string  `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback *struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback,omitempty"`
+	UsageMetadata usageMetadata `json:"usageMetadata"`
+}
+
+type usageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+	ThoughtsTokenCount   int `json:"thoughtsTokenCount"`
+	TotalTokenCount      int `json:"totalTokenCount"`
+}
+
+// toUsage counts thinking tokens as completion tokens
+func (u usageMetadata) toUsage() llm.Usage {
+	completion := u.CandidatesTokenCount + u.ThoughtsTokenCount
+	total := u.TotalTokenCount
+	if total == 0 {
+		total = u.PromptTokenCount + completion
+	}
+	return llm.Usage{
+		PromptTokens:     u.PromptTokenCount,
+		CompletionTokens: completion,
+		TotalTokens:      total,
+	}
+}
+
+type errorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error"`
+}
+
+// ============================================================================
+// Conversion
+// ============================================================================
+
+func (p *GeminiProvider) buildRequest(messages []llm.Message, opts []llm.Option) (string, generateRequest, *llm.ChatOptions, error) {
+	options := llm.DefaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	model := options.Model
+	if model == "" {
+		model = p.defaultModel
+	}
+
+	system, contents, err := convertMessages(messages)
+	if err != nil {
+		return "", generateRequest{}, nil, err
+	}
+
+	request := generateRequest{
+		Contents:         contents,
+		GenerationConfig: convertGenerationConfig(options),
+	}
+	if system != "" {
+		request.SystemInstruction = &content{Parts: []part{{Text: system}}}
+	}
+
+	if declarations := convertTools(options.Tools, options.Functions); len(declarations) > 0 {
+		request.Tools = []toolDeclaration{{FunctionDeclarations: declarations}}
+		if options.ToolChoice != nil {
+			request.ToolConfig = convertToolChoice(options.ToolChoice)
+		}
+	}
+
+	return model, request, options, nil
+}
+
+// convertMessages splits out system messages and maps the rest to Gemini contents
+// Assistant turns use the "model" role, tool results become functionResponse parts
+// in a user turn, and consecutive turns with the same role are merged
+func convertMessages(messages []llm.Message) (string, []content, error) {
+	var system []string
+	contents := make([]content, 0, len(messages))
+	toolNames := make(map[string]string) // tool call ID -> function name
+
+	appendTurn := func(role string, parts ...part) {
+		if len(parts) == 0 {
+			return
+		}
+		if n := len(contents); n > 0 && contents[n-1].Role == role {
+			contents[n-1].Parts = append(contents[n-1].Parts, parts...)
+			return
+		}
+		contents = append(contents, content{Role: role, Parts: parts})
+	}
+
+	for _, msg := range messages {
+		switch msg.Role {
+		case llm.RoleSystem:
+			if msg.Content != "" {
+				system = append(system, msg.Content)
+			}
+
+		case llm.RoleUser:
+			if msg.Content != "" {
+				appendTurn("user", part{Text: msg.Content})
+			}
+
+		case llm.RoleAssistant:
+			parts := make([]part, 0, len(msg.ToolCalls)+1)
+			if msg.Content != "" {
+				parts = append(parts, part{Text: msg.Content})
+			}
+			for _, tc := range msg.ToolCalls {
+				args := strings.TrimSpace(tc.Function.Arguments)
+				if args == "" {
+					args = "{}"
+				}
+				if !json.Valid([]byte(args)) {
+					return "", nil, fmt.Errorf("gemini: tool call %s has invalid JSON arguments", tc.ID)
+				}
+				toolNames[tc.ID] = tc.Function.Name
+				parts = append(parts, part{FunctionCall: &functionCall{
+					ID:   tc.ID,
+					Name: tc.Function.Name,
+					Args: json.RawMessage(args),
+				}})
+			}
+			appendTurn("model", parts...)
+
+		case llm.RoleTool, llm.RoleFunction:
+			name := msg.Name
+			if name == "" {
+				name = toolNames[msg.ToolCallID]
+			}
+			if name == "" {
+				return "", nil, fmt.Errorf("gemini: tool result %s has no matching tool call", msg.ToolCallID)
+			}
+			appendTurn("user", part{FunctionResponse: &functionResponse{
+				ID:       msg.ToolCallID,
+				Name:     name,
+				Response: toolResponse(msg),
+			}})
+
+		default:
+			return "", nil, fmt.Errorf("gemini: unsupported message role %q", msg.Role)
+		}
+	}
+
+	return strings.Join(system, "\n\n"), contents, nil
+}
+
+// toolResponse wraps a tool result in the object Gemini expects
+// JSON object results are passed through; anything else goes under "output"
+// (or "error" when the tool failed)
+func toolResponse(msg llm.Message) map[string]any {
+	if _, failed := msg.Metadata[toolx.MetadataToolError]; failed {
+		return map[string]any{"error": msg.Content}
+	}
+
+	var object map[string]any
+	if err := json.Unmarshal([]byte(msg.Content), &object); err == nil && object != nil {
+		return object
+	}
+	return map[string]any{"output": msg.Content}
+}
+
+func convertGenerationConfig(options *llm.ChatOptions) *generationConfig {
+	config := &generationConfig{
+		MaxOutputTokens: options.MaxCompletionTokens,
+		StopSequences:   options.Stop,
+	}
+	if config.MaxOutputTokens == 0 {
+		config.MaxOutputTokens = options.MaxTokens
+	}
+
+	if options.TemperatureSet {
+		temperature := options.Temperature
+		config.Temperature = &temperature
+	}
+	if options.TopP > 0 && options.TopP < 1 {
+		topP := options.TopP
+		config.TopP = &topP
+	}
+	if options.Seed != 0 {
+		seed := options.Seed
+		config.Seed = &seed
+	}
+	if options.PresencePenalty != 0 {
+		penalty := options.PresencePenalty
+		config.PresencePenalty = &penalty
+	}
+	if options.FrequencyPenalty != 0 {
+		penalty := options.FrequencyPenalty
+		config.FrequencyPenalty = &penalty
+	}
+
+	switch {
+	case options.ResponseFormat != nil && options.ResponseFormat.Type == llm.JSONSchema:
+		config.ResponseMimeType = "application/json"
+		config.ResponseJSONSchema = options.ResponseFormat.JSONSchema
+	case options.JSONMode || (options.ResponseFormat != nil && options.ResponseFormat.Type == llm.JSONObject):
+		config.ResponseMimeType = "application/json"
+	}
+
+	return config
+}
+
+func convertTools(tools []llm.Tool, functions []llm.Function) []functionDeclaration {
+	declarations := make([]functionDeclaration, 0, len(tools)+len(functions))
+	for _, tool := range tools {
+		declarations = append(declarations, convertFunction(tool.Function))
+	}
+	for _, function := range functions {
+		declarations = append(declarations, convertFunction(function))
+	}
+	return declarations
+}
+
+func convertFunction(function llm.Function) functionDeclaration {
+	return functionDeclaration{
+		Name:                 function.Name,
+		Description:          function.Description,
+		ParametersJSONSchema: function.Parameters,
+	}
+}
+
+// convertToolChoice maps OpenAI-style tool choices to a function calling mode
+func convertToolChoice(toolChoice any) *toolConfig {
+	mode := func(mode string, names ...string) *toolConfig {
+		return &toolConfig{FunctionCallingConfig: functionCallingConfig{Mode: mode, AllowedFunctionNames: names}}
+	}
+
+	switch choice := toolChoice.(type) {
+	case string:
+		switch choice {
+		case "none":
+			return mode("NONE")
+		case "required", "any":
+			return mode("ANY")
+		case "auto", "":
+			return mode("AUTO")
+		default:
+			// A bare tool name
+			return mode("ANY", choice)
+		}
+	case map[string]any:
+		// {"type": "function", "function": {"name": "..."}}
+		if function, ok := choice["function"].(map[string]any); ok {
+			if name, ok := function["name"].(string); ok {
+				return mode("ANY", name)
+			}
+		}
+	}
+	return mode("AUTO")
+}
+
+// appendParts adds response parts to an assistant message
+// Gemini may omit function call IDs, so one is generated to pair results later
+func appendParts(message *llm.Message, parts []part) {
+	for _, p := range parts {
+		if p.Text != "" {
+			message.Content += p.Text
+		}
+		if p.FunctionCall != nil {
+			id := p.FunctionCall.ID
+			if id == "" {
+				id = "call_" + uuid.NewString()
+			}
+			args := string(p.FunctionCall.Args)
+			if args == "" || args == "null" {
+				args = "{}"
+			}
+			message.ToolCalls = append(message.ToolCalls, llm.ToolCall{
+				ID:       id,
+				Type:     "function",
+				Function: llm.FunctionCall{Name: p.FunctionCall.Name, Arguments: args},
+			})
+		}
+	}
+}
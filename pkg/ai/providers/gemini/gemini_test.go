@@ -0,0 +1,268 @@
+package aigemini
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Abraxas-365/ams/pkg/ai/embedding"
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/toolx"
+)
+
+// recordedServer replays a recorded response for the given path and captures the request body
+func recordedServer(t *testing.T, path string, status int, contentType, fixture string, captured *map[string]any) *httptest.Server {
+	t.Helper()
+
+	body, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			t.Errorf("path = %s, want %s", r.URL.Path, path)
+		}
+		if got := r.Header.Get("x-goog-api-key"); got != "test-key" {
+			t.Errorf("x-goog-api-key = %q", got)
+		}
+		if captured != nil {
+			if err := json.NewDecoder(r.Body).Decode(captured); err != nil {
+				t.Errorf("decode request: %v", err)
+			}
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestChatText(t *testing.T) {
+	var request map[string]any
+	server := recordedServer(t, "/v1beta/models/gemini-2.5-pro:generateContent", http.StatusOK, "application/json", "generate_text.json", &request)
+	provider := NewGeminiProvider("test-key", WithBaseURL(server.URL))
+
+	resp, err := provider.Chat(context.Background(), []llm.Message{
+		llm.NewSystemMessage("You are helpful."),
+		llm.NewUserMessage("Hi"),
+	}, llm.WithModel("gemini-2.5-pro"), llm.WithMaxCompletionTokens(128), llm.WithSeed(7))
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	if resp.Message.Content != "Hello! How can I help you today?" || resp.Message.Role != llm.RoleAssistant {
+		t.Errorf("message = %+v", resp.Message)
+	}
+	if resp.Usage != (llm.Usage{PromptTokens: 8, CompletionTokens: 9, TotalTokens: 17}) {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+
+	instruction, _ := request["systemInstruction"].(map[string]any)
+	parts, _ := instruction["parts"].([]any)
+	if len(parts) != 1 || parts[0].(map[string]any)["text"] != "You are helpful." {
+		t.Errorf("systemInstruction = %v", request["systemInstruction"])
+	}
+	contents, _ := request["contents"].([]any)
+	if len(contents) != 1 || contents[0].(map[string]any)["role"] != "user" {
+		t.Errorf("contents = %v", request["contents"])
+	}
+	config, _ := request["generationConfig"].(map[string]any)
+	if config["maxOutputTokens"] != float64(128) || config["seed"] != float64(7) {
+		t.Errorf("generationConfig = %v", config)
+	}
+}
+
+func TestChatFunctionCalling(t *testing.T) {
+	var request map[string]any
+	server := recordedServer(t, "/v1beta/models/gemini-2.5-flash:generateContent", http.StatusOK, "application/json", "generate_function_call.json", &request)
+	provider := NewGeminiProvider("test-key", WithBaseURL(server.URL))
+
+	history := []llm.Message{
+		llm.NewUserMessage("Weather in Cusco and Lima?"),
+		{
+			Role: llm.RoleAssistant,
+			ToolCalls: []llm.ToolCall{
+				{ID: "call_1", Type: "function", Function: llm.FunctionCall{Name: "get_weather", Arguments: `{"location":"Cusco"}`}},
+				{ID: "call_2", Type: "function", Function: llm.FunctionCall{Name: "lookup_city", Arguments: `{"name":"Lima"}`}},
+			},
+		},
+		llm.NewToolMessage("call_1", `{"temp_c": 14}`),
+		{
+			Role:       llm.RoleTool,
+			Content:    "service unavailable",
+			ToolCallID: "call_2",
+			Metadata:   map[string]any{toolx.MetadataToolError: "service unavailable"},
+		},
+	}
+	tools := []llm.Tool{{
+		Type: "function",
+		Function: llm.Function{
+			Name:        "get_weather",
+			Description: "Get the weather",
+			Parameters: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"location": map[string]any{"type": "string"}},
+				"required":   []string{"location"},
+			},
+		},
+	}}
+
+	resp, err := provider.Chat(context.Background(), history, llm.WithTools(tools), llm.WithToolChoice("required"))
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	if len(resp.Message.ToolCalls) != 1 {
+		t.Fatalf("tool calls = %+v", resp.Message.ToolCalls)
+	}
+	call := resp.Message.ToolCalls[0]
+	if call.ID == "" || call.Function.Name != "get_weather" || call.Function.Arguments != `{"location": "Lima"}` {
+		t.Errorf("tool call = %+v", call)
+	}
+	if resp.Usage != (llm.Usage{PromptTokens: 60, CompletionTokens: 35, TotalTokens: 95}) {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+
+	// Request mapping: model turn with function calls, then one user turn with both responses
+	contents, _ := request["contents"].([]any)
+	if len(contents) != 3 {
+		t.Fatalf("contents = %v", contents)
+	}
+	model := contents[1].(map[string]any)
+	modelParts := model["parts"].([]any)
+	if model["role"] != "model" || len(modelParts) != 2 {
+		t.Fatalf("model turn = %v", model)
+	}
+	firstCall := modelParts[0].(map[string]any)["functionCall"].(map[string]any)
+	if firstCall["name"] != "get_weather" || firstCall["args"].(map[string]any)["location"] != "Cusco" {
+		t.Errorf("functionCall = %v", firstCall)
+	}
+
+	results := contents[2].(map[string]any)
+	resultParts := results["parts"].([]any)
+	if results["role"] != "user" || len(resultParts) != 2 {
+		t.Fatalf("tool result turn = %v", results)
+	}
+	weather := resultParts[0].(map[string]any)["functionResponse"].(map[string]any)
+	if weather["name"] != "get_weather" || weather["response"].(map[string]any)["temp_c"] != float64(14) {
+		t.Errorf("functionResponse = %v", weather)
+	}
+	failed := resultParts[1].(map[string]any)["functionResponse"].(map[string]any)
+	if failed["name"] != "lookup_city" || failed["response"].(map[string]any)["error"] != "service unavailable" {
+		t.Errorf("functionResponse = %v", failed)
+	}
+
+	declarations := request["tools"].([]any)[0].(map[string]any)["functionDeclarations"].([]any)
+	if len(declarations) != 1 || declarations[0].(map[string]any)["parametersJsonSchema"] == nil {
+		t.Errorf("tools = %v", request["tools"])
+	}
+	config := request["toolConfig"].(map[string]any)["functionCallingConfig"].(map[string]any)
+	if config["mode"] != "ANY" {
+		t.Errorf("toolConfig = %v", request["toolConfig"])
+	}
+}
+
+func TestChatResponseFormat(t *testing.T) {
+	var request map[string]any
+	server := recordedServer(t, "/v1beta/models/gemini-2.5-flash:generateContent", http.StatusOK, "application/json", "generate_text.json", &request)
+	provider := NewGeminiProvider("test-key", WithBaseURL(server.URL))
+
+	schema := map[string]any{"type": "object", "required": []string{"answer"}}
+	_, err := provider.Chat(context.Background(), []llm.Message{llm.NewUserMessage("Hi")}, llm.WithJSONSchemaResponseFormat(schema))
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	config, _ := request["generationConfig"].(map[string]any)
+	if config["responseMimeType"] != "application/json" || config["responseJsonSchema"] == nil {
+		t.Errorf("generationConfig = %v", config)
+	}
+}
+
+func TestChatAPIError(t *testing.T) {
+	server := recordedServer(t, "/v1beta/models/gemini-2.5-flash:generateContent", http.StatusTooManyRequests, "application/json", "generate_error.json", nil)
+	provider := NewGeminiProvider("test-key", WithBaseURL(server.URL))
+
+	_, err := provider.Chat(context.Background(), []llm.Message{llm.NewUserMessage("Hi")})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusTooManyRequests || apiErr.Status != "RESOURCE_EXHAUSTED" {
+		t.Errorf("error = %+v", apiErr)
+	}
+}
+
+func TestChatStream(t *testing.T) {
+	server := recordedServer(t, "/v1beta/models/gemini-2.5-flash:streamGenerateContent", http.StatusOK, "text/event-stream", "stream_generate.txt", nil)
+	provider := NewGeminiProvider("test-key", WithBaseURL(server.URL))
+
+	stream, err := provider.ChatStream(context.Background(), []llm.Message{llm.NewUserMessage("Weather in Lima?")})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	defer stream.Close()
+
+	var deltas []string
+	var final llm.Message
+	for {
+		msg, err := stream.Next()
+		if err == io.EOF {
+			final = msg
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		deltas = append(deltas, msg.Content)
+	}
+
+	if strings.Join(deltas, "|") != "Okay, let me |check." {
+		t.Errorf("deltas = %q", deltas)
+	}
+	if final.Content != "Okay, let me check." {
+		t.Errorf("final content = %q", final.Content)
+	}
+	if len(final.ToolCalls) != 1 || final.ToolCalls[0].Function.Name != "get_weather" ||
+		final.ToolCalls[0].Function.Arguments != `{"location": "Lima"}` {
+		t.Errorf("final tool calls = %+v", final.ToolCalls)
+	}
+	if usage, _ := llm.StreamUsage(final); usage != (llm.Usage{PromptTokens: 40, CompletionTokens: 12, TotalTokens: 52}) {
+		t.Errorf("final usage = %+v", usage)
+	}
+}
+
+func TestEmbedDocuments(t *testing.T) {
+	var request map[string]any
+	server := recordedServer(t, "/v1beta/models/gemini-embedding-001:batchEmbedContents", http.StatusOK, "application/json", "batch_embed.json", &request)
+	provider := NewGeminiProvider("test-key", WithBaseURL(server.URL))
+
+	embeddings, err := provider.EmbedDocuments(context.Background(), []string{"hello", "world"}, embedding.WithDimensions(3))
+	if err != nil {
+		t.Fatalf("EmbedDocuments: %v", err)
+	}
+
+	if len(embeddings) != 2 || len(embeddings[0].Vector) != 3 || embeddings[1].Vector[2] != float32(0.0277) {
+		t.Errorf("embeddings = %+v", embeddings)
+	}
+
+	requests, _ := request["requests"].([]any)
+	if len(requests) != 2 {
+		t.Fatalf("requests = %v", request["requests"])
+	}
+	first := requests[0].(map[string]any)
+	if first["model"] != "models/gemini-embedding-001" || first["outputDimensionality"] != float64(3) {
+		t.Errorf("request = %v", first)
+	}
+}
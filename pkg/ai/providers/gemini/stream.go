@@ -0,0 +1,88 @@
+package aigemini
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Abraxas-365/ams/pkg/ai/llm"
+)
+
+// geminiStream adapts streamGenerateContent server-sent events to our Stream interface
+// Each event is a partial GenerateContentResponse; Next returns text deltas and
+// the complete message (function calls arrive whole, with the usage) together with io.EOF
+type geminiStream struct {
+	body      io.ReadCloser
+	scanner   *bufio.Scanner
+	lastError error
+	current   llm.Message
+	usage     llm.Usage
+}
+
+func newGeminiStream(body io.ReadCloser) *geminiStream {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	return &geminiStream{
+		body:    body,
+		scanner: scanner,
+		current: llm.Message{Role: llm.RoleAssistant},
+	}
+}
+
+func (s *geminiStream) Next() (llm.Message, error) {
+	if s.lastError != nil {
+		return llm.Message{}, s.lastError
+	}
+
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		var chunk generateResponse
+		payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if err := json.Unmarshal([]byte(payload), &chunk); err != nil {
+			s.lastError = fmt.Errorf("gemini: invalid stream event: %w", err)
+			return llm.Message{}, s.lastError
+		}
+		// Each event carries the usage so far
+		if chunk.UsageMetadata.TotalTokenCount > 0 {
+			s.usage = chunk.UsageMetadata.toUsage()
+		}
+		if len(chunk.Candidates) == 0 {
+			if chunk.PromptFeedback != nil && chunk.PromptFeedback.BlockReason != "" {
+				s.lastError = blockedError(chunk)
+				return llm.Message{}, s.lastError
+			}
+			continue
+		}
+
+		delta := llm.Message{Role: llm.RoleAssistant}
+		appendParts(&delta, chunk.Candidates[0].Content.Parts)
+		s.current.Content += delta.Content
+		s.current.ToolCalls = append(s.current.ToolCalls, delta.ToolCalls...)
+
+		if delta.Content != "" {
+			return llm.Message{Role: llm.RoleAssistant, Content: delta.Content}, nil
+		}
+	}
+
+	if err := s.scanner.Err(); err != nil {
+		s.lastError = err
+		return llm.Message{}, err
+	}
+
+	s.lastError = io.EOF
+	if s.usage.TotalTokens > 0 {
+		s.current = llm.WithStreamUsage(s.current, s.usage)
+	}
+	return s.current, io.EOF
+}
+
+func (s *geminiStream) Close() error {
+	return s.body.Close()
+}
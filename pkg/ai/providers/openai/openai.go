@@ -12,6 +12,7 @@ import (
 	"github.com/Abraxas-365/ams/pkg/ai/embedding"
 	"github.com/Abraxas-365/ams/pkg/ai/llm"
 	"github.com/Abraxas-365/ams/pkg/ai/speech"
+	"github.com/Abraxas-365/ams/pkg/logx"
 	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/option"
 	"github.com/openai/openai-go/v3/packages/param"
@@ -19,6 +20,9 @@ import (
 	"github.com/openai/openai-go/v3/shared/constant"
 )
 
+// DefaultModel is the chat model used when a request doesn't choose one
+const DefaultModel = "gpt-5-mini-2025-08-07"
+
 // OpenAIProvider implements the LLM interface for OpenAI
 type OpenAIProvider struct {
 	client openai.Client
@@ -38,9 +42,47 @@ func NewOpenAIProvider(apiKey string, opts ...option.RequestOption) *OpenAIProvi
 	}
 }
 
+// Models served by the OpenAI API, registered for manifest validation
+var supportedModels = []string{
+	"gpt-5", "gpt-5-mini", "gpt-5-nano",
+	"gpt-4.1", "gpt-4.1-mini", "gpt-4.1-nano",
+	"gpt-4o", "gpt-4o-mini", "gpt-4-turbo", "gpt-3.5-turbo",
+	"o1", "o1-mini", "o3", "o3-mini", "o4-mini",
+}
+
+func init() {
+	llm.RegisterModels(supportedModels...)
+}
+
+// apiError exposes the HTTP status of an OpenAI API error (see llm.StatusError)
+// It unwraps to *openai.Error, so errors.As keeps working for callers
+type apiError struct {
+	err *openai.Error
+}
+
+func (e *apiError) Error() string {
+	return e.err.Error()
+}
+
+func (e *apiError) HTTPStatus() int {
+	return e.err.StatusCode
+}
+
+func (e *apiError) Unwrap() error {
+	return e.err
+}
+
+func wrapError(err error) error {
+	var openaiErr *openai.Error
+	if errors.As(err, &openaiErr) {
+		return &apiError{err: openaiErr}
+	}
+	return err
+}
+
 func defaultChatOptions() *llm.ChatOptions {
 	options := llm.DefaultOptions()
-	options.Model = "gpt-5-mini-2025-08-07"
+	options.Model = DefaultModel
 	return options
 }
 
@@ -68,7 +110,7 @@ func (p *OpenAIProvider) Chat(ctx context.Context, messages []llm.Message, opts
 	}
 
 	// Set optional parameters
-	if options.Temperature != 0 {
+	if options.TemperatureSet {
 		params.Temperature = openai.Float(float64(options.Temperature))
 	}
 
@@ -141,7 +183,7 @@ func (p *OpenAIProvider) Chat(ctx context.Context, messages []llm.Message, opts
 	// Make the API call
 	completion, err := p.client.Chat.Completions.New(ctx, params)
 	if err != nil {
-		return llm.Response{}, err
+		return llm.Response{}, wrapError(err)
 	}
 
 	// Convert the response
@@ -172,7 +214,7 @@ func (p *OpenAIProvider) ChatStream(ctx context.Context, messages []llm.Message,
 	}
 
 	// Set optional parameters
-	if options.Temperature != 0 {
+	if options.TemperatureSet {
 		params.Temperature = openai.Float(float64(options.Temperature))
 	}
 
@@ -217,6 +259,11 @@ func (p *OpenAIProvider) ChatStream(ctx context.Context, messages []llm.Message,
 		params.ResponseFormat = convertToResponseFormatParam(options.ResponseFormat)
 	}
 
+	// Ask for the final usage chunk
+	params.StreamOptions = openai.ChatCompletionStreamOptionsParam{
+		IncludeUsage: openai.Bool(true),
+	}
+
 	// Create the stream
 	sseStream := p.client.Chat.Completions.NewStreaming(ctx, params)
 
@@ -228,6 +275,8 @@ func (p *OpenAIProvider) ChatStream(ctx context.Context, messages []llm.Message,
 }
 
 // openAIStream adapts the OpenAI streaming response to our Stream interface
+// Next returns content deltas; tool call deltas are assembled by index and the
+// complete message (content, tool calls and usage) is returned together with io.EOF
 type openAIStream struct {
 	stream interface {
 		Next() bool
@@ -237,6 +286,8 @@ type openAIStream struct {
 	accumulator openai.ChatCompletionAccumulator
 	lastError   error
 	current     llm.Message
+	toolIndexes map[int64]int // OpenAI tool call index -> position in current.ToolCalls
+	usage       *llm.Usage
 }
 
 func (s *openAIStream) Next() (llm.Message, error) {
@@ -246,16 +297,29 @@ func (s *openAIStream) Next() (llm.Message, error) {
 
 	if !s.stream.Next() {
 		if err := s.stream.Err(); err != nil {
-			s.lastError = err
-			return llm.Message{}, err
+			s.lastError = wrapError(err)
+			return llm.Message{}, s.lastError
 		}
 		s.lastError = io.EOF
-		return llm.Message{}, io.EOF
+		s.current.Role = llm.RoleAssistant
+		if s.usage != nil {
+			s.current = llm.WithStreamUsage(s.current, *s.usage)
+		}
+		return s.current, io.EOF
 	}
 
 	chunk := s.stream.Current()
 	s.accumulator.AddChunk(chunk)
 
+	// The usage chunk comes last, without choices
+	if chunk.Usage.TotalTokens > 0 {
+		s.usage = &llm.Usage{
+			PromptTokens:     int(chunk.Usage.PromptTokens),
+			CompletionTokens: int(chunk.Usage.CompletionTokens),
+			TotalTokens:      int(chunk.Usage.TotalTokens),
+		}
+	}
+
 	if len(chunk.Choices) == 0 {
 		return llm.Message{}, nil
 	}
@@ -265,36 +329,32 @@ func (s *openAIStream) Next() (llm.Message, error) {
 	s.current.Role = llm.RoleAssistant
 	s.current.Content += delta.Content
 
-	if len(delta.ToolCalls) > 0 {
-		if s.current.ToolCalls == nil {
-			s.current.ToolCalls = make([]llm.ToolCall, 0)
+	// Only the first delta of a tool call carries its ID and name,
+	// later deltas carry argument fragments identified by index
+	for _, tc := range delta.ToolCalls {
+		if s.toolIndexes == nil {
+			s.toolIndexes = make(map[int64]int)
 		}
 
-		for _, tc := range delta.ToolCalls {
-			found := false
-			for i, existingTC := range s.current.ToolCalls {
-				if existingTC.ID == tc.ID {
-					s.current.ToolCalls[i].Function.Name += tc.Function.Name
-					s.current.ToolCalls[i].Function.Arguments += tc.Function.Arguments
-					found = true
-					break
-				}
-			}
+		position, exists := s.toolIndexes[tc.Index]
+		if !exists {
+			position = len(s.current.ToolCalls)
+			s.toolIndexes[tc.Index] = position
+			s.current.ToolCalls = append(s.current.ToolCalls, llm.ToolCall{Type: "function"})
+		}
 
-			if !found && tc.ID != "" {
-				s.current.ToolCalls = append(s.current.ToolCalls, llm.ToolCall{
-					ID:   tc.ID,
-					Type: "function",
-					Function: llm.FunctionCall{
-						Name:      tc.Function.Name,
-						Arguments: tc.Function.Arguments,
-					},
-				})
-			}
+		toolCall := &s.current.ToolCalls[position]
+		if tc.ID != "" {
+			toolCall.ID = tc.ID
 		}
+		toolCall.Function.Name += tc.Function.Name
+		toolCall.Function.Arguments += tc.Function.Arguments
 	}
 
-	return s.current, nil
+	return llm.Message{
+		Role:    llm.RoleAssistant,
+		Content: delta.Content,
+	}, nil
 }
 
 func (s *openAIStream) Close() error {
@@ -303,11 +363,54 @@ func (s *openAIStream) Close() error {
 
 // Helper functions
 
+// convertContentParts builds a multimodal user content array, Content first
+func convertContentParts(msg llm.Message) []openai.ChatCompletionContentPartUnionParam {
+	parts := make([]openai.ChatCompletionContentPartUnionParam, 0, len(msg.Parts)+1)
+	if msg.Content != "" {
+		parts = append(parts, openai.TextContentPart(msg.Content))
+	}
+
+	for _, part := range msg.Parts {
+		switch {
+		case part.Type == llm.PartText:
+			parts = append(parts, openai.TextContentPart(part.Text))
+		case part.Type == llm.PartImageURL:
+			parts = append(parts, openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{
+				URL:    part.URL,
+				Detail: part.Detail,
+			}))
+		case part.IsImage() && part.Data != "":
+			parts = append(parts, openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{
+				URL:    part.DataURI(),
+				Detail: part.Detail,
+			}))
+		case part.Type == llm.PartFile && part.Data != "":
+			file := openai.ChatCompletionContentPartFileFileParam{
+				FileData: openai.String(part.DataURI()),
+			}
+			if part.Filename != "" {
+				file.Filename = openai.String(part.Filename)
+			}
+			parts = append(parts, openai.FileContentPart(file))
+		default:
+			// Unresolved file references need llm.ResolveFiles
+			logx.WithFields(logx.Fields{
+				"type": part.Type,
+				"path": part.Path,
+			}).Warn("Skipping content part without inline data")
+		}
+	}
+	return parts
+}
+
 func convertToOpenAIMessage(msg llm.Message) (openai.ChatCompletionMessageParamUnion, error) {
 	switch msg.Role {
 	case llm.RoleSystem:
 		return openai.SystemMessage(msg.Content), nil
 	case llm.RoleUser:
+		if len(msg.Parts) > 0 {
+			return openai.UserMessage(convertContentParts(msg)), nil
+		}
 		return openai.UserMessage(msg.Content), nil
 	case llm.RoleAssistant:
 		if len(msg.ToolCalls) > 0 {
@@ -451,10 +554,15 @@ func convertToResponseFormatParam(format *llm.ResponseFormat) openai.ChatComplet
 			}
 		}
 
+		name := format.Name
+		if name == "" {
+			name = "schema"
+		}
+
 		return openai.ChatCompletionNewParamsResponseFormatUnion{
 			OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
 				JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
-					Name:   "schema",
+					Name:   name,
 					Schema: schema,
 				},
 			},
@@ -3,6 +3,8 @@ package fsx
 import (
 	"context"
 	"io"
+	"path"
+	"strings"
 	"time"
 )
 
@@ -55,3 +57,10 @@ type PathReader interface {
 	FileReader
 	PathOperations
 }
+
+// IsRelativePath reports whether p stays under the file system root, rejecting
+// absolute paths and paths that escape it with ".."
+func IsRelativePath(p string) bool {
+	cleaned := path.Clean(strings.ReplaceAll(p, "\\", "/"))
+	return !path.IsAbs(cleaned) && cleaned != ".." && !strings.HasPrefix(cleaned, "../")
+}
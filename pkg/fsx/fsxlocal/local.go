@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/Abraxas-365/ams/pkg/fsx"
 )
@@ -38,7 +39,10 @@ func NewLocalFileSystem(basePath string) (*LocalFileSystem, error) {
 // ============================================================================
 
 func (fs *LocalFileSystem) ReadFile(ctx context.Context, path string) ([]byte, error) {
-	fullPath := fs.fullPath(path)
+	fullPath, err := fs.fullPath(path)
+	if err != nil {
+		return nil, err
+	}
 	data, err := os.ReadFile(fullPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -50,7 +54,10 @@ func (fs *LocalFileSystem) ReadFile(ctx context.Context, path string) ([]byte, e
 }
 
 func (fs *LocalFileSystem) ReadFileStream(ctx context.Context, path string) (io.ReadCloser, error) {
-	fullPath := fs.fullPath(path)
+	fullPath, err := fs.fullPath(path)
+	if err != nil {
+		return nil, err
+	}
 	file, err := os.Open(fullPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -62,7 +69,10 @@ func (fs *LocalFileSystem) ReadFileStream(ctx context.Context, path string) (io.
 }
 
 func (fs *LocalFileSystem) Stat(ctx context.Context, path string) (fsx.FileInfo, error) {
-	fullPath := fs.fullPath(path)
+	fullPath, err := fs.fullPath(path)
+	if err != nil {
+		return fsx.FileInfo{}, err
+	}
 	info, err := os.Stat(fullPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -82,7 +92,10 @@ func (fs *LocalFileSystem) Stat(ctx context.Context, path string) (fsx.FileInfo,
 }
 
 func (fs *LocalFileSystem) List(ctx context.Context, path string) ([]fsx.FileInfo, error) {
-	fullPath := fs.fullPath(path)
+	fullPath, err := fs.fullPath(path)
+	if err != nil {
+		return nil, err
+	}
 	entries, err := os.ReadDir(fullPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -112,8 +125,11 @@ func (fs *LocalFileSystem) List(ctx context.Context, path string) ([]fsx.FileInf
 }
 
 func (fs *LocalFileSystem) Exists(ctx context.Context, path string) (bool, error) {
-	fullPath := fs.fullPath(path)
-	_, err := os.Stat(fullPath)
+	fullPath, err := fs.fullPath(path)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(fullPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return false, nil
@@ -128,7 +144,10 @@ func (fs *LocalFileSystem) Exists(ctx context.Context, path string) (bool, error
 // ============================================================================
 
 func (fs *LocalFileSystem) WriteFile(ctx context.Context, path string, data []byte) error {
-	fullPath := fs.fullPath(path)
+	fullPath, err := fs.fullPath(path)
+	if err != nil {
+		return err
+	}
 
 	// Create parent directories
 	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
@@ -144,7 +163,10 @@ func (fs *LocalFileSystem) WriteFile(ctx context.Context, path string, data []by
 }
 
 func (fs *LocalFileSystem) WriteFileStream(ctx context.Context, path string, r io.Reader) error {
-	fullPath := fs.fullPath(path)
+	fullPath, err := fs.fullPath(path)
+	if err != nil {
+		return err
+	}
 
 	// Create parent directories
 	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
@@ -167,7 +189,10 @@ func (fs *LocalFileSystem) WriteFileStream(ctx context.Context, path string, r i
 }
 
 func (fs *LocalFileSystem) CreateDir(ctx context.Context, path string) error {
-	fullPath := fs.fullPath(path)
+	fullPath, err := fs.fullPath(path)
+	if err != nil {
+		return err
+	}
 	if err := os.MkdirAll(fullPath, 0755); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
@@ -179,7 +204,10 @@ func (fs *LocalFileSystem) CreateDir(ctx context.Context, path string) error {
 // ============================================================================
 
 func (fs *LocalFileSystem) DeleteFile(ctx context.Context, path string) error {
-	fullPath := fs.fullPath(path)
+	fullPath, err := fs.fullPath(path)
+	if err != nil {
+		return err
+	}
 	if err := os.Remove(fullPath); err != nil {
 		if os.IsNotExist(err) {
 			return nil // Already deleted
@@ -190,7 +218,10 @@ func (fs *LocalFileSystem) DeleteFile(ctx context.Context, path string) error {
 }
 
 func (fs *LocalFileSystem) DeleteDir(ctx context.Context, path string, recursive bool) error {
-	fullPath := fs.fullPath(path)
+	fullPath, err := fs.fullPath(path)
+	if err != nil {
+		return err
+	}
 
 	if recursive {
 		if err := os.RemoveAll(fullPath); err != nil {
@@ -218,8 +249,14 @@ func (fs *LocalFileSystem) Join(elem ...string) string {
 // ============================================================================
 
 // fullPath converts a relative path to absolute path
-func (fs *LocalFileSystem) fullPath(path string) string {
-	return filepath.Join(fs.basePath, path)
+// Paths that resolve outside the base directory are rejected
+func (fs *LocalFileSystem) fullPath(path string) (string, error) {
+	fullPath := filepath.Join(fs.basePath, path)
+	rel, err := filepath.Rel(fs.basePath, fullPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path escapes base directory: %s", path)
+	}
+	return fullPath, nil
 }
 
 // detectContentType detects MIME type from file extension
@@ -0,0 +1,282 @@
+// Package httpx builds manifest-driven HTTP requests and decodes their responses.
+// It is shared by HTTP tools and HTTP context providers.
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Abraxas-365/ams/pkg/fsx"
+)
+
+// BodyType selects how the request body is encoded
+type BodyType string
+
+const (
+	BodyJSON      BodyType = "json"      // Body marshaled as JSON (default)
+	BodyForm      BodyType = "form"      // Body map encoded as application/x-www-form-urlencoded
+	BodyMultipart BodyType = "multipart" // Body map as fields plus Files as parts
+	BodyText      BodyType = "text"      // Body sent verbatim as text/plain
+	BodyXML       BodyType = "xml"       // Body sent verbatim as application/xml
+)
+
+// File is a multipart file part read from a file system
+type File struct {
+	Field       string // Form field name
+	Path        string // Path in the file system (supports templates)
+	Filename    string // Filename sent to the server (defaults to base of Path)
+	ContentType string // Part content type (defaults to application/octet-stream)
+}
+
+// Spec describes a request before template resolution
+type Spec struct {
+	Method   string
+	URL      string
+	Headers  map[string]string
+	Query    map[string]string
+	BodyType BodyType
+	Body     any
+	Files    []File
+}
+
+// Resolver replaces template placeholders in a manifest value
+type Resolver func(template string) string
+
+// unresolvedPattern matches values that are a single placeholder left untouched
+var unresolvedPattern = regexp.MustCompile(`^\{\{?[\w.]+\}?\}$`)
+
+// IsValidBodyType reports whether the body type is supported
+func IsValidBodyType(bodyType BodyType) bool {
+	switch bodyType {
+	case "", BodyJSON, BodyForm, BodyMultipart, BodyText, BodyXML:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewRequest resolves the spec and builds the HTTP request
+// fs is only required when the spec declares multipart files
+func NewRequest(ctx context.Context, spec Spec, resolve Resolver, fs fsx.FileReader) (*http.Request, error) {
+	if resolve == nil {
+		resolve = func(template string) string { return template }
+	}
+
+	method := spec.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	requestURL, err := buildURL(resolve(spec.URL), spec.Query, resolve)
+	if err != nil {
+		return nil, err
+	}
+
+	body, contentType, err := buildBody(ctx, spec, resolve, fs)
+	if err != nil {
+		return nil, err
+	}
+
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range spec.Headers {
+		req.Header.Set(key, resolve(value))
+	}
+
+	// Multipart boundaries are generated, so the declared header can't be used
+	if contentType != "" && (req.Header.Get("Content-Type") == "" || spec.BodyType == BodyMultipart) {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return req, nil
+}
+
+// buildURL appends encoded query parameters, dropping unresolved placeholders
+func buildURL(rawURL string, query map[string]string, resolve Resolver) (string, error) {
+	if len(query) == 0 {
+		return rawURL, nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+
+	values := u.Query()
+	for key, template := range query {
+		value := resolve(template)
+		if isUnresolved(value) {
+			continue
+		}
+		values.Set(key, value)
+	}
+	u.RawQuery = values.Encode()
+
+	return u.String(), nil
+}
+
+// buildBody encodes the body according to its type and returns its content type
+func buildBody(ctx context.Context, spec Spec, resolve Resolver, fs fsx.FileReader) ([]byte, string, error) {
+	if spec.Body == nil && len(spec.Files) == 0 {
+		return nil, "", nil
+	}
+
+	switch spec.BodyType {
+	case "", BodyJSON:
+		data, err := json.Marshal(spec.Body)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to marshal body: %w", err)
+		}
+		return []byte(resolve(string(data))), "application/json", nil
+
+	case BodyForm:
+		fields, err := resolveFields(spec.Body, resolve)
+		if err != nil {
+			return nil, "", err
+		}
+		values := url.Values{}
+		for key, value := range fields {
+			values.Set(key, value)
+		}
+		return []byte(values.Encode()), "application/x-www-form-urlencoded", nil
+
+	case BodyMultipart:
+		return buildMultipart(ctx, spec, resolve, fs)
+
+	case BodyText:
+		return []byte(resolve(fmt.Sprint(spec.Body))), "text/plain; charset=utf-8", nil
+
+	case BodyXML:
+		return []byte(resolve(fmt.Sprint(spec.Body))), "application/xml; charset=utf-8", nil
+
+	default:
+		return nil, "", fmt.Errorf("unsupported body type: %s", spec.BodyType)
+	}
+}
+
+// buildMultipart writes fields and files as a multipart/form-data body
+func buildMultipart(ctx context.Context, spec Spec, resolve Resolver, fs fsx.FileReader) ([]byte, string, error) {
+	fields, err := resolveFields(spec.Body, resolve)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	// Sorted for a deterministic body
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := writer.WriteField(key, fields[key]); err != nil {
+			return nil, "", fmt.Errorf("failed to write field %s: %w", key, err)
+		}
+	}
+
+	if len(spec.Files) > 0 && fs == nil {
+		return nil, "", fmt.Errorf("multipart files require a file system")
+	}
+
+	for _, file := range spec.Files {
+		// Paths can be templated from model-provided params
+		filePath := resolve(file.Path)
+		if !fsx.IsRelativePath(filePath) {
+			return nil, "", fmt.Errorf("file path %s must be relative to the file root", filePath)
+		}
+		data, err := fs.ReadFile(ctx, filePath)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read file %s: %w", filePath, err)
+		}
+
+		filename := file.Filename
+		if filename == "" {
+			filename = path.Base(filePath)
+		}
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename=%q`, file.Field, filename))
+		header.Set("Content-Type", contentType)
+
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create part %s: %w", file.Field, err)
+		}
+		if _, err := part.Write(data); err != nil {
+			return nil, "", fmt.Errorf("failed to write part %s: %w", file.Field, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to close multipart body: %w", err)
+	}
+
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
+
+// resolveFields flattens a body map into resolved string fields
+// Unresolved placeholders are dropped so optional values are omitted
+func resolveFields(body any, resolve Resolver) (map[string]string, error) {
+	fields := make(map[string]string)
+	if body == nil {
+		return fields, nil
+	}
+
+	m, ok := body.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("form and multipart bodies must be a map, got %T", body)
+	}
+
+	for key, value := range m {
+		var raw string
+		switch v := value.(type) {
+		case string:
+			raw = v
+		case map[string]any, []any:
+			data, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode field %s: %w", key, err)
+			}
+			raw = string(data)
+		default:
+			raw = fmt.Sprint(v)
+		}
+
+		resolved := resolve(raw)
+		if isUnresolved(resolved) {
+			continue
+		}
+		fields[key] = resolved
+	}
+
+	return fields, nil
+}
+
+func isUnresolved(value string) bool {
+	return unresolvedPattern.MatchString(strings.TrimSpace(value))
+}
@@ -0,0 +1,144 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+)
+
+// Decode parses a response body based on its content type
+// JSON, XML and CSV become structured data; anything else is returned as a string
+func Decode(body []byte, contentType string) (any, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch {
+	case isXML(mediaType):
+		return DecodeXML(body)
+	case mediaType == "text/csv" || mediaType == "application/csv":
+		return DecodeCSV(body)
+	}
+
+	// JSON is sniffed regardless of content type, falling back to plain text
+	var result any
+	if err := json.Unmarshal(body, &result); err != nil {
+		return string(body), nil
+	}
+	return result, nil
+}
+
+// DecodeCSV parses CSV with a header row into a list of objects
+func DecodeCSV(body []byte) (any, error) {
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV response: %w", err)
+	}
+	if len(records) == 0 {
+		return []any{}, nil
+	}
+
+	header := records[0]
+	rows := make([]any, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]any, len(header))
+		for i, column := range header {
+			if i < len(record) {
+				row[column] = record[i]
+			} else {
+				row[column] = ""
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// DecodeXML parses XML into nested maps
+// Attributes are prefixed with "@", repeated elements become lists and
+// text next to attributes or children is stored under "#text"
+func DecodeXML(body []byte) (any, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("invalid XML response: no root element")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML response: %w", err)
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			value, err := decodeElement(decoder, start)
+			if err != nil {
+				return nil, fmt.Errorf("invalid XML response: %w", err)
+			}
+			return map[string]any{start.Name.Local: value}, nil
+		}
+	}
+}
+
+// decodeElement reads an element until its end tag
+func decodeElement(decoder *xml.Decoder, start xml.StartElement) (any, error) {
+	node := make(map[string]any)
+	for _, attr := range start.Attr {
+		node["@"+attr.Name.Local] = attr.Value
+	}
+
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			child, err := decodeElement(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+			addChild(node, t.Name.Local, child)
+
+		case xml.CharData:
+			text.Write(t)
+
+		case xml.EndElement:
+			content := strings.TrimSpace(text.String())
+			if len(node) == 0 {
+				return content, nil
+			}
+			if content != "" {
+				node["#text"] = content
+			}
+			return node, nil
+		}
+	}
+}
+
+// addChild stores a child element, turning repeated names into lists
+func addChild(node map[string]any, name string, child any) {
+	existing, ok := node[name]
+	if !ok {
+		node[name] = child
+		return
+	}
+	if list, ok := existing.([]any); ok {
+		node[name] = append(list, child)
+		return
+	}
+	node[name] = []any{existing, child}
+}
+
+func isXML(mediaType string) bool {
+	return mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+}
@@ -0,0 +1,333 @@
+// Package schemax validates decoded JSON values against a JSON Schema subset.
+//
+// Supported keywords: type (string or list), properties, required,
+// additionalProperties (bool or schema), items, enum, const, minimum, maximum,
+// minLength, maxLength, minItems, maxItems, pattern, anyOf and oneOf.
+// Unknown keywords are ignored, which is enough for response contracts.
+package schemax
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Violation is a single validation failure
+type Violation struct {
+	Path    string `json:"path"` // e.g. "$.items[0].price"
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	return v.Path + ": " + v.Message
+}
+
+// ValidationError lists every violation found
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.String()
+	}
+	return "schema validation failed: " + strings.Join(parts, "; ")
+}
+
+// Normalize converts a schema decoded from YAML or built in Go into plain JSON types
+func Normalize(schema any) (map[string]any, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	var normalized map[string]any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, fmt.Errorf("schema must be an object: %w", err)
+	}
+	return normalized, nil
+}
+
+// Check verifies that a schema uses known types and compilable patterns
+func Check(schema map[string]any) error {
+	return checkSchema(schema, "$")
+}
+
+// Validate checks a decoded JSON value against the schema
+// The returned error is a *ValidationError when the value doesn't conform
+func Validate(value any, schema map[string]any) error {
+	var violations []Violation
+	validate(value, schema, "$", &violations)
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// ValidateJSON decodes data and validates it against the schema
+func ValidateJSON(data []byte, schema map[string]any) (any, error) {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, &ValidationError{Violations: []Violation{{Path: "$", Message: "invalid JSON: " + err.Error()}}}
+	}
+	if err := Validate(value, schema); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+var validTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true,
+	"integer": true, "boolean": true, "null": true,
+}
+
+func checkSchema(schema map[string]any, path string) error {
+	for _, t := range schemaTypes(schema) {
+		if !validTypes[t] {
+			return fmt.Errorf("%s: unknown type %q", path, t)
+		}
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("%s: invalid pattern: %w", path, err)
+		}
+	}
+	if properties, ok := schema["properties"].(map[string]any); ok {
+		for name, sub := range properties {
+			subSchema, ok := sub.(map[string]any)
+			if !ok {
+				return fmt.Errorf("%s.%s: schema must be an object", path, name)
+			}
+			if err := checkSchema(subSchema, path+"."+name); err != nil {
+				return err
+			}
+		}
+	}
+	if items, ok := schema["items"].(map[string]any); ok {
+		if err := checkSchema(items, path+"[]"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validate(value any, schema map[string]any, path string, violations *[]Violation) {
+	fail := func(format string, args ...any) {
+		*violations = append(*violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if types := schemaTypes(schema); len(types) > 0 && !matchesAnyType(value, types) {
+		fail("expected %s, got %s", strings.Join(types, " or "), typeOf(value))
+		return
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && !containsValue(enum, value) {
+		fail("must be one of %v", enum)
+	}
+	if constant, ok := schema["const"]; ok && !equalValues(constant, value) {
+		fail("must equal %v", constant)
+	}
+
+	if anyOf, ok := schema["anyOf"].([]any); ok && countMatches(value, anyOf, path) == 0 {
+		fail("must match at least one schema in anyOf")
+	}
+	if oneOf, ok := schema["oneOf"].([]any); ok && countMatches(value, oneOf, path) != 1 {
+		fail("must match exactly one schema in oneOf")
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		validateObject(v, schema, path, violations)
+	case []any:
+		validateArray(v, schema, path, violations)
+	case string:
+		if min, ok := number(schema["minLength"]); ok && float64(len([]rune(v))) < min {
+			fail("must be at least %v characters", min)
+		}
+		if max, ok := number(schema["maxLength"]); ok && float64(len([]rune(v))) > max {
+			fail("must be at most %v characters", max)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				fail("must match pattern %q", pattern)
+			}
+		}
+	case float64:
+		if min, ok := number(schema["minimum"]); ok && v < min {
+			fail("must be >= %v", min)
+		}
+		if max, ok := number(schema["maximum"]); ok && v > max {
+			fail("must be <= %v", max)
+		}
+	}
+}
+
+func validateObject(object map[string]any, schema map[string]any, path string, violations *[]Violation) {
+	properties, _ := schema["properties"].(map[string]any)
+
+	if required, ok := schema["required"].([]any); ok {
+		for _, name := range required {
+			key, _ := name.(string)
+			if _, exists := object[key]; !exists {
+				*violations = append(*violations, Violation{Path: path + "." + key, Message: "is required"})
+			}
+		}
+	}
+
+	// Deterministic order for stable error messages
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		childPath := path + "." + key
+		if sub, ok := properties[key].(map[string]any); ok {
+			validate(object[key], sub, childPath, violations)
+			continue
+		}
+
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				*violations = append(*violations, Violation{Path: childPath, Message: "is not allowed"})
+			}
+		case map[string]any:
+			validate(object[key], additional, childPath, violations)
+		}
+	}
+}
+
+func validateArray(array []any, schema map[string]any, path string, violations *[]Violation) {
+	if min, ok := number(schema["minItems"]); ok && float64(len(array)) < min {
+		*violations = append(*violations, Violation{Path: path, Message: fmt.Sprintf("must have at least %v items", min)})
+	}
+	if max, ok := number(schema["maxItems"]); ok && float64(len(array)) > max {
+		*violations = append(*violations, Violation{Path: path, Message: fmt.Sprintf("must have at most %v items", max)})
+	}
+
+	if items, ok := schema["items"].(map[string]any); ok {
+		for i, item := range array {
+			validate(item, items, fmt.Sprintf("%s[%d]", path, i), violations)
+		}
+	}
+}
+
+func countMatches(value any, schemas []any, path string) int {
+	matches := 0
+	for _, candidate := range schemas {
+		sub, ok := candidate.(map[string]any)
+		if !ok {
+			continue
+		}
+		var violations []Violation
+		validate(value, sub, path, &violations)
+		if len(violations) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+func schemaTypes(schema map[string]any) []string {
+	switch t := schema["type"].(type) {
+	case string:
+		return []string{t}
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func matchesAnyType(value any, types []string) bool {
+	for _, t := range types {
+		if matchesType(value, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesType(value any, t string) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == float64(int64(f))
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return false
+}
+
+func typeOf(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func number(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func containsValue(list []any, value any) bool {
+	for _, item := range list {
+		if equalValues(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalValues(a, b any) bool {
+	left, errA := json.Marshal(a)
+	right, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(left) == string(right)
+}
@@ -0,0 +1,200 @@
+// Package shapex reduces backend responses before they reach the model.
+//
+// A response goes through three optional stages, in order:
+//  1. Path: a JMESPath expression (a leading JSONPath "$." is accepted),
+//     e.g. "data.items[0].status" or "orders[*].id"
+//  2. Template: a text/template rendering the result as compact text
+//  3. MaxBytes: truncation of the serialized result with a clear marker
+package shapex
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/jmespath/go-jmespath"
+)
+
+// TruncationMarker is appended to responses cut by MaxBytes
+const TruncationMarker = "\n...[truncated: showing %d of %d bytes]"
+
+// Options configures response shaping
+type Options struct {
+	Path     string // JMESPath expression selecting part of the response
+	Template string // text/template applied to the selected data
+	MaxBytes int    // Maximum serialized size (0 = unlimited)
+}
+
+// IsZero reports whether no shaping is configured
+func (o Options) IsZero() bool {
+	return o.Path == "" && o.Template == "" && o.MaxBytes <= 0
+}
+
+// Validate checks that the path and template compile
+func Validate(opts Options) error {
+	if opts.Path != "" {
+		if _, err := jmespath.Compile(normalizePath(opts.Path)); err != nil && !isDottedPath(opts.Path) {
+			return fmt.Errorf("invalid response path %q: %w", opts.Path, err)
+		}
+	}
+	if opts.Template != "" {
+		if _, err := parseTemplate(opts.Template); err != nil {
+			return fmt.Errorf("invalid response template: %w", err)
+		}
+	}
+	if opts.MaxBytes < 0 {
+		return fmt.Errorf("max response bytes must be positive, got %d", opts.MaxBytes)
+	}
+	return nil
+}
+
+// Apply shapes data according to the options
+// The result is the original data type unless a template or truncation produced text
+func Apply(data any, opts Options) (any, error) {
+	result := data
+
+	if opts.Path != "" {
+		selected, err := Select(result, opts.Path)
+		if err != nil {
+			return nil, err
+		}
+		result = selected
+	}
+
+	if opts.Template != "" {
+		rendered, err := Render(result, opts.Template)
+		if err != nil {
+			return nil, err
+		}
+		result = rendered
+	}
+
+	if opts.MaxBytes > 0 {
+		result = Truncate(result, opts.MaxBytes)
+	}
+
+	return result, nil
+}
+
+// Select evaluates a JMESPath expression against data
+// Plain dotted paths whose keys are not valid JMESPath identifiers
+// (e.g. "data.order-items") fall back to a key-by-key walk
+func Select(data any, path string) (any, error) {
+	expression := normalizePath(path)
+	if expression == "" {
+		return data, nil
+	}
+
+	compiled, err := jmespath.Compile(expression)
+	if err != nil {
+		if isDottedPath(path) {
+			return walkDottedPath(data, expression), nil
+		}
+		return nil, fmt.Errorf("invalid response path %q: %w", path, err)
+	}
+
+	result, err := compiled.Search(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate response path %q: %w", path, err)
+	}
+	return result, nil
+}
+
+// Render executes a text/template with data as the root value
+func Render(data any, text string) (string, error) {
+	tmpl, err := parseTemplate(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid response template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render response template: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// Truncate serializes data and cuts it to maxBytes with a truncation marker
+// Data that already fits is returned unchanged
+func Truncate(data any, maxBytes int) any {
+	text, ok := data.(string)
+	if !ok {
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return data
+		}
+		text = string(encoded)
+	}
+
+	if len(text) <= maxBytes {
+		return data
+	}
+
+	// Never cut a multi-byte character in half
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+
+	return text[:cut] + fmt.Sprintf(TruncationMarker, cut, len(text))
+}
+
+// templateFuncs are available inside response templates
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"join": func(items any, sep string) string {
+		list, ok := items.([]any)
+		if !ok {
+			return fmt.Sprint(items)
+		}
+		parts := make([]string, len(list))
+		for i, item := range list {
+			parts[i] = fmt.Sprint(item)
+		}
+		return strings.Join(parts, sep)
+	},
+	"default": func(fallback, value any) any {
+		if value == nil || value == "" {
+			return fallback
+		}
+		return value
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+func parseTemplate(text string) (*template.Template, error) {
+	return template.New("response").Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+}
+
+// normalizePath strips JSONPath root markers so "$.data.items" works as JMESPath
+func normalizePath(path string) string {
+	path = strings.TrimSpace(path)
+	path = strings.TrimPrefix(path, "$")
+	return strings.TrimPrefix(path, ".")
+}
+
+// isDottedPath reports whether path only uses the legacy "a.b.c" syntax
+func isDottedPath(path string) bool {
+	return !strings.ContainsAny(normalizePath(path), "[]*|@&!(){}`'\"=<>?:")
+}
+
+func walkDottedPath(data any, path string) any {
+	current := data
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+		if current, ok = m[part]; !ok {
+			return nil
+		}
+	}
+	return current
+}
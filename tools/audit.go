@@ -0,0 +1,157 @@
+// tools/audit.go
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/Abraxas-365/ams/manifest"
+	"github.com/Abraxas-365/ams/pkg/ai/llm/memoryx"
+	"github.com/Abraxas-365/ams/pkg/logx"
+)
+
+// CallRecorder persists an audit record for every tool execution
+type CallRecorder interface {
+	RecordToolCall(ctx context.Context, record *memoryx.ToolCallRecord) error
+}
+
+const redactedValue = "[REDACTED]"
+
+// sensitiveKeys are argument name suffixes that are always redacted, compared
+// without case or separators so "access_token" and "accessToken" match but
+// "max_tokens" and "token_count" don't
+var sensitiveKeys = []string{
+	"token",
+	"password",
+	"passwd",
+	"secret",
+	"authorization",
+	"apikey",
+	"secretkey",
+	"privatekey",
+	"accesskey",
+	"credential",
+	"credentials",
+	"cardnumber",
+	"cvv",
+}
+
+// newCallRecord creates an audit record populated from the workflow context
+func (t *HTTPTool) newCallRecord() *memoryx.ToolCallRecord {
+	record := &memoryx.ToolCallRecord{
+		ToolName:  t.definition.Name,
+		CreatedAt: time.Now(),
+	}
+
+	if sessionID, ok := t.workflowContext["session_id"].(string); ok {
+		record.SessionID = memoryx.SessionID(sessionID)
+	}
+	if user, ok := t.workflowContext["user"].(map[string]any); ok {
+		record.UserID, _ = user["id"].(string)
+		record.TenantID, _ = user["tenant_id"].(string)
+	}
+	if route, ok := t.workflowContext["route"].(map[string]any); ok {
+		record.Route, _ = route["name"].(string)
+	}
+
+	return record
+}
+
+// recordCall finalizes and persists an audit record
+// Failures are logged but never fail the tool call itself
+func (t *HTTPTool) recordCall(ctx context.Context, record *memoryx.ToolCallRecord, callErr error) {
+	if t.recorder == nil {
+		return
+	}
+
+	record.LatencyMs = time.Since(record.CreatedAt).Milliseconds()
+	if callErr != nil {
+		record.Error = callErr.Error()
+	}
+
+	// Use a detached context so cancelled requests are still audited
+	recordCtx := context.WithoutCancel(ctx)
+	if err := t.recorder.RecordToolCall(recordCtx, record); err != nil {
+		logx.WithFields(logx.Fields{
+			"tool": t.definition.Name,
+		}).WithError(err).Warn("Failed to record tool call")
+	}
+}
+
+// redactArguments serializes resolved parameters with sensitive values masked
+func (t *HTTPTool) redactArguments(params map[string]any) string {
+	return redactParams(t.definition, params)
+}
+
+// RedactArguments masks sensitive values in the JSON arguments sent for a tool,
+// the same way audit records are redacted; malformed arguments are masked whole
+func RedactArguments(definition manifest.Tool, arguments string) string {
+	if arguments == "" {
+		return ""
+	}
+	var params map[string]any
+	if err := json.Unmarshal([]byte(arguments), &params); err != nil {
+		return redactedValue
+	}
+	return redactParams(definition, params)
+}
+
+func redactParams(definition manifest.Tool, params map[string]any) string {
+	sensitive := make(map[string]bool)
+	for _, param := range definition.Parameters {
+		if param.Sensitive {
+			sensitive[param.Name] = true
+		}
+	}
+
+	redacted := make(map[string]any, len(params))
+	for key, value := range params {
+		if sensitive[key] || isSensitiveKey(key) {
+			redacted[key] = redactedValue
+			continue
+		}
+		redacted[key] = redactValue(value)
+	}
+
+	data, err := json.Marshal(redacted)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// redactValue masks sensitive keys inside nested objects
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, nested := range v {
+			if isSensitiveKey(key) {
+				result[key] = redactedValue
+				continue
+			}
+			result[key] = redactValue(nested)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, nested := range v {
+			result[i] = redactValue(nested)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	normalized := strings.NewReplacer("_", "", "-", "", ".", "").Replace(strings.ToLower(key))
+	for _, suffix := range sensitiveKeys {
+		if strings.HasSuffix(normalized, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+// tools/dry_run.go
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Abraxas-365/ams/pkg/logx"
+)
+
+// sensitiveHeaders are header names masked in simulated requests in addition to sensitiveKeys
+var sensitiveHeaders = []string{
+	"cookie",
+	"set-cookie",
+}
+
+// simulate builds the dry-run result for a fully-resolved request
+func (t *HTTPTool) simulate(req *http.Request, params map[string]any) (any, error) {
+	simulated := map[string]any{
+		"method":  req.Method,
+		"url":     t.maskURL(req),
+		"headers": t.maskHeaders(req.Header),
+	}
+
+	if body := t.readSimulatedBody(req); body != nil {
+		simulated["body"] = body
+	}
+
+	result := map[string]any{
+		"dry_run": true,
+		"request": simulated,
+	}
+
+	if t.definition.MockResponse != nil {
+		result["response"] = t.resolveMockResponse(params)
+	} else {
+		result["message"] = "Dry run: the request was not sent. Tell the user what would have happened."
+	}
+
+	logx.WithFields(logx.Fields{
+		"tool":     t.definition.Name,
+		"method":   req.Method,
+		"has_mock": t.definition.MockResponse != nil,
+	}).Info("HTTP tool simulated (dry run)")
+
+	return result, nil
+}
+
+// resolveMockResponse fills parameter placeholders in string mock responses
+func (t *HTTPTool) resolveMockResponse(params map[string]any) any {
+	mock := t.definition.MockResponse
+
+	data, err := json.Marshal(mock)
+	if err != nil {
+		return mock
+	}
+
+	var resolved any
+	if err := json.Unmarshal([]byte(t.resolveTemplate(string(data), params)), &resolved); err != nil {
+		// Placeholder values broke the JSON, fall back to the raw mock
+		return mock
+	}
+	return resolved
+}
+
+// maskURL masks sensitive query parameters and the user token
+func (t *HTTPTool) maskURL(req *http.Request) string {
+	u := *req.URL
+	query := u.Query()
+	for key := range query {
+		if isSensitiveKey(key) {
+			query.Set(key, redactedValue)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return t.maskToken(u.String())
+}
+
+// maskHeaders returns a copy of the headers with secrets masked
+func (t *HTTPTool) maskHeaders(headers http.Header) map[string]string {
+	masked := make(map[string]string, len(headers))
+	for key := range headers {
+		value := headers.Get(key)
+		if isSensitiveHeader(key) {
+			value = redactedValue
+		}
+		masked[key] = t.maskToken(value)
+	}
+	return masked
+}
+
+// readSimulatedBody reads the request body with sensitive fields masked
+func (t *HTTPTool) readSimulatedBody(req *http.Request) any {
+	if req.GetBody == nil {
+		return nil
+	}
+
+	reader, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+
+	// File contents are not useful to the model, only describe them
+	if strings.HasPrefix(req.Header.Get("Content-Type"), "multipart/") {
+		return fmt.Sprintf("[multipart body, %d bytes]", len(data))
+	}
+
+	var body any
+	if err := json.Unmarshal(data, &body); err != nil {
+		return t.maskToken(string(data))
+	}
+	return redactValue(body)
+}
+
+// maskToken replaces any occurrence of the user token
+func (t *HTTPTool) maskToken(value string) string {
+	if t.userToken == "" {
+		return value
+	}
+	return strings.ReplaceAll(value, t.userToken, redactedValue)
+}
+
+func isSensitiveHeader(key string) bool {
+	lower := strings.ToLower(key)
+	// "X-Api-Key" should match the same fragments as "api_key"
+	if isSensitiveKey(strings.ReplaceAll(lower, "-", "_")) {
+		return true
+	}
+	for _, header := range sensitiveHeaders {
+		if lower == header {
+			return true
+		}
+	}
+	return false
+}
@@ -1,7 +1,6 @@
 package tools
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -14,7 +13,10 @@ import (
 
 	"github.com/Abraxas-365/ams/manifest"
 	"github.com/Abraxas-365/ams/pkg/ai/llm"
+	"github.com/Abraxas-365/ams/pkg/fsx"
+	"github.com/Abraxas-365/ams/pkg/httpx"
 	"github.com/Abraxas-365/ams/pkg/logx"
+	"github.com/Abraxas-365/ams/pkg/shapex"
 )
 
 // HTTPTool implements toolx.Toolx for HTTP-based tools
@@ -23,10 +25,37 @@ type HTTPTool struct {
 	workflowContext map[string]any
 	userToken       string
 	client          *http.Client
+	recorder        CallRecorder
+	dryRun          bool
+	fs              fsx.FileReader
+}
+
+// HTTPToolOption configures an HTTP tool
+type HTTPToolOption func(*HTTPTool)
+
+// WithRecorder records every execution of the tool in the audit trail
+func WithRecorder(recorder CallRecorder) HTTPToolOption {
+	return func(t *HTTPTool) {
+		t.recorder = recorder
+	}
+}
+
+// WithDryRun makes the tool return the resolved request instead of sending it
+func WithDryRun() HTTPToolOption {
+	return func(t *HTTPTool) {
+		t.dryRun = true
+	}
+}
+
+// WithFileSystem sets the file source for multipart file parts
+func WithFileSystem(fs fsx.FileReader) HTTPToolOption {
+	return func(t *HTTPTool) {
+		t.fs = fs
+	}
 }
 
 // NewHTTPTool creates a new HTTP tool
-func NewHTTPTool(definition manifest.Tool, workflowContext map[string]any, userToken string) *HTTPTool {
+func NewHTTPTool(definition manifest.Tool, workflowContext map[string]any, userToken string, opts ...HTTPToolOption) *HTTPTool {
 	timeout := 30 * time.Second
 	if definition.Config.Timeout != "" {
 		if d, err := time.ParseDuration(definition.Config.Timeout); err == nil {
@@ -42,12 +71,18 @@ func NewHTTPTool(definition manifest.Tool, workflowContext map[string]any, userT
 		"has_auth": userToken != "",
 	}).Debug("HTTP tool created")
 
-	return &HTTPTool{
+	tool := &HTTPTool{
 		definition:      definition,
 		workflowContext: workflowContext,
 		userToken:       userToken,
 		client:          &http.Client{Timeout: timeout},
 	}
+
+	for _, opt := range opts {
+		opt(tool)
+	}
+
+	return tool
 }
 
 // Name returns the tool name (sanitized for LLM)
@@ -75,12 +110,20 @@ func (t *HTTPTool) GetTool() llm.Tool {
 }
 
 // Call executes the HTTP tool
-func (t *HTTPTool) Call(ctx context.Context, inputs string) (any, error) {
+func (t *HTTPTool) Call(ctx context.Context, inputs string) (result any, err error) {
 	logx.WithFields(logx.Fields{
 		"tool":   t.definition.Name,
 		"inputs": inputs,
 	}).Info("Executing HTTP tool")
 
+	record := t.newCallRecord()
+	defer func() {
+		// Simulated calls never reached the backend, so they are not audited
+		if !t.dryRun {
+			t.recordCall(ctx, record, err)
+		}
+	}()
+
 	// 1. Parse agent parameters
 	var agentParams map[string]any
 	if inputs != "" {
@@ -112,6 +155,7 @@ func (t *HTTPTool) Call(ctx context.Context, inputs string) (any, error) {
 		"tool":        t.definition.Name,
 		"param_count": len(completeParams),
 	}).Debug("Parameters resolved")
+	record.Arguments = t.redactArguments(completeParams)
 
 	// 3. Build HTTP request
 	req, err := t.buildRequest(ctx, completeParams)
@@ -127,6 +171,11 @@ func (t *HTTPTool) Call(ctx context.Context, inputs string) (any, error) {
 		"url":    req.URL.String(),
 	}).Debug("HTTP request built")
 
+	// Dry run: describe the request instead of sending it
+	if t.dryRun {
+		return t.simulate(req, completeParams)
+	}
+
 	// 4. Execute request
 	startTime := time.Now()
 	resp, err := t.client.Do(req)
@@ -147,6 +196,7 @@ func (t *HTTPTool) Call(ctx context.Context, inputs string) (any, error) {
 		return nil, NewToolExecutionError(t.definition.Name, fmt.Errorf("HTTP request failed: %w", err))
 	}
 	defer resp.Body.Close()
+	record.HTTPStatus = resp.StatusCode
 
 	logx.WithFields(logx.Fields{
 		"tool":        t.definition.Name,
@@ -155,7 +205,7 @@ func (t *HTTPTool) Call(ctx context.Context, inputs string) (any, error) {
 	}).Debug("HTTP response received")
 
 	// 5. Handle response
-	result, err := t.handleResponse(resp)
+	result, record.ResultSize, err = t.handleResponse(resp)
 	if err != nil {
 		logx.WithFields(logx.Fields{
 			"tool":        t.definition.Name,
@@ -335,8 +385,10 @@ func (t *HTTPTool) resolveParameters(agentParams map[string]any) (map[string]any
 
 // buildRequest creates the HTTP request with auth
 func (t *HTTPTool) buildRequest(ctx context.Context, params map[string]any) (*http.Request, error) {
+	config := t.definition.Config
+
 	// 1. Resolve URL with parameters
-	originalURL := t.definition.Config.URL
+	originalURL := config.URL
 	resolvedURL := t.resolveTemplate(originalURL, params)
 
 	// ✅ DEBUG LOGGING - Shows template resolution details
@@ -361,64 +413,35 @@ func (t *HTTPTool) buildRequest(ctx context.Context, params map[string]any) (*ht
 		}
 	}
 
-	logx.WithFields(logx.Fields{
-		"tool": t.definition.Name,
-		"url":  resolvedURL,
-	}).Debug("URL resolved")
-
-	method := t.definition.Config.Method
+	method := config.Method
 	if method == "" {
 		method = "GET"
 	}
 
-	var bodyReader io.Reader
-
-	// 2. Handle request body
-	if t.definition.Config.Body != nil {
-		bodyJSON, err := json.Marshal(t.definition.Config.Body)
-		if err != nil {
-			logx.WithField("tool", t.definition.Name).WithError(err).Error("Failed to marshal request body")
-			return nil, fmt.Errorf("failed to marshal body: %w", err)
-		}
-		bodyStr := t.resolveTemplate(string(bodyJSON), params)
-		bodyReader = bytes.NewReader([]byte(bodyStr))
-		logx.WithFields(logx.Fields{
-			"tool":        t.definition.Name,
-			"body_length": len(bodyStr),
-		}).Debug("Request body prepared")
+	// 2. Build request (query encoding, body by type, headers with auth resolution)
+	spec := config.RequestSpec(method, resolvedURL, config.Headers, config.Body)
+	resolve := func(template string) string {
+		return t.resolveTemplate(template, params)
 	}
 
-	// 3. Create request
-	req, err := http.NewRequestWithContext(ctx, method, resolvedURL, bodyReader)
+	req, err := httpx.NewRequest(ctx, spec, resolve, t.fs)
 	if err != nil {
 		logx.WithFields(logx.Fields{
-			"tool":   t.definition.Name,
-			"method": method,
-			"url":    resolvedURL,
+			"tool":      t.definition.Name,
+			"method":    method,
+			"url":       resolvedURL,
+			"body_type": config.BodyType,
 		}).WithError(err).Error("Failed to create HTTP request")
 		return nil, err
 	}
 
-	// 4. Add headers with authentication resolution
-	headerCount := 0
-	for key, value := range t.definition.Config.Headers {
-		resolvedValue := t.resolveTemplate(value, params)
-		req.Header.Set(key, resolvedValue)
-		headerCount++
-	}
-
-	// 5. Set Content-Type if body present
-	if t.definition.Config.Body != nil && req.Header.Get("Content-Type") == "" {
-		req.Header.Set("Content-Type", "application/json")
-		headerCount++
-	}
-
-	if headerCount > 0 {
-		logx.WithFields(logx.Fields{
-			"tool":         t.definition.Name,
-			"header_count": headerCount,
-		}).Debug("Request headers set")
-	}
+	logx.WithFields(logx.Fields{
+		"tool":         t.definition.Name,
+		"url":          req.URL.String(),
+		"body_type":    config.BodyType,
+		"query_count":  len(config.Query),
+		"header_count": len(req.Header),
+	}).Debug("Request prepared")
 
 	return req, nil
 }
@@ -486,12 +509,12 @@ func (t *HTTPTool) resolveTemplate(template string, params map[string]any) strin
 	return result
 }
 
-// handleResponse processes the HTTP response
-func (t *HTTPTool) handleResponse(resp *http.Response) (any, error) {
+// handleResponse processes the HTTP response and returns the result with the body size
+func (t *HTTPTool) handleResponse(resp *http.Response) (any, int, error) {
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		logx.WithField("tool", t.definition.Name).WithError(err).Error("Failed to read response body")
-		return nil, NewToolExecutionError(t.definition.Name, fmt.Errorf("failed to read response: %w", err))
+		return nil, len(body), NewToolExecutionError(t.definition.Name, fmt.Errorf("failed to read response: %w", err))
 	}
 
 	logx.WithFields(logx.Fields{
@@ -506,32 +529,56 @@ func (t *HTTPTool) handleResponse(resp *http.Response) (any, error) {
 			"status_code": resp.StatusCode,
 			"body":        string(body),
 		}).Error("HTTP request returned error status")
-		return nil, NewToolExecutionError(
+		return nil, len(body), NewToolExecutionError(
 			t.definition.Name,
 			fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body)),
 		)
 	}
 
-	// Try to parse as JSON
-	var result any
-	if err := json.Unmarshal(body, &result); err != nil {
-		// Not JSON, return as string
-		logx.WithField("tool", t.definition.Name).Debug("Response is not JSON, returning as string")
-		return string(body), nil
+	shaping := t.definition.Config.ShapeOptions()
+	contentType := resp.Header.Get("Content-Type")
+
+	// Parse JSON, XML or CSV into structured data
+	result, err := httpx.Decode(body, contentType)
+	if err != nil {
+		logx.WithFields(logx.Fields{
+			"tool":         t.definition.Name,
+			"content_type": contentType,
+		}).WithError(err).Error("Failed to decode response")
+		return nil, len(body), NewToolExecutionError(t.definition.Name, err)
+	}
+
+	if text, ok := result.(string); ok {
+		// Unstructured response, return as string (only truncation applies)
+		logx.WithField("tool", t.definition.Name).Debug("Response is not structured, returning as string")
+		if shaping.MaxBytes > 0 {
+			return shapex.Truncate(text, shaping.MaxBytes), len(body), nil
+		}
+		return text, len(body), nil
 	}
 
-	logx.WithField("tool", t.definition.Name).Debug("Response parsed as JSON")
+	logx.WithFields(logx.Fields{
+		"tool":         t.definition.Name,
+		"content_type": contentType,
+	}).Debug("Response parsed")
 
-	// Extract specific path if configured
-	if t.definition.Config.ResponsePath != "" {
-		result = extractJSONPath(result, t.definition.Config.ResponsePath)
+	// Shape the response (path selection, template, truncation)
+	if !shaping.IsZero() {
+		shaped, err := shapex.Apply(result, shaping)
+		if err != nil {
+			logx.WithField("tool", t.definition.Name).WithError(err).Error("Failed to shape response")
+			return nil, len(body), NewToolExecutionError(t.definition.Name, err)
+		}
+		result = shaped
 		logx.WithFields(logx.Fields{
 			"tool":          t.definition.Name,
-			"response_path": t.definition.Config.ResponsePath,
-		}).Debug("Extracted response path")
+			"response_path": shaping.Path,
+			"has_template":  shaping.Template != "",
+			"max_bytes":     shaping.MaxBytes,
+		}).Debug("Response shaped")
 	}
 
-	return result, nil
+	return result, len(body), nil
 }
 
 // Helper functions
@@ -591,35 +638,3 @@ func extractFromContext(contextPath string, workflowContext map[string]any) (any
 
 	return current, nil
 }
-
-func extractJSONPath(data any, path string) any {
-	if path == "" {
-		return data
-	}
-
-	parts := strings.Split(path, ".")
-	current := data
-
-	for _, part := range parts {
-		if m, ok := current.(map[string]any); ok {
-			if val, exists := m[part]; exists {
-				current = val
-			} else {
-				logx.WithFields(logx.Fields{
-					"json_path": path,
-					"segment":   part,
-				}).Debug("JSON path segment not found")
-				return nil
-			}
-		} else {
-			logx.WithFields(logx.Fields{
-				"json_path": path,
-				"segment":   part,
-				"type":      fmt.Sprintf("%T", current),
-			}).Debug("Cannot traverse non-map in JSON")
-			return nil
-		}
-	}
-
-	return current
-}
@@ -6,26 +6,65 @@ import (
 
 	"github.com/Abraxas-365/ams/manifest"
 	"github.com/Abraxas-365/ams/pkg/ai/llm/toolx"
+	"github.com/Abraxas-365/ams/pkg/fsx"
+	"github.com/Abraxas-365/ams/pkg/logx"
+	"github.com/Abraxas-365/ams/pkg/shapex"
 )
 
 // ToolLoader creates LLM tools from manifest configuration
-type ToolLoader struct{}
+type ToolLoader struct {
+	recorder CallRecorder
+	fs       fsx.FileReader
+}
+
+// ToolLoaderOption configures the tool loader
+type ToolLoaderOption func(*ToolLoader)
+
+// WithCallRecorder attaches an audit recorder to every loaded tool
+func WithCallRecorder(recorder CallRecorder) ToolLoaderOption {
+	return func(l *ToolLoader) {
+		l.recorder = recorder
+	}
+}
+
+// WithToolFileSystem sets the file source for multipart tool uploads
+func WithToolFileSystem(fs fsx.FileReader) ToolLoaderOption {
+	return func(l *ToolLoader) {
+		l.fs = fs
+	}
+}
 
 // NewToolLoader creates a new tool loader
-func NewToolLoader() *ToolLoader {
-	return &ToolLoader{}
+func NewToolLoader(opts ...ToolLoaderOption) *ToolLoader {
+	loader := &ToolLoader{}
+	for _, opt := range opts {
+		opt(loader)
+	}
+	return loader
 }
 
 // LoadFromRoute creates toolx.Toolx instances from route configuration
+// Tools whose required scopes are not covered by grantedScopes are dropped
+// Per-request options (e.g. WithDryRun) are applied to every loaded tool
 func (l *ToolLoader) LoadFromRoute(
 	route *manifest.Route,
 	workflowContext map[string]any,
 	userToken string,
+	grantedScopes []string,
+	opts ...HTTPToolOption,
 ) ([]toolx.Toolx, error) {
 	tools := make([]toolx.Toolx, 0, len(route.Tools))
 
 	for _, toolDef := range route.Tools {
-		tool, err := l.createTool(toolDef, workflowContext, userToken)
+		if !toolDef.IsAllowed(grantedScopes) {
+			logx.WithFields(logx.Fields{
+				"tool":            toolDef.Name,
+				"required_scopes": toolDef.RequiredScopes,
+			}).Debug("Tool skipped: missing required scopes")
+			continue
+		}
+
+		tool, err := l.createTool(toolDef, workflowContext, userToken, opts)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create tool %s: %w", toolDef.Name, err)
 		}
@@ -40,6 +79,7 @@ func (l *ToolLoader) createTool(
 	toolDef manifest.Tool,
 	workflowContext map[string]any,
 	userToken string,
+	toolOpts []HTTPToolOption,
 ) (toolx.Toolx, error) {
 	// Validate tool definition
 	if err := l.validateTool(toolDef); err != nil {
@@ -48,7 +88,15 @@ func (l *ToolLoader) createTool(
 
 	switch toolDef.Type {
 	case "http":
-		return NewHTTPTool(toolDef, workflowContext, userToken), nil
+		opts := make([]HTTPToolOption, 0, len(toolOpts)+2)
+		if l.recorder != nil {
+			opts = append(opts, WithRecorder(l.recorder))
+		}
+		if l.fs != nil {
+			opts = append(opts, WithFileSystem(l.fs))
+		}
+		opts = append(opts, toolOpts...)
+		return NewHTTPTool(toolDef, workflowContext, userToken, opts...), nil
 	default:
 		return nil, NewUnsupportedToolTypeError(toolDef.Type)
 	}
@@ -79,6 +127,16 @@ func (l *ToolLoader) validateTool(tool manifest.Tool) error {
 		}
 	}
 
+	// Validate request encoding
+	if err := tool.Config.RequestEncoding.Validate(); err != nil {
+		return NewInvalidToolError(err.Error())
+	}
+
+	// Validate response shaping
+	if err := shapex.Validate(tool.Config.ShapeOptions()); err != nil {
+		return NewInvalidToolError(err.Error())
+	}
+
 	// Validate parameters
 	paramNames := make(map[string]bool)
 	for _, param := range tool.Parameters {